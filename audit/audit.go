@@ -0,0 +1,247 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The previous hash of the first record in a log
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// The version of the tool recorded in the log, set at build time
+var Version = "dev"
+
+// A seal operation, each record includes the hash of the record before it
+type Record struct {
+	Seq          int       `json:"seq"`
+	Time         time.Time `json:"time"`
+	User         string    `json:"user"`
+	Host         string    `json:"host"`
+	Source       string    `json:"source,omitempty"`
+	InputSha256  string    `json:"inputSha256"`
+	OutputSha256 string    `json:"outputSha256"`
+	Kvv          string    `json:"kvv"`
+	Mac          string    `json:"mac"`
+	SealDate     string    `json:"sealDate"`
+	Version      string    `json:"version"`
+	PrevHash     string    `json:"prevHash"`
+	Hash         string    `json:"hash"`
+}
+
+// Calculate the hash of the record, the SHA-256 of its JSON encoding without the hash
+func (r Record) CalculateHash() string {
+	r.Hash = ""
+	content, _ := json.Marshal(r)
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// An append-only audit log with one JSON record per line
+type Log struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func Open(path string) *Log {
+	return &Log{Path: path}
+}
+
+// Append a record to the log, filling in the sequence number, time, user, host, version and hashes
+// The log file is locked while the last record is read and the new one written
+func (l *Log) Append(record Record) (Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.Path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return Record{}, fmt.Errorf("could not open audit log: %w", err)
+	}
+	defer file.Close()
+
+	// Other processes sealing with the same log must not read the same last record
+	if err := lockFile(file); err != nil {
+		return Record{}, fmt.Errorf("could not lock audit log: %w", err)
+	}
+	defer unlockFile(file)
+
+	last, err := lastRecord(file)
+	if err != nil {
+		return Record{}, fmt.Errorf("could not read audit log: %w", err)
+	}
+
+	record.Seq = 1
+	record.PrevHash = GenesisHash
+	if last != nil {
+		record.Seq = last.Seq + 1
+		record.PrevHash = last.Hash
+	}
+
+	record.Time = time.Now().UTC()
+	record.User = CurrentUser()
+	record.Host, _ = os.Hostname()
+	record.Version = Version
+	record.Hash = record.CalculateHash()
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return Record{}, err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return Record{}, fmt.Errorf("could not write audit log: %w", err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return Record{}, fmt.Errorf("could not write audit log: %w", err)
+	}
+
+	return record, nil
+}
+
+// Get the name of the OS user running the process
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+
+	for _, env := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(env); name != "" {
+			return name
+		}
+	}
+
+	return "unknown"
+}
+
+// Read the last record of the log, nil if the log is empty
+// Only the end of the file is read, growing the window until a full line is found
+func lastRecord(file *os.File) (*Record, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	for window := int64(4096); ; window *= 2 {
+		offset := max(info.Size()-window, 0)
+
+		content := make([]byte, info.Size()-offset)
+		if _, err := file.ReadAt(content, offset); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		content = bytes.TrimRight(content, "\n")
+		start := bytes.LastIndexByte(content, '\n')
+		if start < 0 && offset > 0 {
+			continue
+		}
+
+		if len(content) == 0 {
+			return nil, nil
+		}
+
+		record := &Record{}
+		if err := json.Unmarshal(content[start+1:], record); err != nil {
+			return nil, fmt.Errorf("last record is invalid: %w", err)
+		}
+
+		return record, nil
+	}
+}
+
+// A break in the chain found when verifying the log
+type Problem struct {
+	Line    int
+	Seq     int
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// The result of verifying a log
+// Head is the hash of the last record, record it elsewhere to detect records removed from the end
+type Verification struct {
+	Records  int
+	Head     string
+	Problems []Problem
+}
+
+func (v *Verification) Valid() bool {
+	return len(v.Problems) == 0
+}
+
+// Verify the hash chain of the log file, reporting modified, removed, reordered and inserted records
+func Verify(path string) (*Verification, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("audit log %s does not exist", path)
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	return VerifyReader(file)
+}
+
+// Verify the hash chain of a log read from r
+func VerifyReader(r io.Reader) (*Verification, error) {
+	verification := &Verification{Head: GenesisHash}
+	problem := func(line int, seq int, format string, args ...interface{}) {
+		verification.Problems = append(verification.Problems, Problem{Line: line, Seq: seq, Message: fmt.Sprintf(format, args...)})
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+
+	expectedSeq := 1
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			problem(line, 0, "empty line")
+			continue
+		}
+
+		record := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			problem(line, 0, "invalid record: %v", err)
+			continue
+		}
+		verification.Records++
+
+		if record.Seq != expectedSeq {
+			problem(line, record.Seq, "expected record %d, found %d, records are missing or out of order", expectedSeq, record.Seq)
+		}
+
+		if record.PrevHash != verification.Head {
+			problem(line, record.Seq, "previous hash does not match the record before it")
+		}
+
+		if hash := record.CalculateHash(); hash != record.Hash {
+			problem(line, record.Seq, "record has been modified, hash %s does not match %s", hash, record.Hash)
+		}
+
+		expectedSeq = record.Seq + 1
+		verification.Head = record.Hash
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return verification, nil
+}
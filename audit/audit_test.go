@@ -0,0 +1,122 @@
+package audit_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/audit"
+)
+
+func writeLog(t *testing.T, records int) (string, []string) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log := audit.Open(path)
+
+	for i := 0; i < records; i++ {
+		if _, err := log.Append(audit.Record{Source: "file.txt", Kvv: "FF365893D899291C3BF505FB3175E880", SealDate: "240429"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path, strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+func TestAppend(t *testing.T) {
+	path, lines := writeLog(t, 3)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(lines))
+	}
+
+	verification, err := audit.Verify(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verification.Valid() || verification.Records != 3 {
+		t.Errorf("unexpected verification: %+v", verification)
+	}
+
+	record, err := audit.Open(path).Append(audit.Record{Source: "next.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Seq != 4 || record.PrevHash != verification.Head || record.User == "" {
+		t.Errorf("record not chained to the log: %+v", record)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines []string) []string
+	}{
+		{"modified record", func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], "file.txt", "other.txt", 1)
+			return lines
+		}},
+		{"removed record", func(lines []string) []string {
+			return append(lines[:1], lines[2:]...)
+		}},
+		{"reordered records", func(lines []string) []string {
+			lines[0], lines[1] = lines[1], lines[0]
+			return lines
+		}},
+		{"inserted record", func(lines []string) []string {
+			return append(lines[:2], append([]string{lines[0]}, lines[2:]...)...)
+		}},
+		{"invalid record", func(lines []string) []string {
+			lines[2] = "{"
+			return lines
+		}},
+	}
+
+	for _, test := range tests {
+		path, lines := writeLog(t, 4)
+		if err := os.WriteFile(path, []byte(strings.Join(test.tamper(lines), "\n")+"\n"), 0640); err != nil {
+			t.Fatal(err)
+		}
+
+		verification, err := audit.Verify(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verification.Valid() {
+			t.Errorf("%s: tampering not detected", test.name)
+		}
+	}
+}
+
+func TestAppendConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	// Each appender opens the log on its own, like separate watch, serve and seal processes
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log := audit.Open(path)
+			for j := 0; j < 25; j++ {
+				if _, err := log.Append(audit.Record{Source: "file.txt"}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	verification, err := audit.Verify(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verification.Valid() || verification.Records != 200 {
+		t.Errorf("unexpected verification: %+v", verification)
+	}
+}
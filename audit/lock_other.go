@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package audit
+
+import "os"
+
+// File locks are not supported on this platform, appends are only serialized within the process
+func lockFile(file *os.File) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
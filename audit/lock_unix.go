@@ -0,0 +1,23 @@
+//go:build unix
+
+package audit
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Take an exclusive lock on the file, waiting for other processes to release it
+func lockFile(file *os.File) error {
+	for {
+		err := unix.Flock(int(file.Fd()), unix.LOCK_EX)
+		if err != unix.EINTR {
+			return err
+		}
+	}
+}
+
+func unlockFile(file *os.File) error {
+	return unix.Flock(int(file.Fd()), unix.LOCK_UN)
+}
@@ -0,0 +1,316 @@
+package batch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/calendar"
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+)
+
+// The file name of the manifest written to the output directory by default
+const ManifestName = "manifest.json"
+
+// Seals a single Bankgiro file, e.g. using a key resolved once for the whole batch
+// Implementations must be safe for concurrent use
+type SealFunc func(bgFile *sign.BankgiroFile) error
+
+type Status string
+
+const (
+	StatusSealed  Status = "sealed"
+	StatusSkipped Status = "skipped"
+	StatusFailed  Status = "failed"
+)
+
+// InputDir: directory to read files from
+// OutputDir: directory to write sealed files to, subdirectories are recreated when Recursive is set
+// Patterns: glob patterns matched against the file names, all files are sealed when empty
+// Workers: number of files sealed concurrently, default is the number of CPUs
+// Overwrite: overwrite existing files in the output directory instead of skipping them
+// Charset: how files are converted to ISO-8859-1, the encoding is detected per file unless forced
+type Options struct {
+	InputDir  string
+	OutputDir string
+	Patterns  []string
+	Recursive bool
+	Workers   int
+	Overwrite bool
+	Charset   charset.Options
+}
+
+// The result of sealing a single file
+type Entry struct {
+	Input         string                 `json:"input"`
+	Output        string                 `json:"output,omitempty"`
+	InputSha256   string                 `json:"inputSha256,omitempty"`
+	OutputSha256  string                 `json:"outputSha256,omitempty"`
+	Encoding      string                 `json:"encoding,omitempty"`
+	Substitutions []charset.Substitution `json:"substitutions,omitempty"`
+	DateProblems  []calendar.DateProblem `json:"dateProblems,omitempty"`
+	SealDate      string                 `json:"sealDate,omitempty"`
+	Kvv           string                 `json:"kvv,omitempty"`
+	Mac           string                 `json:"mac,omitempty"`
+	Status        Status                 `json:"status"`
+	Reason        string                 `json:"reason,omitempty"`
+}
+
+// The result of sealing a directory
+type Manifest struct {
+	Started   time.Time `json:"started"`
+	Finished  time.Time `json:"finished"`
+	InputDir  string    `json:"inputDir"`
+	OutputDir string    `json:"outputDir"`
+	Sealed    int       `json:"sealed"`
+	Skipped   int       `json:"skipped"`
+	Failed    int       `json:"failed"`
+	Files     []Entry   `json:"files"`
+}
+
+// Find the files in the input directory matching the patterns
+// The returned paths are relative to the input directory and sorted
+func FindFiles(opts Options) ([]string, error) {
+	files := []string{}
+
+	outputDir, _ := filepath.Abs(opts.OutputDir)
+
+	err := filepath.WalkDir(opts.InputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path == opts.InputDir {
+				return nil
+			}
+
+			// Never descend into the output directory, it may be inside the input directory
+			if abs, _ := filepath.Abs(path); !opts.Recursive || abs == outputDir {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() || d.Name() == ManifestName {
+			return nil
+		}
+
+		matched, err := MatchesPatterns(d.Name(), opts.Patterns)
+		if err != nil || !matched {
+			return err
+		}
+
+		rel, err := filepath.Rel(opts.InputDir, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, rel)
+
+		return nil
+	})
+
+	sort.Strings(files)
+
+	return files, err
+}
+
+// Check if a file name matches any of the glob patterns, an empty list matches all names
+func MatchesPatterns(name string, patterns []string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+
+	for _, pattern := range patterns {
+		matched, err := filepath.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+
+		if matched {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Seal a single file and write the result to output
+// Files that are already sealed, and existing outputs unless overwrite is set, are skipped
+func SealFile(input string, output string, overwrite bool, sealFunc SealFunc) Entry {
+	return sealFile(input, output, overwrite, charset.Options{}, sealFunc)
+}
+
+func sealFile(input string, output string, overwrite bool, opts charset.Options, sealFunc SealFunc) Entry {
+	entry := Entry{Input: input, Output: output}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		return entry.fail(err)
+	}
+	entry.InputSha256 = Sha256(content)
+
+	if sign.IsSealed(content) {
+		entry.Output = ""
+		entry.Status = StatusSkipped
+		entry.Reason = "already sealed"
+		return entry
+	}
+
+	if _, err := os.Stat(output); err == nil && !overwrite {
+		entry.Status = StatusSkipped
+		entry.Reason = "output already exists"
+		return entry
+	}
+
+	bgFile := sign.NewBankgiroFile(opts)
+	bgFile.Write(content)
+	if err := bgFile.Prepare(); err != nil {
+		return entry.fail(err)
+	}
+	bgFile.Source = input
+	entry.Encoding = string(bgFile.Encoding.Encoding)
+	entry.Substitutions = bgFile.Substitutions
+
+	// The seal function may set the seal date the payment dates are checked against
+	err = sealFunc(bgFile)
+	entry.DateProblems = bgFile.DateProblems
+	if err != nil {
+		return entry.fail(err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return entry.fail(err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(output, flags, 0644)
+	if err != nil {
+		return entry.fail(err)
+	}
+	outputHash := sha256.New()
+	if _, err := bgFile.WriteTo(io.MultiWriter(f, outputHash)); err != nil {
+		f.Close()
+		return entry.fail(err)
+	}
+	if err := f.Close(); err != nil {
+		return entry.fail(err)
+	}
+
+	entry.OutputSha256 = hex.EncodeToString(outputHash.Sum(nil))
+	entry.SealDate = bgFile.Seal.SealDate
+	entry.Kvv = bgFile.Seal.GetKvvBgFormat()
+	entry.Mac = bgFile.Seal.GetMacBgFormat()
+	entry.Status = StatusSealed
+
+	return entry
+}
+
+func (e Entry) fail(err error) Entry {
+	e.Status = StatusFailed
+	e.Reason = err.Error()
+
+	return e
+}
+
+// Seal all matching files in the input directory concurrently
+func Run(opts Options, sealFunc SealFunc) (*Manifest, error) {
+	manifest := &Manifest{
+		Started:   time.Now(),
+		InputDir:  opts.InputDir,
+		OutputDir: opts.OutputDir,
+	}
+
+	files, err := FindFiles(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	manifest.Files = make([]Entry, len(files))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				manifest.Files[i] = sealFile(
+					filepath.Join(opts.InputDir, files[i]),
+					filepath.Join(opts.OutputDir, files[i]),
+					opts.Overwrite,
+					opts.Charset,
+					sealFunc,
+				)
+			}
+		}()
+	}
+
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, entry := range manifest.Files {
+		switch entry.Status {
+		case StatusSealed:
+			manifest.Sealed++
+		case StatusSkipped:
+			manifest.Skipped++
+		case StatusFailed:
+			manifest.Failed++
+		}
+	}
+
+	manifest.Finished = time.Now()
+
+	return manifest, nil
+}
+
+// Write the manifest as indented JSON
+func (m *Manifest) Write(path string) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// Get the exit code summarizing the batch: 0 if no file failed, 2 if some files failed
+func (m *Manifest) ExitCode() int {
+	if m.Failed > 0 {
+		return 2
+	}
+
+	return 0
+}
+
+// Get the hex encoded SHA-256 hash of the content
+func Sha256(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
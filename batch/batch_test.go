@@ -0,0 +1,175 @@
+package batch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/batch"
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+)
+
+const (
+	SignedBy     = "1234567890ABCDEF1234567890ABCDEF"
+	SignedByKvv  = "FF365893D899291C3BF505FB3175E880"
+	SignedOnDate = "240429"
+)
+
+func testSealFunc(bgFile *sign.BankgiroFile) error {
+	if err := bgFile.SetSealKey(SignedBy); err != nil {
+		return err
+	}
+	bgFile.SetSealDate(SignedOnDate)
+
+	return bgFile.Sign()
+}
+
+func copyTestFile(t *testing.T, name string, target string) {
+	content, err := os.ReadFile("../tests/sealFile/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(target, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := filepath.Join(inputDir, "sealed")
+
+	copyTestFile(t, "basic.txt", filepath.Join(inputDir, "basic.txt"))
+	copyTestFile(t, "blank-rows.txt", filepath.Join(inputDir, "nested", "blank-rows.txt"))
+	copyTestFile(t, "basic-signed.txt", filepath.Join(inputDir, "already-signed.txt"))
+	copyTestFile(t, "basic.txt", filepath.Join(inputDir, "ignored.dat"))
+
+	opts := batch.Options{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Patterns:  []string{"*.txt"},
+		Recursive: true,
+		Workers:   2,
+	}
+
+	manifest, err := batch.Run(opts, testSealFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Sealed != 2 || manifest.Skipped != 1 || manifest.Failed != 0 {
+		t.Fatalf("sealed %d, skipped %d, failed %d, want 2, 1, 0", manifest.Sealed, manifest.Skipped, manifest.Failed)
+	}
+
+	for _, entry := range manifest.Files {
+		if entry.Status != batch.StatusSealed {
+			continue
+		}
+
+		output, err := os.ReadFile(entry.Output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if batch.Sha256(output) != entry.OutputSha256 {
+			t.Errorf("%s: output hash does not match manifest", entry.Output)
+		}
+		if entry.Kvv != SignedByKvv || entry.SealDate != SignedOnDate || len(entry.Mac) != 32 {
+			t.Errorf("%s: unexpected manifest entry %+v", entry.Input, entry)
+		}
+		if !sign.IsSealed(output) {
+			t.Errorf("%s: output is not sealed", entry.Output)
+		}
+		if len(entry.DateProblems) == 0 {
+			t.Errorf("%s: expected the payment dates of 2024 to be flagged", entry.Input)
+		}
+	}
+
+	// A second run skips the existing outputs
+	manifest, err = batch.Run(opts, testSealFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Sealed != 0 || manifest.Skipped != 3 {
+		t.Errorf("second run sealed %d, skipped %d, want 0, 3", manifest.Sealed, manifest.Skipped)
+	}
+	if manifest.ExitCode() != 0 {
+		t.Errorf("ExitCode() = %d, want 0", manifest.ExitCode())
+	}
+}
+
+func TestRunNotRecursive(t *testing.T) {
+	inputDir := t.TempDir()
+
+	copyTestFile(t, "basic.txt", filepath.Join(inputDir, "basic.txt"))
+	copyTestFile(t, "blank-rows.txt", filepath.Join(inputDir, "nested", "blank-rows.txt"))
+
+	files, err := batch.FindFiles(batch.Options{InputDir: inputDir, OutputDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != "basic.txt" {
+		t.Errorf("FindFiles() = %v, want [basic.txt]", files)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	inputDir := t.TempDir()
+	copyTestFile(t, "basic.txt", filepath.Join(inputDir, "basic.txt"))
+
+	manifest, err := batch.Run(batch.Options{InputDir: inputDir, OutputDir: t.TempDir()}, func(bgFile *sign.BankgiroFile) error {
+		return bgFile.SetSealKey("not a key")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Failed != 1 || manifest.Files[0].Reason == "" {
+		t.Errorf("expected one failed file with a reason, got %+v", manifest.Files)
+	}
+	if manifest.ExitCode() != 2 {
+		t.Errorf("ExitCode() = %d, want 2", manifest.ExitCode())
+	}
+}
+
+func TestRunTransliterate(t *testing.T) {
+	inputDir := t.TempDir()
+
+	content, err := os.ReadFile("../tests/sealFile/basic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content = append([]byte("0120240416AUTOGIRO Łukasz"), content[len("0120240416AUTOGIRO Łukasz"):]...)
+	if err := os.WriteFile(filepath.Join(inputDir, "names.txt"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := batch.Run(batch.Options{InputDir: inputDir, OutputDir: t.TempDir()}, testSealFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Failed != 1 {
+		t.Fatalf("expected the file to fail without transliteration: %+v", manifest.Files)
+	}
+
+	manifest, err = batch.Run(batch.Options{
+		InputDir:  inputDir,
+		OutputDir: t.TempDir(),
+		Charset:   charset.Options{Transliterator: charset.DefaultTransliterator},
+	}, testSealFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := manifest.Files[0]
+	if entry.Status != batch.StatusSealed || entry.Encoding != string(charset.UTF8) {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if len(entry.Substitutions) != 1 || entry.Substitutions[0].Rune != 'Ł' || entry.Substitutions[0].Replacement != "L" {
+		t.Errorf("unexpected substitutions: %+v", entry.Substitutions)
+	}
+}
@@ -0,0 +1,148 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// The number of bank days before the payment date an Autogiro submission must reach Bankgirot
+const PaymentLeadDays = 1
+
+// A Swedish public holiday or other day the banks are closed
+type Holiday struct {
+	Date time.Time `json:"date"`
+	Name string    `json:"name"`
+}
+
+// Get a date at midnight UTC, the time zone dates are compared in
+func Date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Parse a date formatted as YYYYMMDD
+func ParseDate(s string) (time.Time, error) {
+	return time.Parse("20060102", s)
+}
+
+// Get the days the Swedish banks are closed in the year, other than Saturdays and Sundays, sorted by date
+// Holidays falling on a weekend are included
+func Holidays(year int) []Holiday {
+	easter := easterSunday(year)
+
+	return []Holiday{
+		{Date(year, time.January, 1), "Nyårsdagen"},
+		{Date(year, time.January, 6), "Trettondedag jul"},
+		{easter.AddDate(0, 0, -2), "Långfredagen"},
+		{easter, "Påskdagen"},
+		{easter.AddDate(0, 0, 1), "Annandag påsk"},
+		{Date(year, time.May, 1), "Första maj"},
+		{easter.AddDate(0, 0, 39), "Kristi himmelsfärdsdag"},
+		{easter.AddDate(0, 0, 49), "Pingstdagen"},
+		{Date(year, time.June, 6), "Sveriges nationaldag"},
+		{weekdayBetween(Date(year, time.June, 19), time.Friday), "Midsommarafton"},
+		{weekdayBetween(Date(year, time.June, 20), time.Saturday), "Midsommardagen"},
+		{weekdayBetween(Date(year, time.October, 31), time.Saturday), "Alla helgons dag"},
+		{Date(year, time.December, 24), "Julafton"},
+		{Date(year, time.December, 25), "Juldagen"},
+		{Date(year, time.December, 26), "Annandag jul"},
+		{Date(year, time.December, 31), "Nyårsafton"},
+	}
+}
+
+// Get Easter Sunday of the year with the anonymous Gregorian algorithm
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return Date(year, time.Month(month), day)
+}
+
+// Get the first day with the weekday in the week starting on the date
+func weekdayBetween(start time.Time, weekday time.Weekday) time.Time {
+	return start.AddDate(0, 0, (int(weekday)-int(start.Weekday())+7)%7)
+}
+
+// Get the holiday on the date, if any
+func HolidayOn(t time.Time) (Holiday, bool) {
+	date := Date(t.Year(), t.Month(), t.Day())
+	for _, holiday := range Holidays(t.Year()) {
+		if holiday.Date.Equal(date) {
+			return holiday, true
+		}
+	}
+
+	return Holiday{}, false
+}
+
+// Check if the banks are open on the date, Monday to Friday except holidays
+func IsBankDay(t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+
+	_, holiday := HolidayOn(t)
+
+	return !holiday
+}
+
+// Get the first bank day after the date
+func NextBankDay(t time.Time) time.Time {
+	return AddBankDays(t, 1)
+}
+
+// Get the last bank day before the date
+func PreviousBankDay(t time.Time) time.Time {
+	return AddBankDays(t, -1)
+}
+
+// Get the date the given number of bank days after the date, or before it when negative
+// With 0, the date is returned as is
+func AddBankDays(t time.Time, days int) time.Time {
+	date := Date(t.Year(), t.Month(), t.Day())
+
+	step := 1
+	if days < 0 {
+		step, days = -1, -days
+	}
+
+	for days > 0 {
+		date = date.AddDate(0, 0, step)
+		if IsBankDay(date) {
+			days--
+		}
+	}
+
+	return date
+}
+
+// Get the bank day a payment on the date is made, the date itself or the next bank day
+func PaymentDay(t time.Time) time.Time {
+	if IsBankDay(t) {
+		return Date(t.Year(), t.Month(), t.Day())
+	}
+
+	return NextBankDay(t)
+}
+
+// Get the last bank day a submission with payments on the date must reach Bankgirot
+func Deadline(paymentDate time.Time, leadDays int) time.Time {
+	return AddBankDays(PaymentDay(paymentDate), -leadDays)
+}
+
+// Describe why the date is not a bank day
+func describe(t time.Time) string {
+	if holiday, found := HolidayOn(t); found {
+		return holiday.Name
+	}
+
+	return fmt.Sprintf("a %s", t.Weekday())
+}
@@ -0,0 +1,114 @@
+package calendar_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/calendar"
+)
+
+func date(s string) time.Time {
+	t, err := calendar.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func TestHolidays(t *testing.T) {
+	tests := []struct {
+		name string
+		year int
+		date string
+	}{
+		{"Påskdagen", 2000, "20000423"},
+		{"Påskdagen", 2024, "20240331"},
+		{"Påskdagen", 2025, "20250420"},
+		{"Påskdagen", 2038, "20380425"},
+		{"Långfredagen", 2026, "20260403"},
+		{"Kristi himmelsfärdsdag", 2025, "20250529"},
+		{"Midsommarafton", 2025, "20250620"},
+		{"Midsommardagen", 2026, "20260620"},
+		{"Alla helgons dag", 2025, "20251101"},
+		{"Alla helgons dag", 2026, "20261031"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			for _, holiday := range calendar.Holidays(tt.year) {
+				if holiday.Name == tt.name {
+					if !holiday.Date.Equal(date(tt.date)) {
+						t.Errorf("%s %d = %s, expected %s", tt.name, tt.year, holiday.Date.Format("20060102"), tt.date)
+					}
+					return
+				}
+			}
+			t.Errorf("%s not found", tt.name)
+		})
+	}
+}
+
+func TestBankDays(t *testing.T) {
+	tests := []struct {
+		name     string
+		date     string
+		days     int
+		expected string
+	}{
+		{"over easter", "20250417", 1, "20250422"},
+		{"back over easter", "20250422", -1, "20250417"},
+		{"over midsummer", "20250619", 1, "20250623"},
+		{"over christmas", "20251223", 2, "20251230"},
+		{"over new year", "20251230", 1, "20260102"},
+		{"weekend", "20241018", 1, "20241021"},
+		{"several", "20241014", 5, "20241021"},
+		{"zero", "20241019", 0, "20241019"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.AddBankDays(date(tt.date), tt.days); !got.Equal(date(tt.expected)) {
+				t.Errorf("AddBankDays(%s, %d) = %s, expected %s", tt.date, tt.days, got.Format("20060102"), tt.expected)
+			}
+		})
+	}
+
+	if calendar.IsBankDay(date("20251224")) || calendar.IsBankDay(date("20241019")) || !calendar.IsBankDay(date("20241018")) {
+		t.Error("IsBankDay() is wrong for Julafton, a Saturday or a Friday")
+	}
+	if got := calendar.Deadline(date("20250419"), calendar.PaymentLeadDays); !got.Equal(date("20250417")) {
+		t.Errorf("Deadline() = %s, expected 20250417", got.Format("20060102"))
+	}
+}
+
+func TestCheckPaymentDates(t *testing.T) {
+	content := strings.Join([]string{
+		"0120250415AUTOGIRO                                            0069240009925256  ",
+		"82202504220    000000000020790200000008000000099252560040106553200145           ",
+		"82202504190    000000000021458700000004000000099252560040107191200141           ",
+		"32202504150    000000000012095900000007500000099252560040106552200146           ",
+		"82GENAST  0    000000000012095900000007500000099252560040106552200146           ",
+		"82202513010    000000000012095900000007500000099252560040106552200146           ",
+	}, "\r\n")
+
+	problems := calendar.CheckPaymentDates([]byte(content), date("20250415"), calendar.PaymentLeadDays)
+
+	expected := []calendar.DateProblem{
+		{Row: 3, Code: "82", Date: "20250419", PaymentDay: "20250422"},
+		{Row: 4, Code: "32", Date: "20250415"},
+		{Row: 6, Code: "82", Date: "20251301"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("CheckPaymentDates() = %v", problems)
+	}
+	for i, p := range problems {
+		p.Problem = ""
+		if p != expected[i] {
+			t.Errorf("problem = %+v, expected %+v", p, expected[i])
+		}
+	}
+
+	if !strings.Contains(problems[0].String(), "is a Saturday") || !strings.Contains(problems[1].String(), "by 20250414") {
+		t.Errorf("problems = %v", problems)
+	}
+}
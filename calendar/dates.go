@@ -0,0 +1,69 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+)
+
+// A payment date in a submission file that Bankgirot would move or reject
+// Row: the row in the file, numbered from 1
+// Date: the date field as UTF-8, without surrounding spaces
+// PaymentDay: the bank day the payment would be made, set when the date is moved
+type DateProblem struct {
+	Row        int    `json:"row"`
+	Code       string `json:"code"`
+	Date       string `json:"date"`
+	Problem    string `json:"problem"`
+	PaymentDay string `json:"paymentDay,omitempty"`
+}
+
+func (p DateProblem) String() string {
+	return fmt.Sprintf("row %d: TK%s payment date %s %s", p.Row, p.Code, p.Date, p.Problem)
+}
+
+// Check the payment dates of the payments (TK82) and credits (TK32) in an ISO-8859-1 Autogiro submission file
+// Dates on weekends and holidays are moved to the next bank day, dates whose deadline has passed
+// on the day the file is sent are rejected, leadDays is the number of bank days the file must arrive before the payment date
+// Payments with the date GENAST are made as soon as possible and are not checked
+func CheckPaymentDates(content []byte, sent time.Time, leadDays int) []DateProblem {
+	problems := []DateProblem{}
+	today := Date(sent.Year(), sent.Month(), sent.Day())
+
+	for i, row := range strings.Split(string(content), "\n") {
+		row = strings.TrimRight(row, "\r")
+		if len(row) < 10 || (row[0:2] != "82" && row[0:2] != "32") {
+			continue
+		}
+
+		problem := DateProblem{Row: i + 1, Code: row[0:2], Date: charset.IsoToUtf8(strings.TrimSpace(row[2:10]))}
+		if problem.Date == "GENAST" {
+			continue
+		}
+
+		date, err := ParseDate(problem.Date)
+		if err != nil {
+			problem.Problem = "is not a date, Bankgirot rejects the payment"
+			problems = append(problems, problem)
+			continue
+		}
+
+		paymentDay := PaymentDay(date)
+		deadline := Deadline(date, leadDays)
+		switch {
+		case today.After(deadline):
+			problem.Problem = fmt.Sprintf("has passed, the file had to reach Bankgirot by %s and the payment is rejected", deadline.Format("20060102"))
+		case !paymentDay.Equal(date):
+			problem.Problem = fmt.Sprintf("is %s, not a bank day, the payment is made on %s", describe(date), paymentDay.Format("20060102"))
+			problem.PaymentDay = paymentDay.Format("20060102")
+		default:
+			continue
+		}
+
+		problems = append(problems, problem)
+	}
+
+	return problems
+}
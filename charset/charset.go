@@ -0,0 +1,451 @@
+package charset
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// A character encoding Bankgiro files are read from
+type Encoding string
+
+const (
+	UTF8        Encoding = "utf-8"
+	UTF8BOM     Encoding = "utf-8-bom"
+	ISO88591    Encoding = "iso-8859-1"
+	Windows1252 Encoding = "windows-1252"
+	IBM437      Encoding = "ibm437"
+	IBM850      Encoding = "ibm850"
+	UTF16LE     Encoding = "utf-16le"
+	UTF16BE     Encoding = "utf-16be"
+)
+
+// The supported encodings
+var Encodings = []Encoding{UTF8, UTF8BOM, ISO88591, Windows1252, IBM437, IBM850, UTF16LE, UTF16BE}
+
+// Replaces characters that cannot be represented in ISO-8859-1 (ASCII SUB)
+const Substitute = 0x1A
+
+var aliases = map[string]Encoding{
+	"utf8":        UTF8,
+	"utf8bom":     UTF8BOM,
+	"utf8-bom":    UTF8BOM,
+	"iso8859-1":   ISO88591,
+	"iso-8859-1":  ISO88591,
+	"latin1":      ISO88591,
+	"latin-1":     ISO88591,
+	"cp1252":      Windows1252,
+	"windows1252": Windows1252,
+	"cp437":       IBM437,
+	"cp850":       IBM850,
+	"utf16le":     UTF16LE,
+	"utf16be":     UTF16BE,
+}
+
+// Swedish letters and the bytes they are stored as, used to tell DOS code pages from ISO-8859-1
+var (
+	latinLetters = []byte{0xC4, 0xC5, 0xC9, 0xD6, 0xDC, 0xE4, 0xE5, 0xE9, 0xF6, 0xFC}
+	dosLetters   = []byte{0x81, 0x82, 0x84, 0x86, 0x8E, 0x8F, 0x90, 0x94, 0x99, 0x9A}
+	// Bytes without a character in Windows-1252
+	undefined1252 = []byte{0x81, 0x8D, 0x8F, 0x90, 0x9D}
+)
+
+// Get an encoding by name, common aliases such as latin1 and cp1252 are accepted
+func ParseEncoding(name string) (Encoding, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, encoding := range Encodings {
+		if string(encoding) == name {
+			return encoding, nil
+		}
+	}
+
+	if encoding, found := aliases[name]; found {
+		return encoding, nil
+	}
+
+	return "", fmt.Errorf("unsupported encoding: %s", name)
+}
+
+// The encoding chosen for some content and why
+// Forced: the encoding was given by the caller rather than detected
+type Detection struct {
+	Encoding Encoding `json:"encoding"`
+	Reason   string   `json:"reason"`
+	Forced   bool     `json:"forced,omitempty"`
+}
+
+func (d Detection) String() string {
+	return fmt.Sprintf("%s (%s)", d.Encoding, d.Reason)
+}
+
+// Detect the encoding of the content
+// Byte order marks are trusted, then UTF-16 is recognised by its zero bytes and valid UTF-8 is taken as UTF-8
+// Other content is read as a single byte encoding, chosen by how Swedish letters and the bytes 0x80-0x9F are used
+func Detect(data []byte) Detection {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return Detection{Encoding: UTF8BOM, Reason: "UTF-8 byte order mark"}
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return Detection{Encoding: UTF16LE, Reason: "UTF-16 little endian byte order mark"}
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return Detection{Encoding: UTF16BE, Reason: "UTF-16 big endian byte order mark"}
+	}
+
+	if encoding, found := detectUtf16(data); found {
+		return Detection{Encoding: encoding, Reason: "no byte order mark, but every other byte is zero"}
+	}
+
+	if utf8.Valid(data) {
+		multiByte := utf8.RuneCount(data) - countAscii(data)
+		if multiByte == 0 {
+			return Detection{Encoding: UTF8, Reason: "ASCII only, read the same in every supported encoding"}
+		}
+
+		return Detection{Encoding: UTF8, Reason: fmt.Sprintf("valid UTF-8 with %d non-ASCII characters", multiByte)}
+	}
+
+	latin := countBytes(data, latinLetters)
+	dos := countBytes(data, dosLetters)
+	c1 := 0
+	for _, b := range data {
+		if b >= 0x80 && b <= 0x9F {
+			c1++
+		}
+	}
+
+	switch {
+	case c1 == 0:
+		return Detection{Encoding: ISO88591, Reason: fmt.Sprintf("not valid UTF-8, no bytes in 0x80-0x9F and %d Swedish letters as ISO-8859-1", latin)}
+	case dos > latin:
+		return Detection{Encoding: IBM850, Reason: fmt.Sprintf("not valid UTF-8, %d Swedish letters as IBM850 against %d as ISO-8859-1 (IBM437 stores them the same way)", dos, latin)}
+	case countBytes(data, undefined1252) > 0:
+		return Detection{Encoding: ISO88591, Reason: "not valid UTF-8, bytes in 0x80-0x9F that have no character in Windows-1252, read as ISO-8859-1 control characters"}
+	}
+
+	return Detection{Encoding: Windows1252, Reason: fmt.Sprintf("not valid UTF-8, %d bytes in 0x80-0x9F used as Windows-1252 characters", c1)}
+}
+
+// UTF-16 encoded text from Bankgiro files is ASCII, so either the odd or the even bytes are nearly all zero
+func detectUtf16(data []byte) (Encoding, bool) {
+	if len(data) < 4 || len(data)%2 != 0 {
+		return "", false
+	}
+
+	sample := data
+	if len(sample) > 4096 {
+		sample = sample[:4096]
+	}
+
+	evenZero, oddZero := 0, 0
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0 {
+			evenZero++
+		}
+		if sample[i+1] == 0 {
+			oddZero++
+		}
+	}
+
+	pairs := len(sample) / 2
+	switch {
+	case oddZero*10 >= pairs*9 && evenZero*10 < pairs:
+		return UTF16LE, true
+	case evenZero*10 >= pairs*9 && oddZero*10 < pairs:
+		return UTF16BE, true
+	}
+
+	return "", false
+}
+
+func countAscii(data []byte) int {
+	count := 0
+	for _, b := range data {
+		if b < utf8.RuneSelf {
+			count++
+		}
+	}
+
+	return count
+}
+
+func countBytes(data []byte, set []byte) int {
+	count := 0
+	for _, b := range data {
+		if bytes.IndexByte(set, b) >= 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+func decoder(enc Encoding) (encoding.Encoding, error) {
+	switch enc {
+	case UTF8, UTF8BOM:
+		return unicode.UTF8BOM, nil
+	case ISO88591:
+		return charmap.ISO8859_1, nil
+	case Windows1252:
+		return charmap.Windows1252, nil
+	case IBM437:
+		return charmap.CodePage437, nil
+	case IBM850:
+		return charmap.CodePage850, nil
+	case UTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	case UTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM), nil
+	}
+
+	return nil, fmt.Errorf("unsupported encoding: %s", enc)
+}
+
+// Decode the content from the encoding into a UTF-8 string, a byte order mark is removed
+func Decode(data []byte, enc Encoding) (string, error) {
+	dec, err := decoder(enc)
+	if err != nil {
+		return "", err
+	}
+
+	if enc == UTF8 && !utf8.Valid(data) {
+		return "", fmt.Errorf("content is not valid UTF-8")
+	}
+
+	decoded, err := dec.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("could not decode %s: %w", enc, err)
+	}
+
+	return string(decoded), nil
+}
+
+// A character that cannot be represented in ISO-8859-1
+// Line and Column are counted in characters from 1
+type Character struct {
+	Line   int  `json:"line"`
+	Column int  `json:"column"`
+	Rune   rune `json:"rune"`
+}
+
+func (c Character) String() string {
+	if c.Rune == utf8.RuneError {
+		return fmt.Sprintf("invalid character at line %d column %d", c.Line, c.Column)
+	}
+
+	return fmt.Sprintf("'%c' (U+%04X) at line %d column %d", c.Rune, c.Rune, c.Line, c.Column)
+}
+
+// Returned when the content has characters that cannot be represented in ISO-8859-1
+type UnrepresentableError struct {
+	Encoding   Encoding
+	Characters []Character
+}
+
+func (e *UnrepresentableError) Error() string {
+	shown := e.Characters
+	if len(shown) > 5 {
+		shown = shown[:5]
+	}
+
+	list := make([]string, len(shown))
+	for i, char := range shown {
+		list[i] = char.String()
+	}
+
+	more := ""
+	if len(e.Characters) > len(shown) {
+		more = fmt.Sprintf(" and %d more", len(e.Characters)-len(shown))
+	}
+
+	return fmt.Sprintf("%d characters read as %s cannot be represented in ISO-8859-1: %s%s", len(e.Characters), e.Encoding, strings.Join(list, ", "), more)
+}
+
+// The content converted to ISO-8859-1
+// Content: shares memory with the input when it already is ISO-8859-1 or ASCII
+// Unrepresentable: the characters replaced with Substitute
+// Substitutions: the characters replaced by transliteration
+type Result struct {
+	Detection       Detection
+	Content         []byte
+	Unrepresentable []Character
+	Substitutions   []Substitution
+}
+
+// Encoding: the encoding to read the content as, detected when empty
+// Transliterator: when set, characters that cannot be represented in ISO-8859-1 are transliterated
+type Options struct {
+	Encoding       Encoding
+	Transliterator *Transliterator
+}
+
+// Convert the content to ISO-8859-1, detecting the encoding unless one is forced
+// Characters that cannot be represented are replaced with Substitute and returned as an *UnrepresentableError
+// together with the result, line endings are left as they are
+func ToIso(data []byte, force Encoding) (*Result, error) {
+	return Convert(data, Options{Encoding: force})
+}
+
+// Convert the content to ISO-8859-1 with the given options
+// Characters that can neither be represented nor transliterated are replaced with Substitute
+// and returned as an *UnrepresentableError together with the result, line endings are left as they are
+func Convert(data []byte, opts Options) (*Result, error) {
+	detection := Detect(data)
+	if opts.Encoding != "" {
+		detection = Detection{Encoding: opts.Encoding, Reason: "given by the caller", Forced: true}
+	}
+
+	result := &Result{Detection: detection}
+
+	// ISO-8859-1 and ASCII content is used as is, without copying
+	if detection.Encoding == ISO88591 || (detection.Encoding == UTF8 && countAscii(data) == len(data)) {
+		result.Content = data
+		return result, nil
+	}
+
+	var decoded []byte
+	switch detection.Encoding {
+	case UTF8, UTF8BOM:
+		if detection.Encoding == UTF8 && !utf8.Valid(data) {
+			return nil, fmt.Errorf("content is not valid UTF-8")
+		}
+		decoded = bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+	default:
+		decodedString, err := Decode(data, detection.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		decoded = []byte(decodedString)
+	}
+
+	result.Content = make([]byte, 0, len(decoded))
+
+	// Replacements longer than one character are taken up by the padding after them, so fixed-width rows keep their width
+	var widthErr error
+	grown := []grownCharacter{}
+	endLine := func() {
+		if err := takeUpGrowth(result, grown); err != nil && widthErr == nil {
+			widthErr = err
+		}
+		grown = grown[:0]
+	}
+
+	walk(decoded, func(r rune, line, column int) {
+		if r == '\n' || r == '\r' {
+			endLine()
+			result.Content = append(result.Content, byte(r))
+			return
+		}
+
+		if representable(r) {
+			result.Content = append(result.Content, byte(r))
+			return
+		}
+
+		char := Character{Line: line, Column: column, Rune: r}
+
+		replacement := string(r)
+		if opts.Transliterator != nil {
+			replacement = opts.Transliterator.Rune(r)
+			result.Substitutions = append(result.Substitutions, Substitution{char, replacement})
+		}
+
+		written := 0
+		for _, rr := range replacement {
+			if representable(rr) {
+				result.Content = append(result.Content, byte(rr))
+			} else {
+				result.Content = append(result.Content, Substitute)
+				result.Unrepresentable = append(result.Unrepresentable, char)
+			}
+			written++
+		}
+		if written > 1 {
+			grown = append(grown, grownCharacter{char, replacement, len(result.Content), written - 1})
+		}
+	})
+	endLine()
+
+	if widthErr != nil {
+		return nil, widthErr
+	}
+	if len(result.Unrepresentable) > 0 {
+		return result, &UnrepresentableError{Encoding: detection.Encoding, Characters: result.Unrepresentable}
+	}
+
+	return result, nil
+}
+
+// A character replaced with more than one character
+// End: the index in the converted content after the replacement
+// Growth: the number of characters added
+type grownCharacter struct {
+	Character
+	Replacement string
+	End         int
+	Growth      int
+}
+
+// Remove as many spaces as each replacement added from the padding of its field, so the row keeps its width
+// The padding is the first run of at least two spaces after the replacement, or the spaces ending the line.
+// A single space between two words is never removed, and padding followed by another field keeps at least one space.
+// The replacements are on the last line of the content. A replacement without enough padding after it is an error
+func takeUpGrowth(result *Result, grown []grownCharacter) error {
+	// The last replacement first, so the positions of the earlier ones stay valid
+	for i := len(grown) - 1; i >= 0; i-- {
+		g := grown[i]
+
+		start, end := g.End, g.End
+		for start < len(result.Content) {
+			for start < len(result.Content) && result.Content[start] != ' ' {
+				start++
+			}
+			end = start
+			for end < len(result.Content) && result.Content[end] == ' ' {
+				end++
+			}
+
+			if end-start >= 2 || end == len(result.Content) {
+				break
+			}
+			start = end
+		}
+
+		available := end - start
+		if end < len(result.Content) {
+			available--
+		}
+
+		if available < g.Growth {
+			return fmt.Errorf("%s replaced with \"%s\" makes line %d %d characters longer, there is not enough padding after it", g.Character, g.Replacement, g.Line, g.Growth)
+		}
+
+		result.Content = append(result.Content[:start], result.Content[start+g.Growth:]...)
+	}
+
+	return nil
+}
+
+// Convert an ISO-8859-1 string, as returned by ToIso, to UTF-8
+func IsoToUtf8(s string) string {
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+
+	return string(runes)
+}
+
+// Convert ISO-8859-1 strings to UTF-8
+func IsoToUtf8Strings(s []string) []string {
+	converted := make([]string, len(s))
+	for i, row := range s {
+		converted[i] = IsoToUtf8(row)
+	}
+
+	return converted
+}
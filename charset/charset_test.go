@@ -0,0 +1,130 @@
+package charset_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    charset.Encoding
+	}{
+		{"ascii", []byte("0120240416AUTOGIRO\r\n"), charset.UTF8},
+		{"utf-8", []byte("MAK/ÄNDRINGSLISTA\r\n"), charset.UTF8},
+		{"utf-8 bom", []byte("\xEF\xBB\xBFMAK/ÄNDRINGSLISTA\r\n"), charset.UTF8BOM},
+		{"iso-8859-1", []byte("MAK/\xC4NDRINGSLISTA \xE5\xE4\xF6\r\n"), charset.ISO88591},
+		{"windows-1252", []byte("MAK/\xC4NDRINGSLISTA \x80 \x93quoted\x94\r\n"), charset.Windows1252},
+		{"ibm850", []byte("MAK/\x8ENDRINGSLISTA \x86\x84\x94\r\n"), charset.IBM850},
+		{"iso-8859-1 control characters", []byte("\xC4\xC5 \x81\x8D\r\n"), charset.ISO88591},
+		{"utf-16le bom", []byte("\xFF\xFE0\x001\x00"), charset.UTF16LE},
+		{"utf-16be bom", []byte("\xFE\xFF\x000\x001"), charset.UTF16BE},
+		{"utf-16le", []byte("0\x001\x00A\x00U\x00\r\x00\n\x00"), charset.UTF16LE},
+		{"utf-16be", []byte("\x000\x001\x00A\x00U\x00\r\x00\n"), charset.UTF16BE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := charset.Detect(tt.content)
+			if got.Encoding != tt.want {
+				t.Errorf("Detect() = %s, expected %s", got, tt.want)
+			}
+			if got.Reason == "" {
+				t.Error("Detect() gave no reason")
+			}
+		})
+	}
+}
+
+func TestToIso(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		force   charset.Encoding
+		want    string
+	}{
+		{"utf-8", []byte("ÅÄÖ åäö\r\n"), "", "\xC5\xC4\xD6 \xE5\xE4\xF6\r\n"},
+		{"utf-8 bom", []byte("\xEF\xBB\xBFÄ\r\n"), "", "\xC4\r\n"},
+		{"iso-8859-1", []byte("\xC5\xC4\xD6\r\n"), "", "\xC5\xC4\xD6\r\n"},
+		{"ibm850", []byte("\x8F\x8E\x99 \x86\x84\x94"), "", "\xC5\xC4\xD6 \xE5\xE4\xF6"},
+		{"utf-16le", []byte("\xFF\xFE\xC4\x00\r\x00\n\x00"), "", "\xC4\r\n"},
+		{"forced ibm437", []byte("\x8E"), charset.IBM437, "\xC4"},
+		{"forced iso-8859-1 on utf-8", []byte("Ä"), charset.ISO88591, "\xC3\x84"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := charset.ToIso(tt.content, tt.force)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got.Content) != tt.want {
+				t.Errorf("ToIso() = %q, expected %q", got.Content, tt.want)
+			}
+			if got.Detection.Forced != (tt.force != "") {
+				t.Errorf("ToIso() forced = %v", got.Detection.Forced)
+			}
+		})
+	}
+}
+
+func TestToIsoUnrepresentable(t *testing.T) {
+	content := []byte("01 \xC5\xC4\xD6\r\n02 \x80 and \x93x\x94\n03 \x80")
+
+	result, err := charset.ToIso(content, "")
+
+	var unrepresentable *charset.UnrepresentableError
+	if !errors.As(err, &unrepresentable) {
+		t.Fatalf("ToIso() error = %v, expected an UnrepresentableError", err)
+	}
+	if unrepresentable.Encoding != charset.Windows1252 {
+		t.Errorf("encoding = %s", unrepresentable.Encoding)
+	}
+
+	expected := []charset.Character{
+		{Line: 2, Column: 4, Rune: '€'},
+		{Line: 2, Column: 10, Rune: '“'},
+		{Line: 2, Column: 12, Rune: '”'},
+		{Line: 3, Column: 4, Rune: '€'},
+	}
+	if len(unrepresentable.Characters) != len(expected) {
+		t.Fatalf("characters = %v, expected %v", unrepresentable.Characters, expected)
+	}
+	for i, char := range expected {
+		if unrepresentable.Characters[i] != char {
+			t.Errorf("character %d = %v, expected %v", i, unrepresentable.Characters[i], char)
+		}
+	}
+
+	if string(result.Content) != "01 \xC5\xC4\xD6\r\n02 \x1A and \x1Ax\x1A\n03 \x1A" {
+		t.Errorf("content = %q", result.Content)
+	}
+}
+
+func TestDecodeInvalidUtf8(t *testing.T) {
+	if _, err := charset.ToIso([]byte("\xC4"), charset.UTF8); err == nil {
+		t.Error("expected an error reading invalid UTF-8 as UTF-8")
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := map[string]charset.Encoding{
+		"utf-8":        charset.UTF8,
+		"UTF8":         charset.UTF8,
+		"latin1":       charset.ISO88591,
+		"cp1252":       charset.Windows1252,
+		"Windows-1252": charset.Windows1252,
+		"cp850":        charset.IBM850,
+		"utf-16le":     charset.UTF16LE,
+	}
+	for name, want := range tests {
+		if got, err := charset.ParseEncoding(name); err != nil || got != want {
+			t.Errorf("ParseEncoding(%s) = %s, %v, expected %s", name, got, err, want)
+		}
+	}
+
+	if _, err := charset.ParseEncoding("ebcdic"); err == nil {
+		t.Error("expected an error for an unsupported encoding")
+	}
+}
@@ -0,0 +1,176 @@
+package charset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Replaces characters that cannot be represented in ISO-8859-1 with Bankgiro-safe text
+// Table: replacements tried first, e.g. ł to l or € to EUR
+// Fold: when set, other characters are decomposed (Unicode NFKD) and their combining marks dropped, e.g. ş to s
+// Fallback: the replacement for characters neither the table nor folding can handle
+type Transliterator struct {
+	Table    map[rune]string
+	Fold     bool
+	Fallback string
+}
+
+// Replacements for characters NFKD folding does not handle
+var DefaultTable = map[rune]string{
+	'ł': "l", 'Ł': "L",
+	'đ': "d", 'Đ': "D",
+	'ħ': "h", 'Ħ': "H",
+	'ı': "i",
+	'ŋ': "n", 'Ŋ': "N",
+	'ŧ': "t", 'Ŧ': "T",
+	'œ': "oe", 'Œ': "OE",
+	'ſ': "s",
+	'ƒ': "f",
+	'€': "EUR",
+	'™': "TM",
+	'‘': "'", '’': "'", '‚': "'", '‹': "'", '›': "'", '′': "'",
+	'“': "\"", '”': "\"", '„': "\"", '″': "\"",
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-", '−': "-",
+	'•': "*",
+	'…': "...",
+}
+
+// The transliteration used unless another is configured: the default table, NFKD folding and ? as fallback
+var DefaultTransliterator = &Transliterator{
+	Table:    DefaultTable,
+	Fold:     true,
+	Fallback: "?",
+}
+
+// A character replaced during transliteration
+type Substitution struct {
+	Character
+	Replacement string `json:"replacement"`
+}
+
+func (s Substitution) String() string {
+	return fmt.Sprintf("%s replaced with \"%s\"", s.Character, s.Replacement)
+}
+
+// Read a transliteration table from a JSON file mapping characters to replacements, e.g. {"ł": "l"}
+// The entries are added to the default table, replacing default entries for the same character
+func LoadTransliterator(path string) (*Transliterator, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[string]string{}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("invalid transliteration table %s: %w", path, err)
+	}
+
+	table := make(map[rune]string, len(DefaultTable)+len(entries))
+	for from, to := range DefaultTable {
+		table[from] = to
+	}
+
+	for from, to := range entries {
+		runes := []rune(from)
+		if len(runes) != 1 {
+			return nil, fmt.Errorf("invalid transliteration table %s: %q is not a single character", path, from)
+		}
+
+		if !Representable(to) {
+			return nil, fmt.Errorf("invalid transliteration table %s: replacement %q for %q cannot be represented in ISO-8859-1", path, to, from)
+		}
+
+		table[runes[0]] = to
+	}
+
+	return &Transliterator{Table: table, Fold: true, Fallback: DefaultTransliterator.Fallback}, nil
+}
+
+// Check if every character in the string can be represented in ISO-8859-1
+func Representable(s string) bool {
+	for _, r := range s {
+		if !representable(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func representable(r rune) bool {
+	return r <= 0xFF && r != unicode.ReplacementChar
+}
+
+// Get the replacement for a single character, characters that can be represented in ISO-8859-1 are kept
+func (t *Transliterator) Rune(r rune) string {
+	if representable(r) {
+		return string(r)
+	}
+
+	if replacement, found := t.Table[r]; found {
+		return replacement
+	}
+
+	if t.Fold {
+		var folded strings.Builder
+		for _, d := range norm.NFKD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) {
+				continue
+			}
+
+			if !representable(d) {
+				folded.Reset()
+				break
+			}
+
+			folded.WriteRune(d)
+		}
+
+		if folded.Len() > 0 {
+			return folded.String()
+		}
+	}
+
+	return t.Fallback
+}
+
+// Transliterate the characters in the string that cannot be represented in ISO-8859-1
+// Every substitution is returned with its position, lines and columns are counted in characters from 1
+func (t *Transliterator) String(s string) (string, []Substitution) {
+	var out strings.Builder
+	substitutions := []Substitution{}
+
+	walk([]byte(s), func(r rune, line, column int) {
+		replacement := t.Rune(r)
+		if replacement != string(r) {
+			substitutions = append(substitutions, Substitution{Character{Line: line, Column: column, Rune: r}, replacement})
+		}
+		out.WriteString(replacement)
+	})
+
+	return out.String(), substitutions
+}
+
+// Call fn with every UTF-8 character in b and its position
+func walk(b []byte, fn func(r rune, line, column int)) {
+	line, column := 1, 0
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		i += size
+
+		column++
+		fn(r, line, column)
+
+		// A CR followed by LF is a single line break
+		if r == '\n' || (r == '\r' && (i >= len(b) || b[i] != '\n')) {
+			line++
+			column = 0
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package charset_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+)
+
+func TestTransliterator_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"latin-1 kept", "Åsa Öberg, Ängelholm", "Åsa Öberg, Ängelholm"},
+		{"table", "Łukasz Wałęsa", "Lukasz Walesa"},
+		{"folding", "Şükrü Çelik, Brașov", "Sükrü Çelik, Brasov"},
+		{"ligature", "ﬁrma", "firma"},
+		{"full width", "ＡＢ", "AB"},
+		{"euro and quotes", "€ 10 “best” – ok…", "EUR 10 \"best\" - ok..."},
+		{"emoji", "Anna 😀", "Anna ?"},
+		{"no folding to non latin-1", "Ωmega", "?mega"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, substitutions := charset.DefaultTransliterator.String(tt.input)
+			if got != tt.want {
+				t.Errorf("String() = %q, expected %q", got, tt.want)
+			}
+			if !charset.Representable(got) {
+				t.Errorf("String() = %q cannot be represented in ISO-8859-1", got)
+			}
+			if (len(substitutions) == 0) != (tt.input == tt.want) {
+				t.Errorf("String() substitutions = %v", substitutions)
+			}
+		})
+	}
+}
+
+func TestTransliterator_Substitutions(t *testing.T) {
+	_, substitutions := charset.DefaultTransliterator.String("ok\r\nał\nb€")
+
+	expected := []charset.Substitution{
+		{Character: charset.Character{Line: 2, Column: 2, Rune: 'ł'}, Replacement: "l"},
+		{Character: charset.Character{Line: 3, Column: 2, Rune: '€'}, Replacement: "EUR"},
+	}
+	if len(substitutions) != len(expected) {
+		t.Fatalf("substitutions = %v, expected %v", substitutions, expected)
+	}
+	for i := range expected {
+		if substitutions[i] != expected[i] {
+			t.Errorf("substitution %d = %v, expected %v", i, substitutions[i], expected[i])
+		}
+	}
+}
+
+func TestConvertTransliterate(t *testing.T) {
+	content := []byte("0120240416AUTOGIRO\r\n82 Łódź ÅÄÖ 😀\r\n")
+
+	if _, err := charset.ToIso(content, ""); err == nil {
+		t.Error("expected an error without transliteration")
+	}
+
+	result, err := charset.Convert(content, charset.Options{Transliterator: charset.DefaultTransliterator})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(result.Content) != "0120240416AUTOGIRO\r\n82 L\xF3dz \xC5\xC4\xD6 ?\r\n" {
+		t.Errorf("Convert() = %q", result.Content)
+	}
+	if len(result.Substitutions) != 3 {
+		t.Errorf("Convert() substitutions = %v", result.Substitutions)
+	}
+
+	// A fallback that cannot be represented is still flagged
+	_, err = charset.Convert(content, charset.Options{Transliterator: &charset.Transliterator{Fallback: "😀"}})
+	var unrepresentable *charset.UnrepresentableError
+	if !errors.As(err, &unrepresentable) {
+		t.Errorf("Convert() error = %v, expected an UnrepresentableError", err)
+	}
+}
+
+func TestConvertTransliterateWidth(t *testing.T) {
+	// The reference field at 54-69 is followed by the result code at 80
+	row := "82 " + strings.Repeat("0", 50) + "Pris 5 €" + strings.Repeat(" ", 18) + "0"
+	converted := "82 " + strings.Repeat("0", 50) + "Pris 5 EUR" + strings.Repeat(" ", 16) + "0"
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		problem  string
+	}{
+		{"padding after", row + "\r\n" + row, converted + "\r\n" + converted, ""},
+		{"several on a line", "……    \r\n", "......\r\n", ""},
+		{"no padding", "82 Pris 5 €0\r\n", "", "line 1 2 characters longer"},
+		{"padding on the next line", "82 €\r\n   ", "", "line 1 2 characters longer"},
+		{"spaces between words", "Œuvre Nowak         |", "OEuvre Nowak        |", ""},
+		{"spaces between words after", "Pris 5€ per st      |", "Pris 5EUR per st    |", ""},
+		{"double space is padding", "Œuvre  Nowak", "OEuvre Nowak", ""},
+		{"only spaces between words", "Œuvre Nowak|", "", "line 1 1 characters longer"},
+		{"padding before the next field", "Pris 5€  |", "", "line 1 2 characters longer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := charset.Convert([]byte(tt.content), charset.Options{Transliterator: charset.DefaultTransliterator})
+			if tt.problem != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.problem) {
+					t.Errorf("Convert() error = %v, expected %q", err, tt.problem)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(result.Content) != tt.expected {
+				t.Errorf("Convert() = %q, expected %q", result.Content, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadTransliterator(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"valid", `{"€": "E", "ő": "oe"}`, ""},
+		{"not a single character", `{"ab": "x"}`, "not a single character"},
+		{"unrepresentable replacement", `{"€": "€"}`, "cannot be represented"},
+		{"invalid json", `{`, "invalid transliteration table"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "table.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			transliterator, err := charset.LoadTransliterator(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadTransliterator() error = %v, expected %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, _ := transliterator.String("€ ő ł"); got != "E oe l" {
+				t.Errorf("String() = %q", got)
+			}
+		})
+	}
+}
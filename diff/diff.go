@@ -0,0 +1,311 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Kind int
+
+const (
+	Equal Kind = iota
+	Delete
+	Insert
+)
+
+// A line kept, deleted from the first or inserted from the second input
+type Edit struct {
+	Kind Kind
+	Line string
+}
+
+// Calculate the shortest edit script between two sets of lines, using the linear space variant of the Myers algorithm
+// Lines equal at the start and end are matched first, so files with a few changes are fast
+// Within each run of changes the deleted lines come before the inserted ones
+func Lines(a []string, b []string) []Edit {
+	edits := appendEdits(make([]Edit, 0, max(len(a), len(b))), a, b)
+
+	for start := 0; start < len(edits); start++ {
+		if edits[start].Kind == Equal {
+			continue
+		}
+		end := start
+		for end < len(edits) && edits[end].Kind != Equal {
+			end++
+		}
+		sort.SliceStable(edits[start:end], func(i, j int) bool {
+			return edits[start+i].Kind == Delete && edits[start+j].Kind == Insert
+		})
+		start = end
+	}
+
+	return edits
+}
+
+// Append the edits between a and b, splitting them at the middle snake of the edit path
+func appendEdits(edits []Edit, a []string, b []string) []Edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		edits = append(edits, Edit{Equal, a[prefix]})
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			edits = append(edits, Edit{Insert, line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			edits = append(edits, Edit{Delete, line})
+		}
+	default:
+		// Without a common prefix and suffix at least two edits are needed, so both halves are smaller
+		x, y, u, v := middleSnake(a, b)
+		edits = appendEdits(edits, a[:x], b[:y])
+		for _, line := range a[x:u] {
+			edits = append(edits, Edit{Equal, line})
+		}
+		edits = appendEdits(edits, a[u:], b[v:])
+	}
+
+	for _, line := range common {
+		edits = append(edits, Edit{Equal, line})
+	}
+
+	return edits
+}
+
+// Find the middle snake of the shortest edit path, searching from both ends until the paths overlap
+// The snake runs from (x, y) to (u, v), only the furthest point on each diagonal is kept
+func middleSnake(a []string, b []string) (int, int, int, int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+
+	forward := make([]int, 2*limit+3)
+	backward := make([]int, 2*limit+3)
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			// The backward search has gone d-1 steps, on diagonal delta-k counted from the end
+			if reverse := delta - k; odd && reverse >= -(d-1) && reverse <= d-1 && x+backward[offset+reverse] >= n {
+				return startX, startY, x, y
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			if reverse := delta - k; !odd && reverse >= -d && reverse <= d && forward[offset+reverse]+x >= n {
+				return n - x, m - y, n - startX, m - startY
+			}
+		}
+	}
+
+	panic("diff: no middle snake found")
+}
+
+// Create a unified diff between two sets of lines with the given number of context lines
+// An empty string is returned when the lines are equal
+func Unified(fromName string, toName string, a []string, b []string, context int) string {
+	edits := Lines(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	changed := false
+	for start := 0; start < len(edits); {
+		// Find the next change
+		for start < len(edits) && edits[start].Kind == Equal {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		changed = true
+
+		// Extend the hunk until there are more than 2*context equal lines between changes
+		end := start
+		for i := start; i < len(edits); i++ {
+			if edits[i].Kind != Equal {
+				end = i + 1
+				continue
+			}
+			if i-end >= 2*context {
+				break
+			}
+		}
+
+		hunkStart := start - context
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		hunkEnd := end + context
+		if hunkEnd > len(edits) {
+			hunkEnd = len(edits)
+		}
+
+		writeHunk(&out, edits, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+
+	if !changed {
+		return ""
+	}
+
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, edits []Edit, start int, end int) {
+	// Line numbers of the first line in the hunk
+	aLine, bLine := 1, 1
+	for _, edit := range edits[:start] {
+		if edit.Kind != Insert {
+			aLine++
+		}
+		if edit.Kind != Delete {
+			bLine++
+		}
+	}
+
+	aCount, bCount := 0, 0
+	for _, edit := range edits[start:end] {
+		if edit.Kind != Insert {
+			aCount++
+		}
+		if edit.Kind != Delete {
+			bCount++
+		}
+	}
+
+	// Empty ranges refer to the line before the hunk
+	if aCount == 0 {
+		aLine--
+	}
+	if bCount == 0 {
+		bLine--
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+	for _, edit := range edits[start:end] {
+		switch edit.Kind {
+		case Equal:
+			out.WriteString(" ")
+		case Delete:
+			out.WriteString("-")
+		case Insert:
+			out.WriteString("+")
+		}
+		out.WriteString(edit.Line)
+		out.WriteString("\n")
+	}
+}
+
+// Split content into lines on LF, escaping bytes that are not printable ASCII
+// Carriage returns are kept as \r so line ending changes are visible
+func EscapedLines(content []byte) []string {
+	if len(content) == 0 {
+		return []string{}
+	}
+
+	lines := strings.Split(string(content), "\n")
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = Escape([]byte(line))
+	}
+
+	return escaped
+}
+
+// Split content into lines like EscapedLines, without the carriage returns ending the lines
+// The line ending is returned separately: CRLF, LF, mixed, or an empty string when there are no line breaks
+func LinesAndEnding(content []byte) ([]string, string) {
+	if len(content) == 0 {
+		return []string{}, ""
+	}
+
+	lines := strings.Split(string(content), "\n")
+	escaped := make([]string, len(lines))
+	crlf := 0
+	for i, line := range lines {
+		if i < len(lines)-1 && strings.HasSuffix(line, "\r") {
+			line = strings.TrimSuffix(line, "\r")
+			crlf++
+		}
+		escaped[i] = Escape([]byte(line))
+	}
+
+	switch breaks := len(lines) - 1; {
+	case breaks == 0:
+		return escaped, ""
+	case crlf == breaks:
+		return escaped, "CRLF"
+	case crlf == 0:
+		return escaped, "LF"
+	default:
+		return escaped, "mixed"
+	}
+}
+
+// Escape bytes that are not printable ASCII as \r, \n, \t or \xHH
+func Escape(b []byte) string {
+	var out strings.Builder
+	for _, c := range b {
+		switch {
+		case c == '\\':
+			out.WriteString(`\\`)
+		case c == '\r':
+			out.WriteString(`\r`)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\t':
+			out.WriteString(`\t`)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&out, `\x%02X`, c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.String()
+}
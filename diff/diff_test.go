@@ -0,0 +1,183 @@
+package diff_test
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/diff"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"", ""},
+		{"a b c", "a b c"},
+		{"", "a b"},
+		{"a b", ""},
+		{"a b c a b b a", "c b a b a c"},
+		{"a x c", "a y c"},
+		{"a b c d", "b c d e"},
+	}
+
+	for _, test := range tests {
+		a, b := strings.Fields(test.a), strings.Fields(test.b)
+		edits := diff.Lines(a, b)
+
+		// Applying the edits to a must give b
+		gotA, gotB := []string{}, []string{}
+		for _, edit := range edits {
+			if edit.Kind != diff.Insert {
+				gotA = append(gotA, edit.Line)
+			}
+			if edit.Kind != diff.Delete {
+				gotB = append(gotB, edit.Line)
+			}
+		}
+		if strings.Join(gotA, " ") != strings.Join(a, " ") || strings.Join(gotB, " ") != strings.Join(b, " ") {
+			t.Errorf("%q -> %q: edits do not reproduce the inputs: %v", test.a, test.b, edits)
+		}
+	}
+
+	// The example from the Myers paper has an edit distance of 5
+	changes := 0
+	for _, edit := range diff.Lines(strings.Fields("a b c a b b a"), strings.Fields("c b a b a c")) {
+		if edit.Kind != diff.Equal {
+			changes++
+		}
+	}
+	if changes != 5 {
+		t.Errorf("expected 5 changes, got %d", changes)
+	}
+}
+
+func TestLinesShortest(t *testing.T) {
+	// Compare the number of changes with the longest common subsequence of random inputs
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a, b := make([]string, random.Intn(12)), make([]string, random.Intn(12))
+		for j := range a {
+			a[j] = string(rune('a' + random.Intn(3)))
+		}
+		for j := range b {
+			b[j] = string(rune('a' + random.Intn(3)))
+		}
+
+		lcs := make([][]int, len(a)+1)
+		for x := range lcs {
+			lcs[x] = make([]int, len(b)+1)
+		}
+		for x := len(a) - 1; x >= 0; x-- {
+			for y := len(b) - 1; y >= 0; y-- {
+				if a[x] == b[y] {
+					lcs[x][y] = lcs[x+1][y+1] + 1
+				} else {
+					lcs[x][y] = max(lcs[x+1][y], lcs[x][y+1])
+				}
+			}
+		}
+
+		changes := 0
+		gotA, gotB := []string{}, []string{}
+		for _, edit := range diff.Lines(a, b) {
+			if edit.Kind != diff.Equal {
+				changes++
+			}
+			if edit.Kind != diff.Insert {
+				gotA = append(gotA, edit.Line)
+			}
+			if edit.Kind != diff.Delete {
+				gotB = append(gotB, edit.Line)
+			}
+		}
+		if changes != len(a)+len(b)-2*lcs[0][0] || strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("%q -> %q: %d changes, expected %d", a, b, changes, len(a)+len(b)-2*lcs[0][0])
+		}
+	}
+}
+
+func TestLinesMemory(t *testing.T) {
+	// Every line differs, as when the line endings of a file are changed
+	a, b := make([]string, 5000), make([]string, 5000)
+	for i := range a {
+		a[i] = fmt.Sprintf("82 payment %d", i)
+		b[i] = a[i] + `\r`
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	edits := diff.Lines(a, b)
+	runtime.ReadMemStats(&after)
+
+	if len(edits) != 10000 {
+		t.Errorf("expected 10000 edits, got %d", len(edits))
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 10<<20 {
+		t.Errorf("Lines() allocated %d bytes", allocated)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := strings.Fields("1 2 3 4 5 6 7 8 9 10 11 12")
+	b := strings.Fields("0 1 2 3 4 5 6 7 8 nine 10 11 12")
+
+	// The hunks are separated by more than 2*3 equal lines
+	expected := `--- a
++++ b
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -6,7 +7,7 @@
+ 6
+ 7
+ 8
+-9
++nine
+ 10
+ 11
+ 12
+`
+
+	got := diff.Unified("a", "b", a, b, 3)
+	if got != expected {
+		t.Errorf("unexpected diff:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	if diff.Unified("a", "b", a, a, 3) != "" {
+		t.Error("expected no diff for equal inputs")
+	}
+}
+
+func TestEscapedLines(t *testing.T) {
+	lines := diff.EscapedLines([]byte("ab\\c\r\n\xc4\t\xc3\x84\n"))
+	expected := []string{`ab\\c\r`, `\xC4\t\xC3\x84`, ""}
+
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+}
+
+func TestLinesAndEnding(t *testing.T) {
+	tests := []struct {
+		content string
+		lines   string
+		ending  string
+	}{
+		{"", "", ""},
+		{"a", "a", ""},
+		{"a\r\nb\r\n", "a|b|", "CRLF"},
+		{"a\nb\n", "a|b|", "LF"},
+		{"a\r\nb\nc\r", `a|b|c\r`, "mixed"},
+	}
+	for _, tt := range tests {
+		lines, ending := diff.LinesAndEnding([]byte(tt.content))
+		if strings.Join(lines, "|") != tt.lines || ending != tt.ending {
+			t.Errorf("LinesAndEnding(%q) = %q, %s, expected %q, %s", tt.content, lines, ending, tt.lines, tt.ending)
+		}
+	}
+}
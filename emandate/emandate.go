@@ -0,0 +1,254 @@
+package emandate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hoglandets-it/go-bankgiro/fixedwidth"
+	"github.com/hoglandets-it/go-bankgiro/parse"
+)
+
+// Record codes of an e-mandate section (AG-EMEDGIV) and of the mandate records in a submission file
+const (
+	TK_MANDATE     = "52"
+	TK_INFORMATION = "53"
+	TK_NAME        = "54"
+	TK_ADDRESS     = "55"
+	TK_POSTAL      = "56"
+	TK_APPROVAL    = "04"
+)
+
+// A mandate given by the payer in the internet bank, assembled from its TK52-TK56 records
+// Row: the row of the TK52 record in the section, the opening record being row 1
+// InformationCode: position 62 of the TK52 record, as given by the payer's bank
+// Information: the payer's message to the payee (TK53)
+// Text fields are converted to UTF-8. The tagged fields are read from the TK52 record as text and checked by Validate
+type Mandate struct {
+	Row             int    `json:"row"`
+	Bankgiro        string `json:"bankgiro" bg:"pos=3,len=10"`
+	PayerNumber     string `json:"payerNumber" bg:"pos=13,len=16"`
+	Clearing        string `json:"clearing" bg:"pos=29,len=4"`
+	Account         string `json:"account" bg:"pos=33,len=12"`
+	IdentityNumber  string `json:"identityNumber" bg:"pos=45,len=12"`
+	InformationCode string `json:"informationCode" bg:"pos=62,len=1"`
+	Information     string `json:"information"`
+	Name            string `json:"name"`
+	ExtraName       string `json:"extraName"`
+	Address         string `json:"address"`
+	ExtraAddress    string `json:"extraAddress"`
+	PostalCode      string `json:"postalCode"`
+	City            string `json:"city"`
+
+	// The bankgiro number of the opening record, the payee the mandate was given to
+	payee string
+	// The record codes read after the TK52 record
+	found map[string]bool
+}
+
+// The opening record (TK01) of an AG-EMEDGIV section
+type openingRecord struct {
+	Bankgiro string `bg:"pos=15,len=10"`
+}
+
+// The payer's message (TK53)
+type informationRecord struct {
+	Information string `bg:"pos=3,len=78"`
+}
+
+// The payer's name (TK54) or address (TK55)
+type nameRecord struct {
+	Line  string `bg:"pos=3,len=36"`
+	Extra string `bg:"pos=39,len=36"`
+}
+
+// The payer's postal code and city (TK56)
+type postalRecord struct {
+	PostalCode string `bg:"pos=3,len=5"`
+	City       string `bg:"pos=8,len=31"`
+}
+
+// Assemble the e-mandates of all AG-EMEDGIV sections in the file
+func ReadFile(file *parse.AutogiroFile) ([]Mandate, error) {
+	mandates := []Mandate{}
+	found := false
+
+	for i := range file.Sections {
+		sec := &file.Sections[i]
+		if sec.SectionType.Code != "ag-emedgiv" {
+			continue
+		}
+		found = true
+
+		secMandates, err := Read(sec)
+		if err != nil {
+			return nil, err
+		}
+		mandates = append(mandates, secMandates...)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no e-mandates (AG-EMEDGIV) found in the file")
+	}
+
+	return mandates, nil
+}
+
+// Assemble the e-mandates of an AG-EMEDGIV section, each starting with a TK52 record
+func Read(sec *parse.AutogiroSection) ([]Mandate, error) {
+	if sec.SectionType.Code != "ag-emedgiv" {
+		return nil, fmt.Errorf("section is a %s, not an e-mandate section", sec.SectionType.Name)
+	}
+	if len(sec.Rows) == 0 {
+		return nil, fmt.Errorf("the section is empty")
+	}
+
+	opening := openingRecord{}
+	if err := fixedwidth.Unmarshal(sec.Rows[0], &opening); err != nil {
+		return nil, fmt.Errorf("row 1: %w", err)
+	}
+	mandates := []Mandate{}
+
+	for i, line := range sec.Rows[1:] {
+		row := i + 2
+		if len(line) < 2 || line[0:2] == parse.SECTION_END_IBANK {
+			continue
+		}
+
+		tk := line[0:2]
+		if tk == TK_MANDATE {
+			m := Mandate{Row: row, payee: opening.Bankgiro, found: map[string]bool{}}
+			if err := fixedwidth.Unmarshal(line, &m); err != nil {
+				return nil, fmt.Errorf("row %d: %w", row, err)
+			}
+			mandates = append(mandates, m)
+			continue
+		}
+
+		if len(mandates) == 0 {
+			return nil, fmt.Errorf("row %d: TK%s record before the first TK52 record", row, tk)
+		}
+
+		m := &mandates[len(mandates)-1]
+		if m.found[tk] {
+			return nil, fmt.Errorf("row %d: second TK%s record of the mandate on row %d", row, tk, m.Row)
+		}
+		m.found[tk] = true
+
+		var err error
+		switch tk {
+		case TK_INFORMATION:
+			record := informationRecord{}
+			err = fixedwidth.Unmarshal(line, &record)
+			m.Information = record.Information
+		case TK_NAME:
+			record := nameRecord{}
+			err = fixedwidth.Unmarshal(line, &record)
+			m.Name, m.ExtraName = record.Line, record.Extra
+		case TK_ADDRESS:
+			record := nameRecord{}
+			err = fixedwidth.Unmarshal(line, &record)
+			m.Address, m.ExtraAddress = record.Line, record.Extra
+		case TK_POSTAL:
+			record := postalRecord{}
+			err = fixedwidth.Unmarshal(line, &record)
+			m.PostalCode, m.City = record.PostalCode, record.City
+		default:
+			return nil, fmt.Errorf("row %d: unexpected TK%s record", row, tk)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+	}
+
+	return mandates, nil
+}
+
+// Check the mandate, all problems found are returned joined into one error
+func (m *Mandate) Validate() error {
+	problems := []error{}
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if !digits(m.Bankgiro, 10) {
+		problem("bankgiro number %q is not 10 digits", m.Bankgiro)
+	} else if m.payee != "" && m.Bankgiro != m.payee {
+		problem("bankgiro number %s is not the payee's %s", m.Bankgiro, m.payee)
+	}
+	if !digits(m.PayerNumber, 16) || strings.Trim(m.PayerNumber, "0") == "" {
+		problem("payer number %q is not 16 digits", m.PayerNumber)
+	}
+	if !digits(m.Clearing, 4) {
+		problem("clearing number %q is not 4 digits", m.Clearing)
+	}
+	if !digits(m.Account, 12) || strings.Trim(m.Account, "0") == "" {
+		problem("account number %q is not 12 digits", m.Account)
+	}
+	if !digits(m.IdentityNumber, 12) {
+		problem("identity number %q is not 12 digits", m.IdentityNumber)
+	} else if !luhn(m.IdentityNumber[2:]) {
+		problem("identity number %s has an invalid check digit", m.IdentityNumber)
+	}
+
+	for _, tk := range []string{TK_NAME, TK_ADDRESS, TK_POSTAL} {
+		if m.found != nil && !m.found[tk] {
+			problem("the TK%s record is missing", tk)
+		}
+	}
+	if m.Name == "" {
+		problem("the payer's name is missing")
+	}
+	if !digits(m.PostalCode, 5) {
+		problem("postal code %q is not 5 digits", m.PostalCode)
+	}
+
+	return errors.Join(problems...)
+}
+
+// Check that s is n digits
+func digits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Check the last digit of a personal identity or organisation number, without century, with the Luhn algorithm
+func luhn(number string) bool {
+	sum := 0
+	for i, c := range number {
+		digit := int(c - '0')
+		if i%2 == len(number)%2 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+
+	return sum%10 == 0
+}
+
+// Get the TK04 record approving the mandate, to be sent in the next submission file
+func (m *Mandate) ApprovalRecord() string {
+	return m.record("")
+}
+
+// Get the TK04 record rejecting the mandate, to be sent in the next submission file
+func (m *Mandate) RejectionRecord() string {
+	return m.record("AV")
+}
+
+// Get a TK04 record for the mandate, the rejection code is AV or blank
+func (m *Mandate) record(rejection string) string {
+	return fmt.Sprintf("%s%010s%016s%04s%012s%012s%20s%-2s%2s", TK_APPROVAL, m.Bankgiro, m.PayerNumber, m.Clearing, m.Account, m.IdentityNumber, "", rejection, "")
+}
@@ -0,0 +1,98 @@
+package emandate_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/emandate"
+	"github.com/hoglandets-it/go-bankgiro/parse"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+)
+
+func readMandates(t *testing.T, replace ...string) []emandate.Mandate {
+	t.Helper()
+
+	content, err := os.ReadFile("../tests/normalization/medgivande-new.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isoContent, err := tools.BytesToIsoString(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	isoContent = strings.NewReplacer(replace...).Replace(isoContent)
+
+	agFile := &parse.AutogiroFile{}
+	if err := agFile.ParseFile(isoContent); err != nil {
+		t.Fatal(err)
+	}
+
+	mandates, err := emandate.ReadFile(agFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mandates
+}
+
+func TestRead(t *testing.T) {
+	mandates := readMandates(t)
+	if len(mandates) != 3 {
+		t.Fatalf("ReadFile() = %d mandates, expected 3", len(mandates))
+	}
+
+	m := mandates[0]
+	if m.Row != 2 || m.Bankgiro != "0009912346" || m.PayerNumber != "0000000000010133" || m.Clearing != "9918" || m.Account != "000000041014" || m.IdentityNumber != "194512121212" || m.InformationCode != "0" {
+		t.Errorf("mandate = %+v", m)
+	}
+	if m.Information != "JAG VILL BETALA MÅNADSVIS" || m.Name != "DORIS DEMOSSON" || m.ExtraName != "C/o DAVID DEMOSSON" || m.Address != "DEMOVÄGEN 1" || m.PostalCode != "10000" || m.City != "DEMOSTAD" {
+		t.Errorf("mandate = %+v", m)
+	}
+	if mandates[2].Row != 12 || mandates[2].Name != "KARL KARLSSON" || mandates[2].Information != "" {
+		t.Errorf("mandate = %+v", mandates[2])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// The identity number of the test file has an invalid check digit
+	valid := []string{"194512121212     0", "194512121213     0"}
+
+	tests := []struct {
+		name    string
+		replace []string
+		problem string
+	}{
+		{"valid", valid, ""},
+		{"check digit", []string{"", ""}, "invalid check digit"},
+		{"other payee", append([]string{"52000991234600000000000101339918", "52000991234700000000000101339918"}, valid...), "is not the payee's 0009912346"},
+		{"postal code", append([]string{"5610000DEMOSTAD", "56100 0DEMOSTAD"}, valid...), "postal code"},
+		{"missing name", append([]string{"54DORIS DEMOSSON                      C/o DAVID DEMOSSON                        \r\n", ""}, valid...), "TK54 record is missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := readMandates(t, tt.replace...)[0].Validate()
+			if tt.problem == "" && err != nil {
+				t.Errorf("Validate() = %v, expected no error", err)
+			}
+			if tt.problem != "" && (err == nil || !strings.Contains(err.Error(), tt.problem)) {
+				t.Errorf("Validate() = %v, expected %q", err, tt.problem)
+			}
+		})
+	}
+}
+
+func TestRecords(t *testing.T) {
+	m := readMandates(t)[0]
+
+	approval := "04000991234600000000000101339918000000041014194512121212" + strings.Repeat(" ", 24)
+	if m.ApprovalRecord() != approval {
+		t.Errorf("ApprovalRecord() = %q, expected %q", m.ApprovalRecord(), approval)
+	}
+
+	rejection := m.RejectionRecord()
+	if len(rejection) != 80 || rejection[:56] != approval[:56] || rejection[76:78] != "AV" {
+		t.Errorf("RejectionRecord() = %q", rejection)
+	}
+}
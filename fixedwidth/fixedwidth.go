@@ -0,0 +1,375 @@
+package fixedwidth
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/money"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+)
+
+// The width of a Bankgiro record, records are padded to it when encoded
+const RecordWidth = 80
+
+// Field types of the bg struct tag
+const (
+	TypeAlpha  = "alpha"
+	TypeNum    = "num"
+	TypeDate   = "date"
+	TypeAmount = "amount"
+)
+
+// The date of a payment made as soon as possible, used instead of a date in submission files
+const Immediately = "GENAST"
+
+// The layout of a field, read from a struct tag such as bg:"pos=3,len=10,type=num,pad=zero"
+// Pos: the first column, counted from 1 as in Bankgirot's manuals
+// Type: alpha (default), num, date or amount
+// Pad: space or zero, alpha and date fields are padded with spaces and num and amount fields with zeros by default
+// Value: a constant, e.g. the record code, checked when decoding and written when the field is empty
+// Options: other options, passed to fields implementing Unmarshaler and Marshaler
+type Tag struct {
+	Pos     int
+	Len     int
+	Type    string
+	Pad     string
+	Value   string
+	Options map[string]string
+}
+
+// Implemented by field types decoding themselves, e.g. codes looked up in a table named by an option
+type Unmarshaler interface {
+	UnmarshalFixed(field string, tag Tag) error
+}
+
+// Implemented by field types encoding themselves, the result must be tag.Len characters
+type Marshaler interface {
+	MarshalFixed(tag Tag) (string, error)
+}
+
+// A tagged struct field
+type field struct {
+	name  string
+	index int
+	tag   Tag
+}
+
+// The tagged fields of a struct type
+type layout struct {
+	fields []field
+	width  int
+}
+
+var layouts sync.Map
+
+var amountType = reflect.TypeOf(money.Amount(0))
+
+// Parse a bg struct tag
+func ParseTag(s string) (Tag, error) {
+	tag := Tag{Type: TypeAlpha, Options: map[string]string{}}
+
+	for _, option := range strings.Split(s, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+		var err error
+		switch key {
+		case "pos":
+			tag.Pos, err = strconv.Atoi(value)
+		case "len":
+			tag.Len, err = strconv.Atoi(value)
+		case "type":
+			tag.Type = value
+		case "pad":
+			tag.Pad = value
+		case "value":
+			tag.Value = value
+		default:
+			tag.Options[key] = value
+		}
+		if err != nil {
+			return Tag{}, fmt.Errorf("invalid %s %q", key, value)
+		}
+	}
+
+	if tag.Pos < 1 || tag.Len < 1 {
+		return Tag{}, fmt.Errorf("pos and len must be at least 1")
+	}
+	if tag.Type != TypeAlpha && tag.Type != TypeNum && tag.Type != TypeDate && tag.Type != TypeAmount {
+		return Tag{}, fmt.Errorf("unknown type %s", tag.Type)
+	}
+	if tag.Pad == "" {
+		tag.Pad = "space"
+		if tag.Type == TypeNum || tag.Type == TypeAmount {
+			tag.Pad = "zero"
+		}
+	}
+	if tag.Pad != "space" && tag.Pad != "zero" {
+		return Tag{}, fmt.Errorf("unknown padding %s", tag.Pad)
+	}
+	if tag.Value != "" && len(tag.Value) != tag.Len {
+		return Tag{}, fmt.Errorf("value %q is not %d characters", tag.Value, tag.Len)
+	}
+
+	return tag, nil
+}
+
+// Get the tagged fields of the struct type, checking that they don't overlap
+func layoutOf(t reflect.Type) (*layout, error) {
+	if cached, found := layouts.Load(t); found {
+		return cached.(*layout), nil
+	}
+
+	l := &layout{width: RecordWidth}
+	columns := map[int]string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		s, found := sf.Tag.Lookup("bg")
+		if !found || s == "-" {
+			continue
+		}
+
+		tag, err := ParseTag(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), sf.Name, err)
+		}
+		if !sf.IsExported() && !(sf.Name == "_" && tag.Value != "") {
+			return nil, fmt.Errorf("%s.%s: unexported fields must be named _ and have a value", t.Name(), sf.Name)
+		}
+
+		for column := tag.Pos; column < tag.Pos+tag.Len; column++ {
+			if other, found := columns[column]; found {
+				return nil, fmt.Errorf("%s.%s: column %d is also used by %s", t.Name(), sf.Name, column, other)
+			}
+			columns[column] = sf.Name
+		}
+
+		l.fields = append(l.fields, field{name: sf.Name, index: i, tag: tag})
+		l.width = max(l.width, tag.Pos+tag.Len-1)
+	}
+
+	layouts.Store(t, l)
+
+	return l, nil
+}
+
+// Get the struct value v points to
+func structValue(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("expected a struct or a pointer to one, got %T", v)
+	}
+
+	return rv, nil
+}
+
+// Decode the fields of an ISO-8859-1 record into the tagged fields of the struct v points to
+// Text is converted to UTF-8. Lines shorter than the record are padded with spaces
+// Dates are only checked to be digits, files from Bankgirot report invalid dates in rejected records
+func Unmarshal(line string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+
+	l, err := layoutOf(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	if len(line) < l.width {
+		line += strings.Repeat(" ", l.width-len(line))
+	}
+
+	for _, f := range l.fields {
+		raw := line[f.tag.Pos-1 : f.tag.Pos-1+f.tag.Len]
+		if err := decode(raw, rv.Field(f.index), f); err != nil {
+			return fmt.Errorf("%s at position %d-%d: %w", f.name, f.tag.Pos, f.tag.Pos+f.tag.Len-1, err)
+		}
+	}
+
+	return nil
+}
+
+func decode(raw string, fv reflect.Value, f field) error {
+	if f.tag.Value != "" && raw != f.tag.Value {
+		return fmt.Errorf("expected %q, got %q", f.tag.Value, raw)
+	}
+	if !fv.CanSet() {
+		return nil
+	}
+
+	if u, ok := fv.Addr().Interface().(Unmarshaler); ok {
+		return u.UnmarshalFixed(raw, f.tag)
+	}
+
+	trimmed := strings.TrimSpace(raw)
+
+	switch f.tag.Type {
+	case TypeAmount:
+		if fv.Type() != amountType {
+			return fmt.Errorf("amount fields must be money.Amount")
+		}
+		amount, err := money.ParseField(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(amount))
+		return nil
+	case TypeNum:
+		if !digits(trimmed) {
+			return fmt.Errorf("%q is not a number", trimmed)
+		}
+		if fv.Kind() == reflect.Int || fv.Kind() == reflect.Int64 || fv.Kind() == reflect.Int32 {
+			if trimmed == "" {
+				fv.SetInt(0)
+				return nil
+			}
+			n, err := strconv.ParseInt(trimmed, 10, 64)
+			if err != nil || fv.OverflowInt(n) {
+				return fmt.Errorf("%q is too large", trimmed)
+			}
+			fv.SetInt(n)
+			return nil
+		}
+	case TypeDate:
+		if strings.Trim(trimmed, "0") == "" {
+			trimmed = ""
+		}
+		if trimmed != Immediately && !digits(trimmed) {
+			return fmt.Errorf("%q is not a date", trimmed)
+		}
+	case TypeAlpha:
+		trimmed = charset.IsoToUtf8(trimmed)
+	}
+
+	if fv.Kind() != reflect.String {
+		return fmt.Errorf("%s fields must be strings, not %s", f.tag.Type, fv.Type())
+	}
+	fv.SetString(trimmed)
+
+	return nil
+}
+
+// Encode the tagged fields of the struct as an ISO-8859-1 record of at least 80 characters
+// Columns without a field are spaces. Text is transliterated with charset.DefaultTransliterator. Values not fitting their field,
+// non-numeric numbers, negative amounts and invalid dates are errors
+func Marshal(v any) (string, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return "", err
+	}
+
+	l, err := layoutOf(rv.Type())
+	if err != nil {
+		return "", err
+	}
+
+	line := []byte(strings.Repeat(" ", l.width))
+	for _, f := range l.fields {
+		encoded, err := encode(rv.Field(f.index), f)
+		if err != nil {
+			return "", fmt.Errorf("%s at position %d-%d: %w", f.name, f.tag.Pos, f.tag.Pos+f.tag.Len-1, err)
+		}
+		copy(line[f.tag.Pos-1:], encoded)
+	}
+
+	return string(line), nil
+}
+
+func encode(fv reflect.Value, f field) (string, error) {
+	if f.tag.Value != "" && (!fv.CanInterface() || fv.IsZero()) {
+		return f.tag.Value, nil
+	}
+
+	if m, ok := fv.Interface().(Marshaler); ok {
+		encoded, err := m.MarshalFixed(f.tag)
+		if err == nil && len(encoded) != f.tag.Len {
+			err = fmt.Errorf("encoded as %d characters, expected %d", len(encoded), f.tag.Len)
+		}
+		return encoded, err
+	}
+
+	var value string
+	switch {
+	case f.tag.Type == TypeAmount:
+		if fv.Type() != amountType {
+			return "", fmt.Errorf("amount fields must be money.Amount")
+		}
+		return fv.Interface().(money.Amount).Field(f.tag.Len)
+	case fv.Kind() == reflect.String:
+		value = fv.String()
+	case f.tag.Type == TypeNum && (fv.Kind() == reflect.Int || fv.Kind() == reflect.Int64 || fv.Kind() == reflect.Int32):
+		if fv.Int() < 0 {
+			return "", fmt.Errorf("%d is negative", fv.Int())
+		}
+		value = strconv.FormatInt(fv.Int(), 10)
+	default:
+		return "", fmt.Errorf("%s fields must be strings, not %s", f.tag.Type, fv.Type())
+	}
+
+	switch f.tag.Type {
+	case TypeNum:
+		if !digits(value) {
+			return "", fmt.Errorf("%q is not a number", value)
+		}
+	case TypeDate:
+		if value != "" && value != Immediately && !validDate(value) {
+			return "", fmt.Errorf("%q is not a date", value)
+		}
+	case TypeAlpha:
+		// Characters outside ISO-8859-1 are transliterated as when building a file, e.g. Ł to L
+		transliterated, _ := charset.DefaultTransliterator.String(value)
+		if !charset.Representable(transliterated) {
+			return "", fmt.Errorf("%q cannot be represented in ISO-8859-1", value)
+		}
+		value = tools.StringEnsureIso(transliterated)
+	}
+
+	// The length is checked after transliteration, a replacement may be longer than the character
+	if len(value) > f.tag.Len {
+		return "", fmt.Errorf("%q is longer than %d characters", value, f.tag.Len)
+	}
+
+	pad := strings.Repeat(" ", f.tag.Len-len(value))
+	if f.tag.Pad == "zero" {
+		pad = strings.Repeat("0", f.tag.Len-len(value))
+	}
+	if f.tag.Type == TypeNum || (f.tag.Type == TypeDate && value == "") {
+		return pad + value, nil
+	}
+
+	return value + pad, nil
+}
+
+// Check that s only has digits, an empty string is a blank field
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Check that the date is a valid YYYYMMDD or YYMMDD date
+func validDate(s string) bool {
+	layouts := map[int]string{8: "20060102", 6: "060102"}
+	if _, found := layouts[len(s)]; !found || !digits(s) {
+		return false
+	}
+
+	_, err := time.Parse(layouts[len(s)], s)
+
+	return err == nil
+}
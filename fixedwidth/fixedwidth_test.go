@@ -0,0 +1,198 @@
+package fixedwidth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/fixedwidth"
+	"github.com/hoglandets-it/go-bankgiro/money"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+)
+
+type payment struct {
+	Row       int
+	_         string       `bg:"pos=1,len=2,value=82"`
+	Date      string       `bg:"pos=3,len=8,type=date"`
+	Payer     string       `bg:"pos=16,len=16,type=num"`
+	Amount    money.Amount `bg:"pos=32,len=12,type=amount"`
+	Count     int          `bg:"pos=44,len=3,type=num"`
+	Reference string       `bg:"pos=54,len=16"`
+	Status    status       `bg:"pos=80,len=1,ok=0"`
+}
+
+// A status read from a one character code, the code named by the ok option means approved
+type status struct {
+	Approved bool
+}
+
+func (s *status) UnmarshalFixed(field string, tag fixedwidth.Tag) error {
+	s.Approved = field == tag.Options["ok"]
+	return nil
+}
+
+func (s status) MarshalFixed(tag fixedwidth.Tag) (string, error) {
+	if s.Approved {
+		return tag.Options["ok"], nil
+	}
+	return "1", nil
+}
+
+const paymentLine = "8220250102     0000000000000101000000012350012       Faktura åäö               1"
+
+func TestRoundTrip(t *testing.T) {
+	p := payment{}
+	if err := fixedwidth.Unmarshal(tools.StringEnsureIso(paymentLine), &p); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := payment{Date: "20250102", Payer: "0000000000000101", Amount: 12350, Count: 12, Reference: "Faktura åäö"}
+	if p != expected {
+		t.Errorf("Unmarshal() = %+v, expected %+v", p, expected)
+	}
+
+	line, err := fixedwidth.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != tools.StringEnsureIso(paymentLine) {
+		t.Errorf("Marshal() = %q", line)
+	}
+
+	p.Status.Approved = true
+	if line, _ := fixedwidth.Marshal(&p); len(line) != 80 || line[79] != '0' {
+		t.Errorf("Marshal() = %q, expected the ok option to be written", line)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		problem string
+	}{
+		{"record code", "83" + paymentLine[2:], `_ at position 1-2: expected "82"`},
+		{"number", paymentLine[:15] + "000000000000010A" + paymentLine[31:], "Payer at position 16-31"},
+		{"amount", paymentLine[:31] + "0000000123-0" + paymentLine[43:], "Amount at position 32-43"},
+		{"date", "82202501XX" + paymentLine[10:], "Date at position 3-10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fixedwidth.Unmarshal(tt.line, &payment{})
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("Unmarshal() = %v, expected %q", err, tt.problem)
+			}
+		})
+	}
+
+	if err := fixedwidth.Unmarshal(paymentLine, payment{}); err == nil {
+		t.Errorf("Unmarshal() of a struct value succeeded, expected a pointer to be required")
+	}
+}
+
+func TestUnmarshalShortLine(t *testing.T) {
+	p := payment{}
+	if err := fixedwidth.Unmarshal("8200000000", &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Date != "" || p.Payer != "" || p.Amount != 0 || p.Reference != "" {
+		t.Errorf("Unmarshal() = %+v, expected blank fields", p)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		payment payment
+		problem string
+	}{
+		{"invalid date", payment{Date: "20250230"}, "Date at position 3-10"},
+		{"number", payment{Payer: "12A"}, "Payer at position 16-31"},
+		{"too long", payment{Payer: "12345678901234567"}, "longer than 16"},
+		{"negative amount", payment{Amount: -1}, "negative"},
+		{"amount too large", payment{Amount: money.Kronor(10_000_000_000)}, "does not fit"},
+		{"negative count", payment{Count: -1}, "Count at position 44-46"},
+		{"count too large", payment{Count: 1000}, "longer than 3"},
+		{"transliterated too long", payment{Reference: "Faktura 12345 €"}, `"Faktura 12345 EUR" is longer than 16`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fixedwidth.Marshal(tt.payment)
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("Marshal() = %v, expected %q", err, tt.problem)
+			}
+		})
+	}
+
+	if line, err := fixedwidth.Marshal(payment{Date: fixedwidth.Immediately}); err != nil || line[2:10] != "GENAST  " {
+		t.Errorf("Marshal() = %q, %v, expected GENAST to be accepted as a date", line, err)
+	}
+}
+
+func TestLayoutErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		problem string
+	}{
+		{"overlap", &struct {
+			A string `bg:"pos=1,len=5"`
+			B string `bg:"pos=5,len=2"`
+		}{}, "column 5 is also used by A"},
+		{"missing len", &struct {
+			A string `bg:"pos=1"`
+		}{}, "pos and len"},
+		{"unknown type", &struct {
+			A string `bg:"pos=1,len=2,type=text"`
+		}{}, "unknown type text"},
+		{"value length", &struct {
+			A string `bg:"pos=1,len=2,value=1"`
+		}{}, "is not 2 characters"},
+		{"amount type", &struct {
+			A int64 `bg:"pos=1,len=2,type=amount"`
+		}{}, "money.Amount"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fixedwidth.Unmarshal("", tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("Unmarshal() = %v, expected %q", err, tt.problem)
+			}
+		})
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	tag, err := fixedwidth.ParseTag("pos=3,len=10,type=num,pad=space,table=codes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.Pos != 3 || tag.Len != 10 || tag.Type != fixedwidth.TypeNum || tag.Pad != "space" || tag.Options["table"] != "codes" {
+		t.Errorf("ParseTag() = %+v", tag)
+	}
+
+	tag, _ = fixedwidth.ParseTag("pos=1,len=8,type=date")
+	if tag.Pad != "space" {
+		t.Errorf("ParseTag() pad = %s, expected dates to be padded with spaces", tag.Pad)
+	}
+}
+
+func TestMarshalTransliterate(t *testing.T) {
+	type name struct {
+		Name string `bg:"pos=3,len=10"`
+	}
+
+	line, err := fixedwidth.Marshal(name{"Łukasz Ñ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line[2:12] != tools.StringEnsureIso("Lukasz Ñ  ") {
+		t.Errorf("Marshal() = %q, expected the name to be transliterated", line)
+	}
+
+	if _, err := fixedwidth.Marshal(name{"Łukasz Wąs"}); err != nil {
+		t.Errorf("Marshal() error = %v", err)
+	}
+	if _, err := fixedwidth.Marshal(name{"Łukasz Wąsik"}); err == nil {
+		t.Error("Marshal() of a name longer than the field succeeded")
+	}
+}
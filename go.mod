@@ -2,10 +2,25 @@ module github.com/hoglandets-it/go-bankgiro
 
 go 1.22
 
+require (
+	filippo.io/age v1.2.1
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/miekg/pkcs11 v1.1.1
+	github.com/pkg/sftp v1.13.6
+	github.com/urfave/cli/v2 v2.27.2
+	golang.org/x/crypto v0.24.0
+	golang.org/x/sys v0.21.0
+	golang.org/x/term v0.21.0
+	golang.org/x/text v0.16.0
+	google.golang.org/grpc v1.66.0
+	google.golang.org/protobuf v1.35.1
+)
+
 require (
 	github.com/cpuguy83/go-md2man/v2 v2.0.4 // indirect
+	github.com/kr/fs v0.1.0 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
-	github.com/urfave/cli/v2 v2.27.2 // indirect
 	github.com/xrash/smetrics v0.0.0-20240312152122-5f08fbb34913 // indirect
-	golang.org/x/text v0.14.0 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240604185151-ef581f913117 // indirect
 )
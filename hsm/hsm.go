@@ -0,0 +1,14 @@
+// Package hsm provides seal.Signer implementations that keep the seal key inside a hardware security module
+package hsm
+
+// Settings used to locate the seal key in a PKCS#11 token
+// Module: path to the PKCS#11 library, e.g. /usr/lib/softhsm/libsofthsm2.so
+// TokenLabel: label of the token holding the key
+// Pin: user PIN of the token
+// KeyLabel: CKA_LABEL of the generic secret key used for HMAC-SHA256
+type Pkcs11Config struct {
+	Module     string
+	TokenLabel string
+	Pin        string
+	KeyLabel   string
+}
@@ -0,0 +1,202 @@
+//go:build cgo
+
+package hsm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"github.com/miekg/pkcs11"
+)
+
+// A seal.Signer calculating the HMAC-SHA256 seal inside a PKCS#11 token
+// The key never leaves the token, only the data and the resulting MAC pass through process memory
+type Pkcs11Signer struct {
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	key     pkcs11.ObjectHandle
+	mu      sync.Mutex
+}
+
+// Load the PKCS#11 module, log in to the token and locate the seal key
+func OpenPkcs11(config Pkcs11Config) (*Pkcs11Signer, error) {
+	ctx, session, err := openSession(config)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := &Pkcs11Signer{ctx: ctx, session: session}
+
+	signer.key, err = findKey(ctx, session, config.KeyLabel)
+	if err != nil {
+		signer.Close()
+		return nil, err
+	}
+
+	return signer, nil
+}
+
+// Import a raw HMAC key into the token as a non-extractable generic secret
+// Used to provision a token, e.g. with a key combined from its components
+func ImportPkcs11Key(config Pkcs11Config, key []byte) error {
+	ctx, session, err := openSession(config)
+	if err != nil {
+		return err
+	}
+	defer closeSession(ctx, session)
+
+	if _, err := findKey(ctx, session, config.KeyLabel); err == nil {
+		return fmt.Errorf("key %q already exists in token %q", config.KeyLabel, config.TokenLabel)
+	}
+
+	_, err = ctx.CreateObject(session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_GENERIC_SECRET),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, config.KeyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, key),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+	})
+	if err != nil {
+		return fmt.Errorf("could not import key %q: %w", config.KeyLabel, err)
+	}
+
+	return nil
+}
+
+// Calculate the HMAC-SHA256 of the data inside the token
+func (s *Pkcs11Signer) ComputeMAC(data []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx == nil {
+		return nil, fmt.Errorf("pkcs11 signer is closed")
+	}
+
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_SHA256_HMAC, nil)}
+	if err := s.ctx.SignInit(s.session, mechanism, s.key); err != nil {
+		return nil, fmt.Errorf("pkcs11 sign init failed: %w", err)
+	}
+
+	mac, err := s.ctx.Sign(s.session, data)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11 sign failed: %w", err)
+	}
+
+	return mac, nil
+}
+
+// Calculate the KVV inside the token
+func (s *Pkcs11Signer) ComputeKVV() ([]byte, error) {
+	return s.ComputeMAC([]byte(seal.KvvCalcValue))
+}
+
+// Log out, close the session and unload the module
+func (s *Pkcs11Signer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx == nil {
+		return nil
+	}
+
+	closeSession(s.ctx, s.session)
+	s.ctx = nil
+
+	return nil
+}
+
+func openSession(config Pkcs11Config) (*pkcs11.Ctx, pkcs11.SessionHandle, error) {
+	ctx := pkcs11.New(config.Module)
+	if ctx == nil {
+		return nil, 0, fmt.Errorf("could not load pkcs11 module %s", config.Module)
+	}
+
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, 0, fmt.Errorf("could not initialize pkcs11 module %s: %w", config.Module, err)
+	}
+
+	slot, err := findSlot(ctx, config.TokenLabel)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, 0, err
+	}
+
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, 0, fmt.Errorf("could not open pkcs11 session: %w", err)
+	}
+
+	if err := ctx.Login(session, pkcs11.CKU_USER, config.Pin); err != nil {
+		ctx.CloseSession(session)
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, 0, fmt.Errorf("could not log in to token %q: %w", config.TokenLabel, err)
+	}
+
+	return ctx, session, nil
+}
+
+func closeSession(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) {
+	ctx.Logout(session)
+	ctx.CloseSession(session)
+	ctx.Finalize()
+	ctx.Destroy()
+}
+
+func findSlot(ctx *pkcs11.Ctx, tokenLabel string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("could not list pkcs11 slots: %w", err)
+	}
+
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+
+		if strings.TrimRight(info.Label, " \x00") == tokenLabel {
+			return slot, nil
+		}
+	}
+
+	return 0, fmt.Errorf("token %q not found", tokenLabel)
+}
+
+func findKey(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, keyLabel string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyLabel),
+	}
+
+	if err := ctx.FindObjectsInit(session, template); err != nil {
+		return 0, fmt.Errorf("could not search for key %q: %w", keyLabel, err)
+	}
+	defer ctx.FindObjectsFinal(session)
+
+	objects, _, err := ctx.FindObjects(session, 2)
+	if err != nil {
+		return 0, fmt.Errorf("could not search for key %q: %w", keyLabel, err)
+	}
+
+	if len(objects) == 0 {
+		return 0, fmt.Errorf("key %q not found", keyLabel)
+	}
+
+	if len(objects) > 1 {
+		return 0, fmt.Errorf("multiple keys labelled %q found", keyLabel)
+	}
+
+	return objects[0], nil
+}
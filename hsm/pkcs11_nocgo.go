@@ -0,0 +1,28 @@
+//go:build !cgo
+
+package hsm
+
+import "fmt"
+
+// PKCS#11 requires cgo to load the module, this build only reports the missing support
+type Pkcs11Signer struct{}
+
+func OpenPkcs11(config Pkcs11Config) (*Pkcs11Signer, error) {
+	return nil, fmt.Errorf("pkcs11 support requires a build with cgo enabled")
+}
+
+func ImportPkcs11Key(config Pkcs11Config, key []byte) error {
+	return fmt.Errorf("pkcs11 support requires a build with cgo enabled")
+}
+
+func (s *Pkcs11Signer) ComputeMAC(data []byte) ([]byte, error) {
+	return nil, fmt.Errorf("pkcs11 support requires a build with cgo enabled")
+}
+
+func (s *Pkcs11Signer) ComputeKVV() ([]byte, error) {
+	return nil, fmt.Errorf("pkcs11 support requires a build with cgo enabled")
+}
+
+func (s *Pkcs11Signer) Close() error {
+	return nil
+}
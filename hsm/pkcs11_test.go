@@ -0,0 +1,143 @@
+//go:build cgo
+
+package hsm_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/hsm"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"github.com/miekg/pkcs11"
+)
+
+// Run with SOFTHSM2_MODULE=/usr/lib/softhsm/libsofthsm2.so to test against SoftHSM
+const (
+	TestKey    = "1234567890ABCDEF1234567890ABCDEF"
+	TestKeyKvv = "FF365893D899291C3BF505FB3175E880"
+	TestPin    = "1234"
+	TestSoPin  = "12345678"
+)
+
+// Create a fresh SoftHSM token in a temporary directory
+func softHsmConfig(t *testing.T) hsm.Pkcs11Config {
+	module := os.Getenv("SOFTHSM2_MODULE")
+	if module == "" {
+		t.Skip("SOFTHSM2_MODULE not set, skipping SoftHSM tests")
+	}
+
+	dir := t.TempDir()
+	tokenDir := filepath.Join(dir, "tokens")
+	if err := os.Mkdir(tokenDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := filepath.Join(dir, "softhsm2.conf")
+	err := os.WriteFile(conf, []byte(fmt.Sprintf("directories.tokendir = %s\nobjectstore.backend = file\n", tokenDir)), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SOFTHSM2_CONF", conf)
+
+	ctx := pkcs11.New(module)
+	if ctx == nil {
+		t.Fatalf("could not load %s", module)
+	}
+	defer ctx.Destroy()
+
+	if err := ctx.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Finalize()
+
+	slots, err := ctx.GetSlotList(false)
+	if err != nil || len(slots) == 0 {
+		t.Fatalf("no slots available: %v", err)
+	}
+
+	if err := ctx.InitToken(slots[0], TestSoPin, "bankgiro"); err != nil {
+		t.Fatal(err)
+	}
+
+	// SoftHSM moves the initialized token to a new slot
+	slots, err = ctx.GetSlotList(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := ctx.OpenSession(slots[0], pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.CloseSession(session)
+
+	if err := ctx.Login(session, pkcs11.CKU_SO, TestSoPin); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.InitPIN(session, TestPin); err != nil {
+		t.Fatal(err)
+	}
+	ctx.Logout(session)
+
+	return hsm.Pkcs11Config{
+		Module:     module,
+		TokenLabel: "bankgiro",
+		Pin:        TestPin,
+		KeyLabel:   "seal-key",
+	}
+}
+
+func TestPkcs11SignerMatchesSoftware(t *testing.T) {
+	config := softHsmConfig(t)
+
+	key, err := hex.DecodeString(TestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := hsm.ImportPkcs11Key(config, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := hsm.ImportPkcs11Key(config, key); err == nil {
+		t.Error("expected error when importing a duplicate key label")
+	}
+
+	signer, err := hsm.OpenPkcs11(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+
+	software := &seal.SoftwareSigner{Key: key}
+	data := seal.NormalizeContentString("0120240416AUTOGIRO\r\n82202404220    000000000020790200000008000000099252560040106553200145")
+
+	got, err := signer.ComputeMAC(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := software.ComputeMAC(data)
+	if !bytes.Equal(got, want) {
+		t.Errorf("ComputeMAC() = %X, want %X", got, want)
+	}
+
+	sealer := seal.HmacSealer{}
+	if err := sealer.SetSigner(signer); err != nil {
+		t.Fatal(err)
+	}
+	if err := sealer.CheckKvv(TestKeyKvv); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPkcs11MissingKey(t *testing.T) {
+	config := softHsmConfig(t)
+	config.KeyLabel = "does-not-exist"
+
+	if _, err := hsm.OpenPkcs11(config); err == nil {
+		t.Error("expected error when the key label does not exist")
+	}
+}
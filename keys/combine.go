@@ -0,0 +1,134 @@
+package keys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hoglandets-it/go-bankgiro/seal"
+)
+
+// A key component as delivered by Bankgiro
+// Value is the hex encoded component, CheckValue the KVV of the component used as a key
+type Component struct {
+	Value      []byte
+	CheckValue string
+}
+
+// Read a component from a reader
+// The first key line is the component, the optional second key line its check value
+func ReadComponent(r io.Reader) (Component, error) {
+	buf, err := FromReaderLines(r, 2)
+	if err != nil {
+		return Component{}, err
+	}
+
+	component := Component{Value: buf[0]}
+	if len(buf) > 1 {
+		component.CheckValue = string(buf[1])
+		Zero(buf[1])
+	}
+
+	return component, nil
+}
+
+// Verify the check value of a component
+// Components without a check value are not verified
+func (c *Component) Verify() error {
+	if c.CheckValue == "" {
+		return nil
+	}
+
+	sealer := seal.HmacSealer{}
+	defer sealer.ClearKey()
+
+	if err := sealer.SetKeyBytes(c.Value); err != nil {
+		return err
+	}
+
+	return sealer.CheckKvv(c.CheckValue)
+}
+
+// Combine key components by XOR and verify the result against the KVV
+// Each component check value is verified before combining
+// The returned key is hex encoded and should be zeroed when no longer needed
+func CombineComponents(components []Component, kvv string) ([]byte, error) {
+	if len(components) < 2 {
+		return nil, fmt.Errorf("at least two key components are required, got %d", len(components))
+	}
+
+	if kvv == "" {
+		return nil, fmt.Errorf("kvv is required to verify the combined key")
+	}
+
+	combined := make([]byte, 16)
+	defer Zero(combined)
+
+	raw := make([]byte, 16)
+	defer Zero(raw)
+
+	for i := range components {
+		if err := components[i].Verify(); err != nil {
+			return nil, fmt.Errorf("component %d: %w", i+1, err)
+		}
+
+		if hex.DecodedLen(len(components[i].Value)) != 16 {
+			return nil, fmt.Errorf("component %d: invalid length %d, expected 32 hex characters", i+1, len(components[i].Value))
+		}
+
+		if _, err := hex.Decode(raw, components[i].Value); err != nil {
+			return nil, fmt.Errorf("component %d: %w", i+1, err)
+		}
+
+		for j := range combined {
+			combined[j] ^= raw[j]
+		}
+	}
+
+	key := make([]byte, hex.EncodedLen(len(combined)))
+	hex.Encode(key, combined)
+	for i, b := range key {
+		if b >= 'a' && b <= 'f' {
+			key[i] = b - 'a' + 'A'
+		}
+	}
+
+	sealer := seal.HmacSealer{}
+	defer sealer.ClearKey()
+
+	if err := sealer.SetKeyBytes(key); err != nil {
+		Zero(key)
+		return nil, err
+	}
+
+	if err := sealer.CheckKvv(kvv); err != nil {
+		Zero(key)
+		return nil, fmt.Errorf("combined key: %w", err)
+	}
+
+	return key, nil
+}
+
+// Write a key to a new file only readable by its owner
+// The KVV is written as a comment above the key, an existing file is never overwritten
+func WriteKeyFile(path string, key []byte, kvv string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	content := make([]byte, 0, len(key)+64)
+	content = append(content, fmt.Sprintf("# KVV %s\n", kvv)...)
+	content = append(content, key...)
+	content = append(content, '\n')
+	defer Zero(content)
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+
+	return f.Close()
+}
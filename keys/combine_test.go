@@ -0,0 +1,138 @@
+package keys_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/keys"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+)
+
+const (
+	TestKeyKvv       = "FF365893D899291C3BF505FB3175E880"
+	TestComponentOne = "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"
+	TestComponentTwo = "EDCBA9876F543210EDCBA9876F543210"
+)
+
+func checkValue(t *testing.T, component string) string {
+	sealer := seal.HmacSealer{}
+	if err := sealer.SetKey(component); err != nil {
+		t.Fatal(err)
+	}
+
+	return sealer.GetKvvBgFormat()
+}
+
+func TestCombineComponents(t *testing.T) {
+	checkOne := checkValue(t, TestComponentOne)
+	checkTwo := checkValue(t, TestComponentTwo)
+
+	tests := []struct {
+		name       string
+		components []keys.Component
+		kvv        string
+		wantErr    bool
+	}{
+		{
+			name: "Valid Components",
+			components: []keys.Component{
+				{Value: []byte(TestComponentOne), CheckValue: checkOne},
+				{Value: []byte(TestComponentTwo), CheckValue: checkTwo},
+			},
+			kvv: TestKeyKvv,
+		},
+		{
+			name: "Lowercase Without Check Values",
+			components: []keys.Component{
+				{Value: []byte(strings.ToLower(TestComponentOne))},
+				{Value: []byte(strings.ToLower(TestComponentTwo))},
+			},
+			kvv: strings.ToLower(TestKeyKvv),
+		},
+		{
+			name: "Wrong Component Check Value",
+			components: []keys.Component{
+				{Value: []byte(TestComponentOne), CheckValue: checkTwo},
+				{Value: []byte(TestComponentTwo), CheckValue: checkTwo},
+			},
+			kvv:     TestKeyKvv,
+			wantErr: true,
+		},
+		{
+			name: "Wrong KVV",
+			components: []keys.Component{
+				{Value: []byte(TestComponentOne), CheckValue: checkOne},
+				{Value: []byte(TestComponentOne), CheckValue: checkOne},
+			},
+			kvv:     TestKeyKvv,
+			wantErr: true,
+		},
+		{
+			name: "Single Component",
+			components: []keys.Component{
+				{Value: []byte(TestKey)},
+			},
+			kvv:     TestKeyKvv,
+			wantErr: true,
+		},
+		{
+			name: "Short Component",
+			components: []keys.Component{
+				{Value: []byte(TestComponentOne[:30])},
+				{Value: []byte(TestComponentTwo)},
+			},
+			kvv:     TestKeyKvv,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keys.CombineComponents(tt.components, tt.kvv)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CombineComponents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != TestKey {
+				t.Errorf("CombineComponents() = %s, want %s", got, TestKey)
+			}
+		})
+	}
+}
+
+func TestWriteKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seal.key")
+
+	if err := keys.WriteKeyFile(path, []byte(TestKey), TestKeyKvv); err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.WriteKeyFile(path, []byte(TestKey), TestKeyKvv); err == nil {
+		t.Error("expected error when the key file already exists")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file permissions = %04o, want 0600", info.Mode().Perm())
+	}
+
+	key, err := keys.FromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key) != TestKey {
+		t.Errorf("FromFile() = %s, want %s", key, TestKey)
+	}
+}
+
+func TestReadComponent(t *testing.T) {
+	component, err := keys.ReadComponent(strings.NewReader("# component 1\n" + TestComponentOne + "\n" + TestKeyKvv + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(component.Value) != TestComponentOne || component.CheckValue != TestKeyKvv {
+		t.Errorf("ReadComponent() = %s, %s", component.Value, component.CheckValue)
+	}
+}
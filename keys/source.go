@@ -0,0 +1,142 @@
+package keys
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// The maximum amount of bytes read from a key source
+// A hex encoded 128-bit key is 32 characters, the limit leaves room for line endings and comments
+const MaxKeySourceSize = 4096
+
+// Overwrite the contents of a key buffer with zeroes
+func Zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// Ensure the key file is only accessible by its owner
+// Permission bits are not enforced on Windows, where ACLs are used instead
+func CheckPermissions(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("key file %s is a directory", path)
+	}
+
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Errorf("key file %s has too open permissions %04o, expected 0600 or stricter", path, perm)
+	}
+
+	return nil
+}
+
+// Read a key from a file, the file must not be readable by group or others
+func FromFile(path string) ([]byte, error) {
+	if err := CheckPermissions(path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return FromReader(f)
+}
+
+// Read a key from a reader, such as stdin
+// Only the first non-empty line not starting with # is used
+func FromReader(r io.Reader) ([]byte, error) {
+	lines, err := FromReaderLines(r, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return lines[0], nil
+}
+
+// Read up to max key lines from a reader, skipping empty lines and lines starting with #
+// At least one line must be present, all returned lines should be zeroed after use
+func FromReaderLines(r io.Reader, max int) ([][]byte, error) {
+	buf := make([]byte, MaxKeySourceSize+1)
+	defer Zero(buf)
+
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	if n > MaxKeySourceSize {
+		return nil, fmt.Errorf("key source exceeds %d bytes", MaxKeySourceSize)
+	}
+
+	lines := keyLines(buf[:n], max)
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no key found in key source")
+	}
+
+	out := make([][]byte, len(lines))
+	for i, line := range lines {
+		out[i] = make([]byte, len(line))
+		copy(out[i], line)
+	}
+
+	return out, nil
+}
+
+// Run an external command and read the key from its output
+// The command is run through the system shell, stderr is passed through to allow prompts
+func FromCommand(command string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	defer Zero(output)
+	if err != nil {
+		return nil, fmt.Errorf("key command failed: %w", err)
+	}
+
+	return FromReader(bytes.NewReader(output))
+}
+
+// Find the key lines without copying, so the source buffer can be zeroed afterwards
+func keyLines(b []byte, max int) [][]byte {
+	lines := [][]byte{}
+	for len(b) > 0 && len(lines) < max {
+		line := b
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			line, b = b[:i], b[i+1:]
+		} else {
+			b = nil
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
@@ -0,0 +1,118 @@
+package keys_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/keys"
+)
+
+const TestKey = "1234567890ABCDEF1234567890ABCDEF"
+
+func TestFromFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on windows")
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		perm    os.FileMode
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Owner Only",
+			content: TestKey + "\n",
+			perm:    0600,
+			want:    TestKey,
+		},
+		{
+			name:    "Read Only",
+			content: TestKey,
+			perm:    0400,
+			want:    TestKey,
+		},
+		{
+			name:    "Comments and Blank Lines",
+			content: "# seal key 2024\n\n  " + TestKey + "  \r\n",
+			perm:    0600,
+			want:    TestKey,
+		},
+		{
+			name:    "Group Readable",
+			content: TestKey,
+			perm:    0640,
+			wantErr: true,
+		},
+		{
+			name:    "World Readable",
+			content: TestKey,
+			perm:    0644,
+			wantErr: true,
+		},
+		{
+			name:    "Empty",
+			content: "# no key here\n",
+			perm:    0600,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "seal.key")
+			if err := os.WriteFile(path, []byte(tt.content), tt.perm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(path, tt.perm); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := keys.FromFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("FromFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromReaderTooLarge(t *testing.T) {
+	_, err := keys.FromReader(strings.NewReader(strings.Repeat("A", keys.MaxKeySourceSize+1)))
+	if err == nil {
+		t.Error("expected error for oversized key source")
+	}
+}
+
+func TestFromCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test command requires a posix shell")
+	}
+
+	got, err := keys.FromCommand("echo " + TestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != TestKey {
+		t.Errorf("FromCommand() = %q, want %q", got, TestKey)
+	}
+
+	if _, err := keys.FromCommand("exit 3"); err == nil {
+		t.Error("expected error for failing key command")
+	}
+}
+
+func TestZero(t *testing.T) {
+	key := []byte(TestKey)
+	keys.Zero(key)
+	for i, b := range key {
+		if b != 0 {
+			t.Fatalf("byte %d not zeroed", i)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+)
+
+// The date format used for key validity dates
+const DateFormat = "2006-01-02"
+
+// A named seal key held in the key store
+// Key is the hex encoded HMAC key, as accepted by seal.HmacSealer.SetKeyBytes
+// Kvv is the 32 character KVV of the key, calculated when the key is added
+// ValidFrom/ValidTo limit the dates the key is used on, empty means no limit
+// CustomerNumber/Bankgiro are matched against the TK01 opening record when selecting a key
+type Entry struct {
+	Name           string `json:"name"`
+	Key            []byte `json:"key"`
+	Kvv            string `json:"kvv"`
+	ValidFrom      string `json:"validFrom,omitempty"`
+	ValidTo        string `json:"validTo,omitempty"`
+	CustomerNumber string `json:"customerNumber,omitempty"`
+	Bankgiro       string `json:"bankgiro,omitempty"`
+	Added          string `json:"added,omitempty"`
+}
+
+// Calculate the KVV of the key and validate the key and dates
+func (e *Entry) Validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("key name is required")
+	}
+
+	sealer := seal.HmacSealer{}
+	err := sealer.SetKeyBytes(e.Key)
+	sealer.ClearKey()
+	if err != nil {
+		return fmt.Errorf("key %q: %w", e.Name, err)
+	}
+	e.Kvv = sealer.GetKvvBgFormat()
+
+	for _, date := range []string{e.ValidFrom, e.ValidTo} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(DateFormat, date); err != nil {
+			return fmt.Errorf("key %q: invalid date %q, expected YYYY-MM-DD", e.Name, date)
+		}
+	}
+
+	if e.ValidFrom != "" && e.ValidTo != "" && e.ValidTo < e.ValidFrom {
+		return fmt.Errorf("key %q: valid to %s is before valid from %s", e.Name, e.ValidTo, e.ValidFrom)
+	}
+
+	return nil
+}
+
+// Check if the key is valid on the given date
+func (e *Entry) ValidOn(date time.Time) bool {
+	day := date.Format(DateFormat)
+
+	return (e.ValidFrom == "" || e.ValidFrom <= day) && (e.ValidTo == "" || e.ValidTo >= day)
+}
+
+// Get a human readable status of the key on the given date: active, expired or pending
+func (e *Entry) Status(date time.Time) string {
+	day := date.Format(DateFormat)
+
+	switch {
+	case e.ValidTo != "" && e.ValidTo < day:
+		return "expired"
+	case e.ValidFrom != "" && e.ValidFrom > day:
+		return "pending"
+	}
+
+	return "active"
+}
+
+// An encrypted, file-backed store of named seal keys
+// The file is an age encrypted JSON document, the decrypted content is only kept in memory
+type Store struct {
+	Path string  `json:"-"`
+	Keys []Entry `json:"keys"`
+}
+
+// Create an identity/recipient pair for a passphrase protected store
+func PassphraseIdentity(passphrase string) (age.Identity, age.Recipient, error) {
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return identity, recipient, nil
+}
+
+// Read an age X25519 identity file, as created by age-keygen
+// The file must only be readable by its owner
+func IdentityFile(path string) (age.Identity, age.Recipient, error) {
+	content, err := FromFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer Zero(content)
+
+	identity, err := age.ParseX25519Identity(string(content))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid identity file %s: %w", path, err)
+	}
+
+	return identity, identity.Recipient(), nil
+}
+
+// Open and decrypt the key store at path
+// A store that does not exist yet is returned empty
+func OpenStore(path string, identity age.Identity) (*Store, error) {
+	store := &Store{Path: path}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := CheckPermissions(path); err != nil {
+		return nil, err
+	}
+
+	r, err := age.Decrypt(f, identity)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt key store %s: %w", path, err)
+	}
+
+	plain, err := io.ReadAll(r)
+	defer Zero(plain)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt key store %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(plain, store); err != nil {
+		return nil, fmt.Errorf("invalid key store %s: %w", path, err)
+	}
+
+	// Stores written before KVVs were recorded get them calculated on open
+	for i := range store.Keys {
+		if store.Keys[i].Kvv == "" {
+			if err := store.Keys[i].Validate(); err != nil {
+				return nil, fmt.Errorf("invalid key store %s: %w", path, err)
+			}
+		}
+	}
+
+	return store, nil
+}
+
+// Encrypt and write the key store to its path
+// The file is written to a temporary file first and then renamed into place
+func (s *Store) Save(recipients ...age.Recipient) error {
+	if s.Path == "" {
+		return fmt.Errorf("key store has no path")
+	}
+
+	plain, err := json.Marshal(s)
+	defer Zero(plain)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(plain); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".keystore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// Get the key with the given name
+func (s *Store) Get(name string) (*Entry, error) {
+	for i := range s.Keys {
+		if s.Keys[i].Name == name {
+			return &s.Keys[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("key %q not found in key store", name)
+}
+
+// Get the key with the given KVV, e.g. the key that sealed a file, expired keys included
+func (s *Store) FindKvv(kvv string) (*Entry, error) {
+	for i := range s.Keys {
+		if strings.EqualFold(s.Keys[i].Kvv, kvv) {
+			return &s.Keys[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no key with KVV %s found in key store", kvv)
+}
+
+// Add a key to the store, names must be unique
+func (s *Store) Add(entry Entry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := s.Get(entry.Name); err == nil {
+		return fmt.Errorf("key %q already exists in key store", entry.Name)
+	}
+
+	if entry.Added == "" {
+		entry.Added = time.Now().Format(DateFormat)
+	}
+
+	s.Keys = append(s.Keys, entry)
+
+	return nil
+}
+
+// Remove the key with the given name, the key material is zeroed
+func (s *Store) Remove(name string) error {
+	for i := range s.Keys {
+		if s.Keys[i].Name == name {
+			Zero(s.Keys[i].Key)
+			s.Keys = append(s.Keys[:i], s.Keys[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("key %q not found in key store", name)
+}
+
+// Replace the key with the given name by a new key valid from the given date
+// The old key expires the day before, the new key takes over its customer and bankgiro numbers
+func (s *Store) Rotate(name string, replacement Entry, validFrom time.Time) error {
+	old, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if replacement.CustomerNumber == "" {
+		replacement.CustomerNumber = old.CustomerNumber
+	}
+	if replacement.Bankgiro == "" {
+		replacement.Bankgiro = old.Bankgiro
+	}
+	replacement.ValidFrom = validFrom.Format(DateFormat)
+
+	if err := replacement.Validate(); err != nil {
+		return err
+	}
+	if replacement.Kvv == old.Kvv {
+		return fmt.Errorf("key %q: new key is the same as the old key", replacement.Name)
+	}
+
+	validTo := validFrom.AddDate(0, 0, -1).Format(DateFormat)
+	if old.ValidFrom != "" && validTo < old.ValidFrom {
+		return fmt.Errorf("key %q: rotation date %s is before the key became valid", name, replacement.ValidFrom)
+	}
+
+	if err := s.Add(replacement); err != nil {
+		return err
+	}
+
+	// Add may have reallocated the slice, fetch the old key again
+	old, _ = s.Get(name)
+	old.ValidTo = validTo
+
+	return nil
+}
+
+// Select the key to seal a file for the given customer and bankgiro number on the given date
+// Keys without customer or bankgiro number match any file, keys with numbers take precedence
+// If several keys match, the one that became valid last is used
+func (s *Store) Select(customerNumber string, bankgiro string, date time.Time) (*Entry, error) {
+	var selected, tied *Entry
+	selectedScore := -1
+
+	for i := range s.Keys {
+		entry := &s.Keys[i]
+		if !entry.ValidOn(date) {
+			continue
+		}
+
+		score := 0
+		if entry.CustomerNumber != "" {
+			if entry.CustomerNumber != customerNumber {
+				continue
+			}
+			score += 2
+		}
+		if entry.Bankgiro != "" {
+			if strings.TrimLeft(entry.Bankgiro, "0") != strings.TrimLeft(bankgiro, "0") {
+				continue
+			}
+			score += 1
+		}
+
+		// A tie only matters if no better key is found later
+		if score > selectedScore || (score == selectedScore && entry.ValidFrom > selected.ValidFrom) {
+			selected, tied = entry, nil
+			selectedScore = score
+		} else if score == selectedScore && entry.ValidFrom == selected.ValidFrom && tied == nil {
+			tied = entry
+		}
+	}
+
+	if selected == nil {
+		return nil, fmt.Errorf("no valid key found for customer number %s, bankgiro %s on %s", customerNumber, bankgiro, date.Format(DateFormat))
+	}
+	if tied != nil {
+		return nil, fmt.Errorf("keys %q and %q both match customer number %s, bankgiro %s", selected.Name, tied.Name, customerNumber, bankgiro)
+	}
+
+	return selected, nil
+}
+
+// Zero all key material held by the store
+func (s *Store) Close() {
+	for i := range s.Keys {
+		Zero(s.Keys[i].Key)
+	}
+	s.Keys = nil
+}
@@ -0,0 +1,216 @@
+package keys_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/hoglandets-it/go-bankgiro/keys"
+)
+
+func TestStoreRoundtrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.age")
+
+	identity, recipient, err := keys.PassphraseIdentity("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := keys.OpenStore(path, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Add(keys.Entry{Name: "customer-1", Key: []byte(TestKey)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(keys.Entry{Name: "customer-1", Key: []byte(TestKey)}); err == nil {
+		t.Error("expected error when adding duplicate key name")
+	}
+	if err := store.Save(recipient); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key store permissions = %04o, want 0600", info.Mode().Perm())
+	}
+
+	reopened, err := keys.OpenStore(path, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	entry, err := reopened.Get("customer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(entry.Key) != TestKey {
+		t.Errorf("Get() key = %q, want %q", entry.Key, TestKey)
+	}
+
+	wrongIdentity, _, err := keys.PassphraseIdentity("wrong passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.OpenStore(path, wrongIdentity); err == nil {
+		t.Error("expected error when opening store with wrong passphrase")
+	}
+}
+
+func TestStoreSelectAndRotate(t *testing.T) {
+	store := &keys.Store{}
+
+	entries := []keys.Entry{
+		{Name: "fallback", Key: []byte("11111111111111111111111111111111")},
+		{Name: "customer-2024", Key: []byte(TestKey), CustomerNumber: "006924", ValidFrom: "2024-01-01"},
+		{Name: "other-customer", Key: []byte("22222222222222222222222222222222"), CustomerNumber: "111111"},
+	}
+	for _, entry := range entries {
+		if err := store.Add(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Add(keys.Entry{Name: "invalid", Key: []byte("1234")}); err == nil {
+		t.Error("expected error when adding an invalid key")
+	}
+
+	err := store.Rotate("customer-2024", keys.Entry{Name: "customer-2025", Key: []byte("33333333333333333333333333333333")}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, _ := store.Get("customer-2024")
+	if old.ValidTo != "2024-12-31" {
+		t.Errorf("rotated key valid to = %s, want 2024-12-31", old.ValidTo)
+	}
+
+	tests := []struct {
+		name           string
+		customerNumber string
+		date           time.Time
+		want           string
+	}{
+		{"Before Rotation", "006924", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "customer-2024"},
+		{"Rotation Day", "006924", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "customer-2025"},
+		{"Other Customer", "111111", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "other-customer"},
+		{"Unknown Customer", "999999", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "fallback"},
+		{"Before Validity", "006924", time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), "fallback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Select(tt.customerNumber, "0009925256", tt.date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("Select() = %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+
+	// The rotated key can still be found to verify files sealed before the rotation
+	if found, err := store.FindKvv(strings.ToLower(TestKeyKvv)); err != nil || found.Name != "customer-2024" {
+		t.Errorf("FindKvv() = %v, %v, want customer-2024", found, err)
+	}
+	if _, err := store.FindKvv("00000000000000000000000000000000"); err == nil {
+		t.Error("expected error when no key has the KVV")
+	}
+
+	if err := store.Remove("fallback"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Select("999999", "0009925256", time.Now()); err == nil {
+		t.Error("expected error when no key matches")
+	}
+}
+
+func TestStoreIdentityFile(t *testing.T) {
+	dir := t.TempDir()
+
+	generated, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identityPath := filepath.Join(dir, "identity.txt")
+	if err := os.WriteFile(identityPath, []byte("# created by test\n"+generated.String()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	identity, recipient, err := keys.IdentityFile(identityPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := keys.OpenStore(filepath.Join(dir, "keys.age"), identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(keys.Entry{Name: "customer-1", Key: []byte(TestKey)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(recipient); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := keys.OpenStore(filepath.Join(dir, "keys.age"), identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := reopened.Get("customer-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Kvv != "FF365893D899291C3BF505FB3175E880" {
+		t.Errorf("KVV = %s, want FF365893D899291C3BF505FB3175E880", entry.Kvv)
+	}
+}
+
+func TestStoreSelectTie(t *testing.T) {
+	a := keys.Entry{Name: "A", Key: []byte(TestKey), ValidFrom: "2025-01-01"}
+	b := keys.Entry{Name: "B", Key: []byte(TestKey), ValidFrom: "2024-01-01"}
+	c := keys.Entry{Name: "C", Key: []byte(TestKey), ValidFrom: "2024-01-01"}
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// A tie between keys that a later key takes precedence over is not ambiguous, whatever the order
+	for _, order := range [][]keys.Entry{{b, c, a}, {a, b, c}, {b, a, c}} {
+		store := &keys.Store{Keys: order}
+		got, err := store.Select("006924", "0009925256", date)
+		if err != nil || got.Name != "A" {
+			t.Errorf("Select() with keys %s, %s, %s = %v, %v, want A", order[0].Name, order[1].Name, order[2].Name, got, err)
+		}
+	}
+
+	store := &keys.Store{Keys: []keys.Entry{a, b, c}}
+	if _, err := store.Select("006924", "0009925256", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)); err == nil || !strings.Contains(err.Error(), `"B" and "C"`) {
+		t.Errorf("Select() error = %v, want B and C to be ambiguous", err)
+	}
+}
+
+func TestOpenStoreInvalidEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.age")
+
+	identity, recipient, err := keys.PassphraseIdentity("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An entry written without a KVV is validated when the store is opened
+	store := &keys.Store{Path: path, Keys: []keys.Entry{{Name: "corrupt", Key: []byte("1234")}}}
+	if err := store.Save(recipient); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := keys.OpenStore(path, identity); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("OpenStore() error = %v, want the invalid key to be reported", err)
+	}
+}
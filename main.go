@@ -4,15 +4,24 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
+	"github.com/hoglandets-it/go-bankgiro/audit"
+	"github.com/hoglandets-it/go-bankgiro/server"
 	"github.com/hoglandets-it/go-bankgiro/shell"
 	"github.com/urfave/cli/v2"
 )
 
+// Set at build time by goreleaser
+var version = "dev"
+
 func main() {
+	audit.Version = version
+
 	app := &cli.App{
 		Name:        "go-bankgiro",
 		HelpName:    "go-bankgiro",
+		Version:     version,
 		Description: "A tool to seal and validate Bankgiro files with HMAC",
 		Commands: []*cli.Command{
 			{
@@ -21,14 +30,8 @@ func main() {
 				Usage:     "seal a file with a given key",
 				Args:      true,
 				ArgsUsage: " [file-to-sign]",
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:     "key",
-						Aliases:  []string{"k"},
-						Required: true,
-						Usage:    "key to seal the file with",
-						EnvVars:  []string{"BG_SEAL_KEY"},
-					},
+				Flags: append(
+					shell.KeyFlags(),
 					&cli.StringFlag{
 						Name:     "kvv",
 						Aliases:  []string{"v"},
@@ -51,7 +54,14 @@ func main() {
 						Usage:       "overwrite the output file if it exists",
 						EnvVars:     []string{"BG_SEAL_OVERWRITE"},
 					},
-				},
+					shell.EncodingFlag(),
+					shell.TransliterateFlag(),
+					shell.TransliterationTableFlag(),
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "print a diff between the input and the sealed file and the normalized HMAC input, without writing anything",
+					},
+				),
 				Action: func(c *cli.Context) error {
 					err := shell.ParseVars(c)
 					if err != nil {
@@ -63,6 +73,466 @@ func main() {
 					return shell.SealFile(c)
 				},
 			},
+			{
+				Name:      "explain",
+				Usage:     "show byte for byte how a file is normalized into the HMAC input",
+				Args:      true,
+				ArgsUsage: " [file]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "date",
+						Usage: "seal date of the HMAC header added to unsealed files, default is today",
+					},
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "show every line, not only lines changed by more than dropping the line break",
+					},
+					&cli.BoolFlag{
+						Name:  "hex",
+						Usage: "also print a hex dump of the normalized data",
+					},
+					shell.NormalizationProfileFlag(),
+					shell.EncodingFlag(),
+					shell.TransliterateFlag(),
+					shell.TransliterationTableFlag(),
+				},
+				Action: shell.Explain,
+			},
+			{
+				Name:      "unseal",
+				Usage:     "verify the seal of a sealed file and remove its HMAC header and trailer",
+				Args:      true,
+				ArgsUsage: " [sealed-file]",
+				Flags: append(
+					shell.KeyFlags(),
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "output file, default is [sealed-file]-unsealed",
+					},
+					&cli.BoolFlag{
+						Name:    "overwrite",
+						Aliases: []string{"f"},
+						Usage:   "overwrite the output file if it exists",
+					},
+				),
+				Action: shell.Unseal,
+			},
+			{
+				Name:      "reseal",
+				Usage:     "verify the seal of a sealed file with the old key and seal it again with a new key or date",
+				Args:      true,
+				ArgsUsage: " [sealed-file]",
+				Flags: append(
+					append(shell.KeyFlags(), shell.OldKeyFlags()...),
+					&cli.StringFlag{
+						Name:    "kvv",
+						Aliases: []string{"v"},
+						Usage:   "kvv to check the new key with (optional)",
+						EnvVars: []string{"BG_SEAL_KVV"},
+					},
+					&cli.StringFlag{
+						Name:  "date",
+						Usage: "seal date of the new seal, YYMMDD, default is today",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "output file, default is [sealed-file]-resealed",
+					},
+					&cli.BoolFlag{
+						Name:    "overwrite",
+						Aliases: []string{"f"},
+						Usage:   "overwrite the output file if it exists",
+					},
+				),
+				Action: shell.Reseal,
+			},
+			{
+				Name:      "seal-batch",
+				Usage:     "seal all matching files in a directory and write a manifest",
+				Args:      true,
+				ArgsUsage: " [input-dir]",
+				Flags: append(
+					shell.KeyFlags(),
+					&cli.StringFlag{
+						Name:    "kvv",
+						Aliases: []string{"v"},
+						Usage:   "kvv to check the seal with (optional)",
+						EnvVars: []string{"BG_SEAL_KVV"},
+					},
+					&cli.StringFlag{
+						Name:     "output-dir",
+						Aliases:  []string{"o"},
+						Required: true,
+						Usage:    "directory to write the sealed files to",
+					},
+					&cli.StringSliceFlag{
+						Name:    "pattern",
+						Aliases: []string{"p"},
+						Usage:   "only seal files with names matching the glob pattern, can be repeated",
+					},
+					&cli.BoolFlag{
+						Name:    "recursive",
+						Aliases: []string{"r"},
+						Usage:   "seal files in subdirectories",
+					},
+					&cli.IntFlag{
+						Name:    "workers",
+						Aliases: []string{"j"},
+						Usage:   "number of files sealed concurrently, default is the number of CPUs",
+					},
+					&cli.BoolFlag{
+						Name:    "overwrite",
+						Aliases: []string{"f"},
+						Usage:   "overwrite existing files in the output directory",
+					},
+					&cli.StringFlag{
+						Name:  "manifest",
+						Usage: "manifest file, default is [output-dir]/manifest.json",
+					},
+					shell.EncodingFlag(),
+					shell.TransliterateFlag(),
+					shell.TransliterationTableFlag(),
+				),
+				Action: shell.SealBatch,
+			},
+			{
+				Name:  "watch",
+				Usage: "watch an inbox directory and seal files as they arrive",
+				Flags: append(
+					shell.KeyFlags(),
+					&cli.StringFlag{
+						Name:    "kvv",
+						Aliases: []string{"v"},
+						Usage:   "kvv to check the seal with (optional)",
+						EnvVars: []string{"BG_SEAL_KVV"},
+					},
+					&cli.StringFlag{
+						Name:     "inbox",
+						Required: true,
+						Usage:    "directory to watch for new files",
+						EnvVars:  []string{"BG_WATCH_INBOX"},
+					},
+					&cli.StringFlag{
+						Name:     "outbox",
+						Required: true,
+						Usage:    "directory to write sealed files to",
+						EnvVars:  []string{"BG_WATCH_OUTBOX"},
+					},
+					&cli.StringFlag{
+						Name:     "archive",
+						Required: true,
+						Usage:    "directory to move original files to once sealed",
+						EnvVars:  []string{"BG_WATCH_ARCHIVE"},
+					},
+					&cli.StringFlag{
+						Name:     "error-dir",
+						Required: true,
+						Usage:    "directory to move files that could not be sealed to, with a .err file explaining why",
+						EnvVars:  []string{"BG_WATCH_ERROR_DIR"},
+					},
+					&cli.StringSliceFlag{
+						Name:    "pattern",
+						Aliases: []string{"p"},
+						Usage:   "only seal files with names matching the glob pattern, can be repeated",
+					},
+					&cli.DurationFlag{
+						Name:  "settle-time",
+						Value: 2 * time.Second,
+						Usage: "how long a file must remain unchanged before it is sealed",
+					},
+				),
+				Action: shell.WatchInbox,
+			},
+			{
+				Name:  "serve",
+				Usage: "serve an HTTP and gRPC API for sealing, verifying and parsing files",
+				Flags: append(
+					shell.KeyFlags(),
+					&cli.StringFlag{
+						Name:    "kvv",
+						Aliases: []string{"v"},
+						Usage:   "kvv to check the seal with (optional)",
+						EnvVars: []string{"BG_SEAL_KVV"},
+					},
+					&cli.StringFlag{
+						Name:    "listen",
+						Aliases: []string{"l"},
+						Value:   ":8080",
+						Usage:   "address to listen on",
+						EnvVars: []string{"BG_SERVE_LISTEN"},
+					},
+					&cli.StringFlag{
+						Name:    "grpc-listen",
+						Usage:   "address to serve the gRPC API on, disabled by default",
+						EnvVars: []string{"BG_SERVE_GRPC_LISTEN"},
+					},
+					&cli.StringFlag{
+						Name:    "token-file",
+						Usage:   "file with the accepted bearer tokens, one per line, default is the comma separated BG_SERVE_TOKENS variable",
+						EnvVars: []string{"BG_SERVE_TOKEN_FILE"},
+					},
+					&cli.Int64Flag{
+						Name:  "max-body-size",
+						Value: server.DefaultMaxBodySize,
+						Usage: "maximum request body size in bytes",
+					},
+					&cli.DurationFlag{
+						Name:  "shutdown-timeout",
+						Value: 30 * time.Second,
+						Usage: "time given to running requests on shutdown",
+					},
+					&cli.StringFlag{
+						Name:    "tls-cert",
+						Usage:   "certificate file to serve HTTPS with",
+						EnvVars: []string{"BG_SERVE_TLS_CERT"},
+					},
+					&cli.StringFlag{
+						Name:    "tls-key",
+						Usage:   "private key file to serve HTTPS with",
+						EnvVars: []string{"BG_SERVE_TLS_KEY"},
+					},
+				),
+				Action: shell.Serve,
+			},
+			{
+				Name:      "send",
+				Usage:     "upload sealed files to the Bankgiro file exchange over SFTP",
+				Args:      true,
+				ArgsUsage: " [file...]",
+				Flags: append(
+					shell.TransportFlags(),
+					&cli.StringFlag{
+						Name:     "remote-dir",
+						Required: true,
+						Usage:    "remote directory to upload to",
+						EnvVars:  []string{"BG_SFTP_OUTBOX"},
+					},
+					&cli.BoolFlag{
+						Name:    "force",
+						Aliases: []string{"f"},
+						Usage:   "send files again that have already been sent, replacing remote files with the same name",
+					},
+				),
+				Action: shell.Send,
+			},
+			{
+				Name:  "fetch",
+				Usage: "download new return files from the Bankgiro file exchange over SFTP",
+				Flags: append(
+					shell.TransportFlags(),
+					&cli.StringFlag{
+						Name:     "remote-dir",
+						Required: true,
+						Usage:    "remote directory to download from",
+						EnvVars:  []string{"BG_SFTP_INBOX"},
+					},
+					&cli.StringFlag{
+						Name:     "inbox",
+						Required: true,
+						Usage:    "local directory to download files to",
+						EnvVars:  []string{"BG_FETCH_INBOX"},
+					},
+					&cli.StringSliceFlag{
+						Name:    "pattern",
+						Aliases: []string{"p"},
+						Usage:   "only fetch files with names matching the glob pattern, can be repeated",
+					},
+					&cli.BoolFlag{
+						Name:  "remove",
+						Usage: "remove the remote files once downloaded",
+					},
+				),
+				Action: shell.Fetch,
+			},
+			{
+				Name:      "reconcile",
+				Usage:     "match the payments in a Betalningsspecifikation to the expected charges and report paid, rejected, unexpected and missing charges",
+				Args:      true,
+				ArgsUsage: " [betalningsspec-file]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "charges",
+						Required: true,
+						Usage:    "CSV file of expected charges with the columns id, payer_number, amount, date and reference",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print the report as JSON",
+					},
+				},
+				Action: shell.Reconcile,
+			},
+			{
+				Name:      "convert",
+				Usage:     "convert the old format sections of an Autogiro file to the new format",
+				Args:      true,
+				ArgsUsage: " [file]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "output file, default is [file]-new",
+					},
+					&cli.BoolFlag{
+						Name:    "overwrite",
+						Aliases: []string{"f"},
+						Usage:   "overwrite the output file if it exists",
+					},
+				},
+				Action: shell.Convert,
+			},
+			{
+				Name:      "emandate",
+				Usage:     "validate the e-mandates of an AG-EMEDGIV file and write the TK04 records approving or rejecting them",
+				Args:      true,
+				ArgsUsage: " [emedgiv-file]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "file to write TK04 records to, approving the valid mandates and rejecting the others",
+					},
+					&cli.BoolFlag{
+						Name:    "overwrite",
+						Aliases: []string{"f"},
+						Usage:   "overwrite the output file if it exists",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print the mandates as JSON",
+					},
+				},
+				Action: shell.Emandate,
+			},
+			{
+				Name:      "inspect",
+				Usage:     "print the records of an Autogiro file with the status and comment codes decoded",
+				Args:      true,
+				ArgsUsage: " [file]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "codes",
+						Usage: "print the code tables instead of a file",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print the records as JSON",
+					},
+				},
+				Action: shell.Inspect,
+			},
+			{
+				Name:  "audit",
+				Usage: "inspect the audit log of seal operations",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "verify",
+						Usage:     "verify the hash chain of the audit log, detecting modified, removed or inserted records",
+						Args:      true,
+						ArgsUsage: " [audit-log]",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "audit-log",
+								Usage:   "audit log to verify, if not given as an argument",
+								EnvVars: []string{"BG_AUDIT_LOG"},
+							},
+							&cli.StringFlag{
+								Name:  "head",
+								Usage: "expected hash of the last record, detects records removed from the end of the log",
+							},
+						},
+						Action: shell.AuditVerify,
+					},
+				},
+			},
+			{
+				Name:  "key",
+				Usage: "manage the encrypted key store",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "key-store",
+						Usage:   "path to the encrypted key store",
+						EnvVars: []string{"BG_KEY_STORE"},
+					},
+					&cli.StringFlag{
+						Name:    "key-store-passphrase-file",
+						Usage:   "read the key store passphrase from a file, default is the BG_KEY_STORE_PASSPHRASE variable",
+						EnvVars: []string{"BG_KEY_STORE_PASSPHRASE_FILE"},
+					},
+					&cli.StringFlag{
+						Name:    "key-store-identity",
+						Usage:   "decrypt the key store with an age X25519 identity file instead of a passphrase",
+						EnvVars: []string{"BG_KEY_STORE_IDENTITY"},
+					},
+				},
+				Subcommands: []*cli.Command{
+					{
+						Name:      "add",
+						Usage:     "add a key to the key store, the key is read from stdin",
+						Args:      true,
+						ArgsUsage: " [name]",
+						Flags:     shell.KeyEntryFlags(),
+						Action:    shell.KeyAdd,
+					},
+					{
+						Name:   "list",
+						Usage:  "list the keys in the key store",
+						Action: shell.KeyList,
+					},
+					{
+						Name:      "rotate",
+						Usage:     "replace a key with a new key read from stdin, the old key expires the day before --valid-from",
+						Args:      true,
+						ArgsUsage: " [old-name] [new-name]",
+						Flags:     shell.KeyEntryFlags(),
+						Action:    shell.KeyRotate,
+					},
+					{
+						Name:      "remove",
+						Usage:     "remove a key from the key store",
+						Args:      true,
+						ArgsUsage: " [name]",
+						Action:    shell.KeyRemove,
+					},
+					{
+						Name:      "kvv",
+						Usage:     "print the KVV of a key",
+						Args:      true,
+						ArgsUsage: " [name]",
+						Action:    shell.KeyKvv,
+					},
+					{
+						Name:  "combine",
+						Usage: "combine key components delivered by Bankgiro into a key file, verifying each check value and the KVV",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "kvv",
+								Aliases:  []string{"v"},
+								Required: true,
+								Usage:    "KVV of the combined key",
+							},
+							&cli.StringFlag{
+								Name:     "output",
+								Aliases:  []string{"o"},
+								Required: true,
+								Usage:    "key file to create, readable only by its owner",
+							},
+							&cli.StringSliceFlag{
+								Name:  "component-file",
+								Usage: "read a component from a file, the first line is the component and the second its check value",
+							},
+							&cli.IntFlag{
+								Name:  "components",
+								Value: 2,
+								Usage: "number of components to enter on the terminal",
+							},
+						},
+						Action: shell.KeyCombine,
+					},
+				},
+			},
 		},
 	}
 
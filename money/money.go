@@ -0,0 +1,172 @@
+package money
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// An amount in öre, negative for credits and withdrawals
+type Amount int64
+
+// Get the amount of whole kronor
+func Kronor(kronor int64) Amount {
+	return Amount(kronor * 100)
+}
+
+// Get the amount in öre
+func (a Amount) Ore() int64 {
+	return int64(a)
+}
+
+// Add the amounts, failing instead of overflowing
+func (a Amount) Add(b Amount) (Amount, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, fmt.Errorf("the sum of %s and %s is too large", a, b)
+	}
+
+	return a + b, nil
+}
+
+// Add all amounts, failing instead of overflowing
+func Sum(amounts ...Amount) (Amount, error) {
+	var sum Amount
+	for _, amount := range amounts {
+		var err error
+		if sum, err = sum.Add(amount); err != nil {
+			return 0, err
+		}
+	}
+
+	return sum, nil
+}
+
+// Parse a fixed width amount field of öre, zero padded, e.g. 000000012350 for 123.50
+// A blank field is zero
+func ParseField(field string) (Amount, error) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return 0, nil
+	}
+
+	for _, c := range field {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid amount %q", field)
+		}
+	}
+
+	ore, err := strconv.ParseInt(field, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %q is too large", field)
+	}
+
+	return Amount(ore), nil
+}
+
+// Format the amount as a fixed width field of öre, zero padded to the width
+// Fixed width fields have no sign, negative amounts and amounts wider than the field are errors
+func (a Amount) Field(width int) (string, error) {
+	if a < 0 {
+		return "", fmt.Errorf("amount %s is negative, fields only hold positive amounts", a)
+	}
+
+	field := fmt.Sprintf("%0*d", width, int64(a))
+	if len(field) > width {
+		return "", fmt.Errorf("amount %s does not fit in %d digits", a, width)
+	}
+
+	return field, nil
+}
+
+// Parse an amount in kronor, e.g. 1234.50, 1234,5, 1 234,50 or -12
+func Parse(s string) (Amount, error) {
+	cleaned := strings.NewReplacer(" ", "", " ", "", ",", ".").Replace(strings.TrimSpace(s))
+
+	kronor, ore, found := strings.Cut(cleaned, ".")
+	if !found {
+		ore = "00"
+	}
+	if len(ore) == 1 {
+		ore += "0"
+	}
+
+	negative := strings.HasPrefix(kronor, "-")
+	kronor = strings.TrimPrefix(kronor, "-")
+	if kronor == "" || len(ore) != 2 || strings.ContainsAny(kronor+ore, "+-") {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	value, err := strconv.ParseInt(kronor+ore, 10, 64)
+	if err != nil {
+		if err.(*strconv.NumError).Err == strconv.ErrRange {
+			return 0, fmt.Errorf("amount %q is too large", s)
+		}
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		value = -value
+	}
+
+	return Amount(value), nil
+}
+
+// Format the amount in kronor with two decimals, e.g. 1234.50
+func (a Amount) String() string {
+	sign, kronor, ore := a.parts()
+
+	return fmt.Sprintf("%s%d.%02d", sign, kronor, ore)
+}
+
+// Format the amount in kronor the Swedish way, e.g. 1 234,50
+func (a Amount) Swedish() string {
+	sign, kronor, ore := a.parts()
+
+	digits := strconv.FormatUint(kronor, 10)
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte(' ')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return fmt.Sprintf("%s%s,%02d", sign, grouped.String(), ore)
+}
+
+// Get the sign, whole kronor and öre of the amount
+func (a Amount) parts() (string, uint64, uint64) {
+	sign, ore := "", uint64(a)
+	if a < 0 {
+		sign, ore = "-", uint64(-(a+1))+1
+	}
+
+	return sign, ore / 100, ore % 100
+}
+
+// Amounts are JSON numbers in kronor with two decimals, e.g. 1234.50
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Read an amount in kronor, from a JSON number or a string such as "1 234,50"
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	} else if strings.ContainsAny(s, "eE") {
+		return fmt.Errorf("invalid amount %s, exponents are not supported", s)
+	}
+
+	amount, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = amount
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package money_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/money"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    money.Amount
+		wantErr bool
+	}{
+		{"1234.50", 123450, false},
+		{"1 234,5", 123450, false},
+		{"-10", -1000, false},
+		{"0.05", 5, false},
+		{"-0.50", -50, false},
+		{"12.345", 0, true},
+		{"--5", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+		{"92233720368547758.08", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := money.Parse(tt.input)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Parse() = %d, %v, expected %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount  money.Amount
+		plain   string
+		swedish string
+	}{
+		{0, "0.00", "0,00"},
+		{5, "0.05", "0,05"},
+		{123450, "1234.50", "1 234,50"},
+		{-123456789, "-1234567.89", "-1 234 567,89"},
+		{money.Kronor(100000), "100000.00", "100 000,00"},
+		{math.MinInt64, "-92233720368547758.08", "-92 233 720 368 547 758,08"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.plain, func(t *testing.T) {
+			if tt.amount.String() != tt.plain || tt.amount.Swedish() != tt.swedish {
+				t.Errorf("String() = %s, Swedish() = %s, expected %s and %s", tt.amount, tt.amount.Swedish(), tt.plain, tt.swedish)
+			}
+		})
+	}
+}
+
+func TestField(t *testing.T) {
+	amount, err := money.ParseField("000000012350")
+	if err != nil || amount != 12350 {
+		t.Errorf("ParseField() = %d, %v", amount, err)
+	}
+	if amount, err := money.ParseField("            "); err != nil || amount != 0 {
+		t.Errorf("ParseField() of a blank field = %d, %v", amount, err)
+	}
+	for _, field := range []string{"00000001235-", "0000 0012350", "99999999999999999999"} {
+		if _, err := money.ParseField(field); err == nil {
+			t.Errorf("ParseField(%q) expected an error", field)
+		}
+	}
+
+	if field, err := amount.Field(12); err != nil || field != "000000012350" {
+		t.Errorf("Field() = %q, %v", field, err)
+	}
+	if _, err := money.Amount(1234567890123).Field(12); err == nil {
+		t.Error("Field() of an amount wider than the field expected an error")
+	}
+	if _, err := money.Amount(-1).Field(12); err == nil {
+		t.Error("Field() of a negative amount expected an error")
+	}
+}
+
+func TestSum(t *testing.T) {
+	if sum, err := money.Sum(100, 250, -50); err != nil || sum != 300 {
+		t.Errorf("Sum() = %d, %v", sum, err)
+	}
+	if _, err := money.Sum(math.MaxInt64, 1); err == nil {
+		t.Error("Sum() expected an overflow error")
+	}
+	if _, err := money.Sum(math.MinInt64, -1); err == nil {
+		t.Error("Sum() expected an overflow error")
+	}
+}
+
+func TestJson(t *testing.T) {
+	data, err := json.Marshal(struct{ Amount money.Amount }{123450})
+	if err != nil || string(data) != `{"Amount":1234.50}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+
+	tests := []struct {
+		input string
+		want  money.Amount
+	}{
+		{`1234.50`, 123450},
+		{`-3`, -300},
+		{`"1 234,50"`, 123450},
+	}
+	for _, tt := range tests {
+		var amount money.Amount
+		if err := json.Unmarshal([]byte(tt.input), &amount); err != nil || amount != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, %v, expected %d", tt.input, amount, err, tt.want)
+		}
+	}
+
+	var amount money.Amount
+	if err := json.Unmarshal([]byte(`1e3`), &amount); err == nil {
+		t.Error("Unmarshal() of an exponent expected an error")
+	}
+}
@@ -0,0 +1,195 @@
+package parse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hoglandets-it/go-bankgiro/fixedwidth"
+)
+
+// A status, result or comment code with its meaning in Swedish and English
+type Code struct {
+	Code    string `json:"code"`
+	Swedish string `json:"sv"`
+	English string `json:"en"`
+}
+
+func (c Code) String() string {
+	if c.Code == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s %s (%s)", c.Code, c.Swedish, c.English)
+}
+
+// Check if the code was found in its code table
+func (c Code) Known() bool {
+	return c.Swedish != unknownCode.Swedish
+}
+
+// The codes used in one field, e.g. the comment code of a rejected payment
+// Codes: the Swedish and English text of each code
+type CodeTable struct {
+	Name  string
+	Codes map[string][2]string
+}
+
+var unknownCode = Code{Swedish: "Okänd kod", English: "Unknown code"}
+
+// Get the code with its text, codes not in the table are returned as unknown
+// An empty code is returned as is, the field was not set
+func (t *CodeTable) Lookup(code string) Code {
+	if code == "" {
+		return Code{}
+	}
+
+	text, found := t.Codes[code]
+	if !found {
+		return Code{Code: code, Swedish: unknownCode.Swedish, English: unknownCode.English}
+	}
+
+	return Code{Code: code, Swedish: text[0], English: text[1]}
+}
+
+// Get all codes in the table, sorted by code
+func (t *CodeTable) List() []Code {
+	codes := make([]Code, 0, len(t.Codes))
+	for code := range t.Codes {
+		codes = append(codes, t.Lookup(code))
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+
+	return codes
+}
+
+// The code tables of the Autogiro files, as described in Bankgirot's technical manual
+var CodeTables = []*CodeTable{PaymentResults, RefundReasons, RejectionComments, ChangeComments, MandateInformation, MandateComments}
+
+// The code tables by the name used in record tags, e.g. bg:"pos=80,len=1,table=payment-result"
+var codeTableNames = map[string]*CodeTable{
+	"payment-result":      PaymentResults,
+	"refund-reason":       RefundReasons,
+	"rejection-comment":   RejectionComments,
+	"change-comment":      ChangeComments,
+	"mandate-information": MandateInformation,
+	"mandate-comment":     MandateComments,
+}
+
+// Decode the code field with its text from the table named by the table option of the tag
+func (c *Code) UnmarshalFixed(field string, tag fixedwidth.Tag) error {
+	table, found := codeTableNames[tag.Options["table"]]
+	if !found {
+		return fmt.Errorf("unknown code table %q", tag.Options["table"])
+	}
+
+	*c = table.Lookup(strings.TrimSpace(field))
+
+	return nil
+}
+
+// Encode the code, left aligned in the field
+func (c Code) MarshalFixed(tag fixedwidth.Tag) (string, error) {
+	if len(c.Code) > tag.Len {
+		return "", fmt.Errorf("code %q is longer than %d characters", c.Code, tag.Len)
+	}
+
+	return c.Code + strings.Repeat(" ", tag.Len-len(c.Code)), nil
+}
+
+// The payment result of a payment (TK82) or credit (TK32) in a Betalningsspecifikation
+var PaymentResults = &CodeTable{
+	Name: "Betalningsresultat",
+	Codes: map[string][2]string{
+		"0": {"Godkänd", "Approved"},
+		"1": {"Täckning saknas", "Insufficient funds"},
+		"2": {"Koppling till Autogiro saknas, kontot är avslutat eller medgivande saknas", "No Autogiro connection, the account is closed or there is no mandate"},
+		"9": {"Förnyad täckning, ett nytt försök görs", "Insufficient funds, a new attempt will be made"},
+	},
+}
+
+// The refund reason of a refund (TK77) in a Betalningsspecifikation
+var RefundReasons = &CodeTable{
+	Name: "Återbetalningsorsak",
+	Codes: map[string][2]string{
+		"01": {"Återbetalning på begäran av betalaren", "Refunded at the payer's request"},
+		"02": {"Återbetalning på begäran av betalningsmottagaren", "Refunded at the payee's request"},
+		"03": {"Återbetalning, medgivande saknas", "Refunded, there is no mandate"},
+	},
+}
+
+// The comment code of a rejected payment (TK82) or credit (TK32) in Avvisade betalningsuppdrag
+var RejectionComments = &CodeTable{
+	Name: "Kommentarskod avvisade betalningsuppdrag",
+	Codes: map[string][2]string{
+		"01": {"Medgivande saknas", "There is no mandate"},
+		"02": {"Medgivandet är makulerat", "The mandate is cancelled"},
+		"03": {"Kontot är avslutat", "The account is closed"},
+		"04": {"Kontot är inte godkänt för Autogiro", "The account is not approved for Autogiro"},
+		"05": {"Betalarnumret saknas i medgivanderegistret", "The payer number is not in the mandate register"},
+		"06": {"Felaktig periodkod", "Invalid period code"},
+		"07": {"Felaktigt antal självförnyande uppdrag", "Invalid number of self-renewing payments"},
+		"08": {"Beloppet är inte numeriskt", "The amount is not numeric"},
+		"09": {"Förbud mot utbetalningar", "Credits are not allowed"},
+		"10": {"Bankgironumret saknas eller är felaktigt", "The bankgiro number is missing or invalid"},
+		"11": {"Felaktig referens", "Invalid reference"},
+		"12": {"Felaktig betalningsdag", "Invalid payment date"},
+		"13": {"Betalningsdagen har passerats", "The payment date has passed"},
+		"14": {"Betalningsdagen ligger för långt fram", "The payment date is too far ahead"},
+		"15": {"Felaktig transaktionskod", "Invalid record code"},
+		"16": {"Uppdraget finns redan", "Duplicate payment"},
+		"24": {"Beloppet överstiger gränsen för medgivandet", "The amount exceeds the limit of the mandate"},
+	},
+}
+
+// The comment code of a cancelled or changed payment in a Makulerings-/ändringslista
+var ChangeComments = &CodeTable{
+	Name: "Kommentarskod makulering/ändring",
+	Codes: map[string][2]string{
+		"11": {"Makulerat på begäran av betalaren", "Cancelled at the payer's request"},
+		"12": {"Makulerat på begäran av betalningsmottagaren", "Cancelled at the payee's request"},
+		"13": {"Makulerat, medgivandet är makulerat", "Cancelled, the mandate is cancelled"},
+		"14": {"Makulerat, kontot är avslutat", "Cancelled, the account is closed"},
+		"15": {"Makulerat, medgivande saknas", "Cancelled, there is no mandate"},
+		"16": {"Betalningsdagen är ändrad", "The payment date is changed"},
+		"17": {"Stoppat av betalaren", "Stopped by the payer"},
+		"18": {"Makulerat av Bankgirot", "Cancelled by Bankgirot"},
+	},
+}
+
+// The information code of a mandate notice (TK73) in a Medgivandeavisering
+var MandateInformation = &CodeTable{
+	Name: "Informationskod medgivandeavisering",
+	Codes: map[string][2]string{
+		"03": {"Makulering av medgivande", "Mandate cancelled"},
+		"04": {"Anslutning, nytt medgivande", "Mandate added"},
+		"05": {"Byte av betalarnummer", "Payer number changed"},
+		"10": {"Medgivandet är avvisat", "Mandate rejected"},
+		"42": {"Medgivande via Internetbanken", "Mandate through the internet bank"},
+		"43": {"Makulering på initiativ av Bankgirot", "Cancelled by Bankgirot"},
+		"44": {"Makulering på initiativ av betalaren", "Cancelled by the payer"},
+		"46": {"Makulering på initiativ av betalarens bank", "Cancelled by the payer's bank"},
+	},
+}
+
+// The comment code of a mandate notice (TK73) in a Medgivandeavisering
+var MandateComments = &CodeTable{
+	Name: "Kommentarskod medgivandeavisering",
+	Codes: map[string][2]string{
+		"02": {"Medgivandet är makulerat på initiativ av betalaren eller betalarens bank", "Cancelled by the payer or the payer's bank"},
+		"03": {"Kontot är avslutat", "The account is closed"},
+		"04": {"Kontot saknas", "The account does not exist"},
+		"05": {"Kontonumret stämmer inte med personnumret eller organisationsnumret", "The account number does not match the personal identity or organisation number"},
+		"06": {"Betalarnumret finns redan i medgivanderegistret", "The payer number is already in the mandate register"},
+		"07": {"Medgivandet saknas i medgivanderegistret", "The mandate is not in the mandate register"},
+		"09": {"Felaktigt personnummer eller organisationsnummer", "Invalid personal identity or organisation number"},
+		"10": {"Betalarnumret saknas", "The payer number is missing"},
+		"20": {"Kontot är inte godkänt för Autogiro", "The account is not approved for Autogiro"},
+		"21": {"Felaktigt betalarnummer", "Invalid payer number"},
+		"23": {"Felaktigt kontonummer", "Invalid account number"},
+		"24": {"Betalningsmottagarens bankgironummer är avslutat", "The payee's bankgiro number is closed"},
+		"32": {"Nytt medgivande", "New mandate"},
+		"33": {"Medgivandet är makulerat", "The mandate is cancelled"},
+		"98": {"Medgivandet är makulerat på grund av felaktigt personnummer", "Cancelled because of an invalid personal identity number"},
+	},
+}
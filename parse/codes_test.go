@@ -0,0 +1,49 @@
+package parse_test
+
+import (
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/parse"
+)
+
+func TestCodeLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   *parse.CodeTable
+		code    string
+		english string
+		known   bool
+		text    string
+	}{
+		{"known", parse.MandateComments, "03", "The account is closed", true, "03 Kontot är avslutat (The account is closed)"},
+		{"unknown", parse.MandateComments, "77", "Unknown code", false, "77 Okänd kod (Unknown code)"},
+		{"payment result", parse.PaymentResults, "1", "Insufficient funds", true, "1 Täckning saknas (Insufficient funds)"},
+		{"empty", parse.RejectionComments, "", "", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := tt.table.Lookup(tt.code)
+			if code.Code != tt.code || code.English != tt.english || code.Known() != tt.known || code.String() != tt.text {
+				t.Errorf("Lookup(%q) = %+v", tt.code, code)
+			}
+		})
+	}
+}
+
+func TestCodeTables(t *testing.T) {
+	for _, table := range parse.CodeTables {
+		codes := table.List()
+		if len(codes) == 0 {
+			t.Errorf("%s has no codes", table.Name)
+		}
+
+		for i, code := range codes {
+			if code.Swedish == "" || code.English == "" {
+				t.Errorf("%s: code %s has no text", table.Name, code.Code)
+			}
+			if i > 0 && codes[i-1].Code >= code.Code {
+				t.Errorf("%s: codes are not sorted", table.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package parse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hoglandets-it/go-bankgiro/fixedwidth"
+	"github.com/hoglandets-it/go-bankgiro/money"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+)
+
+// The new format section type of each old format section type
+var newFormats = map[string]string{
+	"avvisade-old":       "avvisade-new",
+	"andringslista-old":  "andringslista-new",
+	"betalningsspec-old": "betalningsspec-new",
+	"medgivandeavi-old":  "medgivandeavi-new",
+}
+
+// Get the section type with the given code
+func SectionTypeByCode(code string) (SectionType, error) {
+	for _, sectionType := range SectionTypes {
+		if sectionType.Code == code {
+			return sectionType, nil
+		}
+	}
+
+	return SectionType{}, fmt.Errorf("unknown section type %s", code)
+}
+
+// Check if the section is in an old format layout that can be converted to the new format
+func (sec *AutogiroSection) OldFormat() bool {
+	_, found := newFormats[sec.SectionType.Code]
+	return found
+}
+
+// Convert all old format sections of the file to the new format
+// The HMAC header, trailer and section seals are not kept, verify the seal before converting
+func (file *AutogiroFile) ToNewFormat() (*AutogiroFile, error) {
+	converted := &AutogiroFile{}
+
+	for i := range file.Sections {
+		sec, err := file.Sections[i].ToNewFormat()
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", i+1, err)
+		}
+
+		converted.Sections = append(converted.Sections, sec)
+		converted.Content = append(converted.Content, sec.Rows...)
+	}
+
+	return converted, nil
+}
+
+// Get the rows of all sections as ISO-8859-1 text, each row ending with CRLF
+func (file *AutogiroFile) Text() string {
+	var text strings.Builder
+	for _, sec := range file.Sections {
+		for _, row := range sec.Rows {
+			text.WriteString(row)
+			text.WriteString("\r\n")
+		}
+	}
+
+	return text.String()
+}
+
+// Convert an old format section to the new format, sections in other formats are returned as is
+// The opening record is rewritten in the new layout and rows are padded to 80 characters
+// Old format Betalningsspecifikation sections get deposit (TK15) and withdrawal (TK16) records and a new closing record,
+// the payee's account is not in the old format and is left as zeros
+func (sec *AutogiroSection) ToNewFormat() (AutogiroSection, error) {
+	if !sec.OldFormat() {
+		return *sec, nil
+	}
+	if !sec.StartFound || !sec.EndFound || len(sec.Rows) < 2 {
+		return AutogiroSection{}, fmt.Errorf("the section is incomplete")
+	}
+
+	sectionType, err := SectionTypeByCode(newFormats[sec.SectionType.Code])
+	if err != nil {
+		return AutogiroSection{}, err
+	}
+
+	rows := make([]string, 0, len(sec.Rows))
+	for i, row := range sec.Rows {
+		fixed, err := fixedWidth(row)
+		if err != nil {
+			return AutogiroSection{}, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		rows = append(rows, fixed)
+	}
+
+	converted := AutogiroSection{StartFound: true, EndFound: true, SectionType: sectionType}
+	converted.Rows = append(converted.Rows, openingRecord(sectionType, rows[0][2:10], sec.GetCustomerNumber(), sec.GetAccountNumber()))
+
+	if sectionType.Code == "betalningsspec-new" {
+		payments, err := paymentRows(rows[1 : len(rows)-1])
+		if err != nil {
+			return AutogiroSection{}, err
+		}
+		converted.Rows = append(converted.Rows, payments...)
+		converted.Rows = append(converted.Rows, paymentClosingRecord(rows[len(rows)-1][2:10], converted.Rows))
+	} else {
+		// The records and the closing record have the same layout in both formats
+		converted.Rows = append(converted.Rows, rows[1:]...)
+	}
+
+	return converted, nil
+}
+
+// Pad the row to 80 characters, replacing tabs with spaces
+// Trailing whitespace beyond 80 characters is removed, other content is an error
+func fixedWidth(row string) (string, error) {
+	row = strings.ReplaceAll(strings.TrimRight(row, " \t"), "\t", " ")
+	if len(row) > 80 {
+		return "", fmt.Errorf("row is %d characters, expected 80", len(row))
+	}
+
+	return row + strings.Repeat(" ", 80-len(row)), nil
+}
+
+// Get a new format opening record (TK01)
+func openingRecord(sectionType SectionType, date string, customerNumber string, accountNumber string) string {
+	return fmt.Sprintf("01AUTOGIRO%14s%-8s%12s%-20s%6s%10s", "", date, "", tools.StringEnsureIso(sectionType.Match), customerNumber, accountNumber)
+}
+
+// Group the payments (TK82) and credits (TK32) of an old format Betalningsspecifikation by payment date
+// Each group starts with a deposit (TK15) or withdrawal (TK16) record summarizing its approved records
+// A blank payment result is approved in the old format, it is set to 0
+func paymentRows(rows []string) ([]string, error) {
+	converted := []string{}
+
+	for _, codes := range [][2]string{{TK_PAYMENT, TK_DEPOSIT}, {TK_CREDIT, TK_WITHDRAWAL}} {
+		dates := []string{}
+		groups := map[string][]string{}
+
+		for _, row := range rows {
+			if row[0:2] != codes[0] {
+				continue
+			}
+			if row[79] == ' ' {
+				row = row[:79] + "0"
+			}
+
+			date := row[2:10]
+			if _, found := groups[date]; !found {
+				dates = append(dates, date)
+			}
+			groups[date] = append(groups[date], row)
+		}
+
+		for i, date := range dates {
+			approved := []money.Amount{}
+			for _, row := range groups[date] {
+				if row[79] == '0' {
+					amount, err := money.ParseField(row[31:43])
+					if err != nil {
+						return nil, err
+					}
+					approved = append(approved, amount)
+				}
+			}
+
+			sum, err := money.Sum(approved...)
+			if err != nil {
+				return nil, err
+			}
+			deposit, err := fixedwidth.Marshal(DepositRecord{
+				Code:         codes[1],
+				PaymentDate:  date,
+				SerialNumber: strconv.Itoa(i + 1),
+				Amount:       sum,
+				Count:        len(approved),
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			converted = append(converted, deposit)
+			converted = append(converted, groups[date]...)
+		}
+	}
+
+	return converted, nil
+}
+
+// Get a new format Betalningsspecifikation closing record (TK09) counting the records of the section
+// Payments and credits are counted when approved
+func paymentClosingRecord(date string, rows []string) string {
+	counts := map[string]int{}
+	for _, row := range rows {
+		if (row[0:2] == TK_PAYMENT || row[0:2] == TK_CREDIT) && row[79] != '0' {
+			continue
+		}
+		counts[row[0:2]]++
+	}
+
+	return fmt.Sprintf("09%s9900%06d%012d%06d%012d%06d%012d%12s", date,
+		counts[TK_DEPOSIT], counts[TK_PAYMENT], counts[TK_WITHDRAWAL], counts[TK_CREDIT], counts[TK_REFUNDS], counts[TK_REFUND], "")
+}
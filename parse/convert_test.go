@@ -0,0 +1,157 @@
+package parse_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/parse"
+)
+
+func TestToNewFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		records func(sec *parse.AutogiroSection) (any, error)
+	}{
+		{"avvisade-old", func(sec *parse.AutogiroSection) (any, error) { return sec.Rejections() }},
+		{"andringslista-old", func(sec *parse.AutogiroSection) (any, error) { return sec.Changes() }},
+		{"medgivandeavi-old", func(sec *parse.AutogiroSection) (any, error) { return sec.MandateNotices() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agFile := readAutogiroFile(t, tt.name)
+			converted := convertAndParse(t, agFile)
+
+			sec := &converted.Sections[0]
+			if sec.SectionType.Code != newFormat(tt.name) {
+				t.Errorf("section type = %s, expected %s", sec.SectionType.Code, newFormat(tt.name))
+			}
+
+			oldRecords, err := tt.records(&agFile.Sections[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			newRecords, err := tt.records(sec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(oldRecords, newRecords) {
+				t.Errorf("records = %+v, expected %+v", newRecords, oldRecords)
+			}
+		})
+	}
+}
+
+func TestToNewFormatFields(t *testing.T) {
+	// The values are read from the old format fixtures by hand, a wrong column would change them
+	rejections, err := convertAndParse(t, readAutogiroFile(t, "avvisade-old")).Sections[0].Rejections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRejections := []parse.RejectedRecord{
+		{Row: 2, Code: "82", PaymentDate: "20160726", PeriodCode: "0", PayerNumber: "0000000000000101", Amount: 50000, Comment: parse.RejectionComments.Lookup("01")},
+		{Row: 6, Code: "32", PaymentDate: "20160725", PeriodCode: "0", PayerNumber: "0000000000000105", Amount: 13000, Comment: parse.RejectionComments.Lookup("01")},
+	}
+	if len(rejections) != 5 || rejections[0] != expectedRejections[0] || rejections[4] != expectedRejections[1] {
+		t.Errorf("rejections = %+v", rejections)
+	}
+
+	changes, err := convertAndParse(t, readAutogiroFile(t, "andringslista-old")).Sections[0].Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedChange := parse.ChangeRecord{Row: 2, Code: "25", PaymentDate: "20160719", PayerNumber: "0000000000002102", PaymentCode: "82", Amount: 10000, Reference: "0000000000000000", Comment: parse.ChangeComments.Lookup("12")}
+	if len(changes) != 2 || changes[0] != expectedChange || changes[1].PayerNumber != "0000000000002103" || changes[1].Amount != 15000 || changes[1].Reference != "REFERENS00000000" {
+		t.Errorf("changes = %+v", changes)
+	}
+
+	notices, err := convertAndParse(t, readAutogiroFile(t, "medgivandeavi-old")).Sections[0].MandateNotices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedNotices := []parse.MandateRecord{
+		{Row: 2, Bankgiro: "0009912346", PayerNumber: "0000000000023344", Clearing: "3300", Account: "121212120000", IdentityNumber: "191212121212", Information: parse.MandateInformation.Lookup("04"), Comment: parse.MandateComments.Lookup("10"), Date: "20160722"},
+		{Row: 5, Bankgiro: "0009912346", PayerNumber: "0000000000052244", Clearing: "7001", Account: "000001234567", IdentityNumber: "194608172222", Information: parse.MandateInformation.Lookup("42"), Comment: parse.MandateComments.Lookup("32"), Date: "20160722"},
+	}
+	if len(notices) != 9 || notices[0] != expectedNotices[0] || notices[3] != expectedNotices[1] {
+		t.Errorf("mandate notices = %+v", notices)
+	}
+
+	spec, err := convertAndParse(t, readAutogiroFile(t, "betalningsspec-old")).PaymentSpecification()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedPayment := parse.PaymentRecord{Row: 3, Code: "82", PaymentDate: "20160725", PeriodCode: "1", Renewals: "004", PayerNumber: "0000000000034451", Amount: 75000, Bankgiro: "0009912346", Reference: "FAKT 12345678", Result: parse.PaymentResults.Lookup("1")}
+	if spec.Payments[0] != expectedPayment {
+		t.Errorf("first payment = %+v, expected %+v", spec.Payments[0], expectedPayment)
+	}
+}
+
+func TestToNewFormatPaymentSpecification(t *testing.T) {
+	agFile := readAutogiroFile(t, "betalningsspec-old")
+	converted := convertAndParse(t, agFile)
+
+	spec, err := converted.PaymentSpecification()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Blank payment results are approved in the old format
+	expected := []parse.DepositRecord{
+		{Row: 2, Code: "15", Account: "00000000000000000000000000000000000", PaymentDate: "20160725", SerialNumber: "00001", Amount: 25000 + 2500 + 1500, Count: 3},
+		{Row: 9, Code: "16", Account: "00000000000000000000000000000000000", PaymentDate: "20160725", SerialNumber: "00001", Amount: 125000 + 50000, Count: 2},
+	}
+	if !reflect.DeepEqual(spec.Deposits, expected) {
+		t.Errorf("deposits = %+v, expected %+v", spec.Deposits, expected)
+	}
+
+	results := ""
+	for _, p := range spec.Payments {
+		results += p.Result.Code
+	}
+	if results != "10002900129" {
+		t.Errorf("payment results = %s", results)
+	}
+
+	closing := converted.Sections[0].Rows[len(converted.Sections[0].Rows)-1]
+	if closing != "09201607259900000001000000000003000001000000000002000000000000000000            " {
+		t.Errorf("closing record = %q", closing)
+	}
+}
+
+func TestToNewFormatUnchanged(t *testing.T) {
+	agFile := readAutogiroFile(t, "avvisade-new")
+
+	converted, err := agFile.ToNewFormat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(converted.Sections, agFile.Sections) {
+		t.Error("a new format section was changed")
+	}
+}
+
+// Convert the file and parse the new format text again
+func convertAndParse(t *testing.T, agFile *parse.AutogiroFile) *parse.AutogiroFile {
+	t.Helper()
+
+	converted, err := agFile.ToNewFormat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reparsed := &parse.AutogiroFile{}
+	if err := reparsed.ParseFile(converted.Text()); err != nil {
+		t.Fatal(err)
+	}
+	for _, sec := range reparsed.Sections {
+		if len(sec.Errors) > 0 {
+			t.Errorf("%s: %v", sec.SectionType.Code, sec.Errors)
+		}
+	}
+
+	return reparsed
+}
+
+func newFormat(code string) string {
+	return code[:len(code)-len("old")] + "new"
+}
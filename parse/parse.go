@@ -167,18 +167,34 @@ type AutogiroFile struct {
 	Sections        []AutogiroSection
 }
 
-func (sec *AutogiroSection) SetStart(line string) error {
+// Identify the section type from a TK01 opening record
+// The record may be UTF-8 or ISO-8859-1, as read by the sealer
+func IdentifySectionType(line string) (SectionType, error) {
 	for _, sectionType := range SectionTypes {
-		substr := line[sectionType.Tk01Start:sectionType.Tk01End]
-		if substr == sectionType.Match {
-			sec.StartFound = true
-			sec.Rows = append(sec.Rows, line)
-			sec.SectionType = sectionType
-			return nil
+		if len(line) >= sectionType.Tk01End && line[sectionType.Tk01Start:sectionType.Tk01End] == sectionType.Match {
+			return sectionType, nil
+		}
+
+		isoMatch := tools.StringEnsureIso(sectionType.Match)
+		if isoMatch != sectionType.Match && strings.HasPrefix(line[min(sectionType.Tk01Start, len(line)):], isoMatch) {
+			return sectionType, nil
 		}
 	}
 
-	return fmt.Errorf("no matching section type found")
+	return SectionType{}, fmt.Errorf("no matching section type found")
+}
+
+func (sec *AutogiroSection) SetStart(line string) error {
+	sectionType, err := IdentifySectionType(line)
+	if err != nil {
+		return err
+	}
+
+	sec.StartFound = true
+	sec.Rows = append(sec.Rows, line)
+	sec.SectionType = sectionType
+
+	return nil
 }
 
 func (sec *AutogiroSection) SetEnd(line string, lookaheadRow string) error {
@@ -215,6 +231,51 @@ func (sec *AutogiroSection) GetCustomerNumber() string {
 	return sec.Rows[0][sec.SectionType.CustomerNumber[0]:sec.SectionType.CustomerNumber[1]]
 }
 
+// Find the first opening record in the data and identify its section type
+// The HMAC header and blank rows before the opening record are skipped
+func FindOpeningRecord(data string) (sectionType SectionType, row string, err error) {
+	for _, row := range strings.Split(tools.EnsureCrlfString(data), "\r\n") {
+		if strings.Trim(row, " \t") == "" || strings.HasPrefix(row, HMAC_HEADER) {
+			continue
+		}
+
+		if !strings.HasPrefix(row, SECTION_START) && !strings.HasPrefix(row, SECTION_START_IBANK) {
+			return SectionType{}, "", fmt.Errorf("no section start found where there should be one")
+		}
+
+		sectionType, err := IdentifySectionType(row)
+		if err != nil {
+			return SectionType{}, "", err
+		}
+
+		if sectionType.Code == "invalid" {
+			return SectionType{}, "", fmt.Errorf("unknown opening record: %s", row)
+		}
+
+		return sectionType, row, nil
+	}
+
+	return SectionType{}, "", fmt.Errorf("no opening record found")
+}
+
+// Get the customer number and account (bankgiro) number from the first opening record in the data
+// The HMAC header and blank rows before the opening record are skipped
+func OpeningNumbers(data string) (customerNumber string, accountNumber string, err error) {
+	sectionType, row, err := FindOpeningRecord(data)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(row) < sectionType.CustomerNumber[1] || len(row) < sectionType.AccountNumber[1] {
+		return "", "", fmt.Errorf("opening record too short: %d", len(row))
+	}
+
+	customerNumber = row[sectionType.CustomerNumber[0]:sectionType.CustomerNumber[1]]
+	accountNumber = row[sectionType.AccountNumber[0]:sectionType.AccountNumber[1]]
+
+	return customerNumber, accountNumber, nil
+}
+
 func (sec *AutogiroSection) GetUtf8Bytes() []byte {
 	return []byte(strings.Join(sec.Rows, "\r\n"))
 }
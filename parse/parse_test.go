@@ -63,3 +63,59 @@ func TestAgainstExpected(t *testing.T) {
 	}
 
 }
+
+func TestOpeningNumbers(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           string
+		customerNumber string
+		accountNumber  string
+		wantErr        bool
+	}{
+		{
+			name:           "Outgoing File",
+			data:           "0120240416AUTOGIRO                                            0069240009925256  \r\n82202404220    000000000020790200000008000000099252560040106553200145           ",
+			customerNumber: "006924",
+			accountNumber:  "0009925256",
+		},
+		{
+			name:           "Sealed File",
+			data:           "00240429HMAC                                                                    \r\n0120240416AUTOGIRO                                            0069240009925256  ",
+			customerNumber: "006924",
+			accountNumber:  "0009925256",
+		},
+		{
+			name:           "New Format",
+			data:           "01AUTOGIRO              20160725112931972673BET. SPEC & STOPP TK4711170009912346\r\n",
+			customerNumber: "471117",
+			accountNumber:  "0009912346",
+		},
+		{
+			name:           "ISO-8859-1",
+			data:           "01AUTOGIRO              20160714            MAKULERING/\xC4NDRING  4711170009912346\r\n",
+			customerNumber: "471117",
+			accountNumber:  "0009912346",
+		},
+		{
+			name:    "Short Opening Record",
+			data:    "01AUTOGIRO\r\n",
+			wantErr: true,
+		},
+		{
+			name:    "No Opening Record",
+			data:    "82202404220    000000000020790200000008000000099252560040106553200145           ",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			customerNumber, accountNumber, err := parse.OpeningNumbers(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpeningNumbers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if customerNumber != tt.customerNumber || accountNumber != tt.accountNumber {
+				t.Errorf("OpeningNumbers() = %s, %s, want %s, %s", customerNumber, accountNumber, tt.customerNumber, tt.accountNumber)
+			}
+		})
+	}
+}
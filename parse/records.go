@@ -0,0 +1,243 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hoglandets-it/go-bankgiro/fixedwidth"
+	"github.com/hoglandets-it/go-bankgiro/money"
+)
+
+// Record codes of a Betalningsspecifikation
+const (
+	TK_DEPOSIT    = "15"
+	TK_WITHDRAWAL = "16"
+	TK_REFUNDS    = "17"
+	TK_PAYMENT    = "82"
+	TK_CREDIT     = "32"
+	TK_REFUND     = "77"
+)
+
+// A deposit, withdrawal or refund summary record (TK15/TK16/TK17), summarizing the payments (TK82), credits (TK32) or refunds (TK77) that follow
+// Amount: the approved amount
+type DepositRecord struct {
+	Row          int          `json:"row"`
+	Code         string       `json:"code" bg:"pos=1,len=2"`
+	Account      string       `json:"account" bg:"pos=3,len=35,type=num"`
+	PaymentDate  string       `json:"paymentDate" bg:"pos=38,len=8,type=date"`
+	SerialNumber string       `json:"serialNumber" bg:"pos=46,len=5,type=num"`
+	Amount       money.Amount `json:"amount" bg:"pos=51,len=18,type=amount"`
+	Count        int          `json:"count" bg:"pos=72,len=8,type=num"`
+}
+
+// A payment (TK82) or credit (TK32) record
+// PeriodCode/Renewals: set for self-renewing payments
+// Result: the payment result code, 0 when the payment was made
+type PaymentRecord struct {
+	Row         int          `json:"row"`
+	Code        string       `json:"code" bg:"pos=1,len=2"`
+	PaymentDate string       `json:"paymentDate" bg:"pos=3,len=8,type=date"`
+	PeriodCode  string       `json:"periodCode" bg:"pos=11,len=1"`
+	Renewals    string       `json:"renewals" bg:"pos=12,len=3"`
+	PayerNumber string       `json:"payerNumber" bg:"pos=16,len=16,type=num"`
+	Amount      money.Amount `json:"amount" bg:"pos=32,len=12,type=amount"`
+	Bankgiro    string       `json:"bankgiro" bg:"pos=44,len=10,type=num"`
+	Reference   string       `json:"reference" bg:"pos=54,len=16"`
+	Result      Code         `json:"result" bg:"pos=80,len=1,table=payment-result"`
+}
+
+// A refund record (TK77), a payment returned to the payer
+// RefundDate: the date the payment was returned
+// Reason: the refund reason code
+type RefundRecord struct {
+	Row         int          `json:"row"`
+	_           string       `bg:"pos=1,len=2,value=77"`
+	PaymentDate string       `json:"paymentDate" bg:"pos=3,len=8,type=date"`
+	PeriodCode  string       `json:"periodCode" bg:"pos=11,len=1"`
+	Renewals    string       `json:"renewals" bg:"pos=12,len=3"`
+	PayerNumber string       `json:"payerNumber" bg:"pos=16,len=16,type=num"`
+	Amount      money.Amount `json:"amount" bg:"pos=32,len=12,type=amount"`
+	Bankgiro    string       `json:"bankgiro" bg:"pos=44,len=10,type=num"`
+	Reference   string       `json:"reference" bg:"pos=54,len=16"`
+	RefundDate  string       `json:"refundDate" bg:"pos=70,len=8,type=date"`
+	Reason      Code         `json:"reason" bg:"pos=78,len=2,table=refund-reason"`
+}
+
+// A rejected payment (TK82) or credit (TK32) in Avvisade betalningsuppdrag
+// Comment: why the payment was rejected
+type RejectedRecord struct {
+	Row         int          `json:"row"`
+	Code        string       `json:"code" bg:"pos=1,len=2"`
+	PaymentDate string       `json:"paymentDate" bg:"pos=3,len=8,type=date"`
+	PeriodCode  string       `json:"periodCode" bg:"pos=11,len=1"`
+	Renewals    string       `json:"renewals" bg:"pos=12,len=3"`
+	PayerNumber string       `json:"payerNumber" bg:"pos=15,len=16,type=num"`
+	Amount      money.Amount `json:"amount" bg:"pos=31,len=12,type=amount"`
+	Reference   string       `json:"reference" bg:"pos=43,len=16"`
+	Comment     Code         `json:"comment" bg:"pos=59,len=2,table=rejection-comment"`
+}
+
+// A cancelled or changed payment (TK03, TK11, TK21-29) in a Makulerings-/ändringslista
+// PaymentCode: 82 for a payment, 32 for a credit
+// NewPaymentDate: set when the payment date was changed
+// Comment: why the payment was cancelled or changed
+type ChangeRecord struct {
+	Row            int          `json:"row"`
+	Code           string       `json:"code" bg:"pos=1,len=2"`
+	PaymentDate    string       `json:"paymentDate" bg:"pos=3,len=8,type=date"`
+	PayerNumber    string       `json:"payerNumber" bg:"pos=11,len=16,type=num"`
+	PaymentCode    string       `json:"paymentCode" bg:"pos=27,len=2"`
+	Amount         money.Amount `json:"amount" bg:"pos=29,len=12,type=amount"`
+	Reference      string       `json:"reference" bg:"pos=41,len=16"`
+	NewPaymentDate string       `json:"newPaymentDate" bg:"pos=57,len=8,type=date,pad=zero"`
+	Comment        Code         `json:"comment" bg:"pos=73,len=2,table=change-comment"`
+}
+
+// A mandate notice (TK73) in a Medgivandeavisering
+// Information: what happened to the mandate, Comment: why
+type MandateRecord struct {
+	Row            int    `json:"row"`
+	_              string `bg:"pos=1,len=2,value=73"`
+	Bankgiro       string `json:"bankgiro" bg:"pos=3,len=10,type=num"`
+	PayerNumber    string `json:"payerNumber" bg:"pos=13,len=16,type=num"`
+	Clearing       string `json:"clearing" bg:"pos=29,len=4,type=num"`
+	Account        string `json:"account" bg:"pos=33,len=12,type=num"`
+	IdentityNumber string `json:"identityNumber" bg:"pos=45,len=12,type=num"`
+	Information    Code   `json:"information" bg:"pos=62,len=2,table=mandate-information"`
+	Comment        Code   `json:"comment" bg:"pos=64,len=2,table=mandate-comment"`
+	Date           string `json:"date" bg:"pos=66,len=8,type=date"`
+}
+
+// The typed records of one or more Betalningsspecifikation sections
+// Text fields are converted to UTF-8
+type PaymentSpecification struct {
+	Deposits []DepositRecord `json:"deposits"`
+	Payments []PaymentRecord `json:"payments"`
+	Refunds  []RefundRecord  `json:"refunds"`
+}
+
+// Check if the payment was made
+func (p PaymentRecord) Approved() bool {
+	return p.Result.Code == "0"
+}
+
+// Get the typed records of all Betalningsspecifikation sections in the file
+func (file *AutogiroFile) PaymentSpecification() (*PaymentSpecification, error) {
+	spec := &PaymentSpecification{}
+	found := false
+
+	for i := range file.Sections {
+		sec := &file.Sections[i]
+		if !strings.HasPrefix(sec.SectionType.Code, "betalningsspec") {
+			continue
+		}
+		found = true
+
+		secSpec, err := sec.PaymentSpecification()
+		if err != nil {
+			return nil, err
+		}
+
+		spec.Deposits = append(spec.Deposits, secSpec.Deposits...)
+		spec.Payments = append(spec.Payments, secSpec.Payments...)
+		spec.Refunds = append(spec.Refunds, secSpec.Refunds...)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no Betalningsspecifikation found in the file")
+	}
+
+	return spec, nil
+}
+
+// Get the typed records of a Betalningsspecifikation section
+func (sec *AutogiroSection) PaymentSpecification() (*PaymentSpecification, error) {
+	spec := &PaymentSpecification{}
+	err := sec.records("betalningsspec", "Betalningsspecifikation", func(row int, tk string, line string) error {
+		switch tk {
+		case TK_DEPOSIT, TK_WITHDRAWAL, TK_REFUNDS:
+			record := DepositRecord{Row: row}
+			spec.Deposits = append(spec.Deposits, record)
+			return fixedwidth.Unmarshal(line, &spec.Deposits[len(spec.Deposits)-1])
+		case TK_PAYMENT, TK_CREDIT:
+			record := PaymentRecord{Row: row}
+			spec.Payments = append(spec.Payments, record)
+			return fixedwidth.Unmarshal(line, &spec.Payments[len(spec.Payments)-1])
+		case TK_REFUND:
+			record := RefundRecord{Row: row}
+			spec.Refunds = append(spec.Refunds, record)
+			return fixedwidth.Unmarshal(line, &spec.Refunds[len(spec.Refunds)-1])
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// Get the rejected payments of an Avvisade betalningsuppdrag section
+func (sec *AutogiroSection) Rejections() ([]RejectedRecord, error) {
+	records := []RejectedRecord{}
+	err := sec.records("avvisade", "Avvisade betalningsuppdrag", func(row int, tk string, line string) error {
+		if tk != TK_PAYMENT && tk != TK_CREDIT {
+			return nil
+		}
+
+		records = append(records, RejectedRecord{Row: row})
+		return fixedwidth.Unmarshal(line, &records[len(records)-1])
+	})
+
+	return records, err
+}
+
+// Get the cancelled and changed payments of a Makulerings-/ändringslista section
+func (sec *AutogiroSection) Changes() ([]ChangeRecord, error) {
+	records := []ChangeRecord{}
+	err := sec.records("andringslista", "Makulerings-/ändringslista", func(row int, tk string, line string) error {
+		if tk != "03" && tk != "11" && (tk < "21" || tk > "29") {
+			return nil
+		}
+
+		records = append(records, ChangeRecord{Row: row})
+		return fixedwidth.Unmarshal(line, &records[len(records)-1])
+	})
+
+	return records, err
+}
+
+// Get the mandate notices of a Medgivandeavisering section
+func (sec *AutogiroSection) MandateNotices() ([]MandateRecord, error) {
+	records := []MandateRecord{}
+	err := sec.records("medgivandeavi", "Medgivandeavisering", func(row int, tk string, line string) error {
+		if tk != "73" {
+			return nil
+		}
+
+		records = append(records, MandateRecord{Row: row})
+		return fixedwidth.Unmarshal(line, &records[len(records)-1])
+	})
+
+	return records, err
+}
+
+// Read the rows of a section of the given type, numbered from 1 with the opening record being row 1
+func (sec *AutogiroSection) records(code string, name string, fn func(row int, tk string, line string) error) error {
+	if !strings.HasPrefix(sec.SectionType.Code, code) {
+		return fmt.Errorf("section is a %s, not a %s", sec.SectionType.Name, name)
+	}
+
+	for i, line := range sec.Rows {
+		if len(line) < 2 {
+			continue
+		}
+
+		if err := fn(i+1, line[0:2], line); err != nil {
+			return fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
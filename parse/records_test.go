@@ -0,0 +1,192 @@
+package parse_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/fixedwidth"
+	"github.com/hoglandets-it/go-bankgiro/money"
+	"github.com/hoglandets-it/go-bankgiro/parse"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+)
+
+func readAutogiroFile(t *testing.T, name string) *parse.AutogiroFile {
+	t.Helper()
+
+	content, err := os.ReadFile("../tests/normalization/" + name + ".txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isoContent, err := tools.BytesToIsoString(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agFile := &parse.AutogiroFile{}
+	if err := agFile.ParseFile(isoContent); err != nil {
+		t.Fatal(err)
+	}
+
+	return agFile
+}
+
+func TestPaymentSpecification(t *testing.T) {
+	spec, err := readAutogiroFile(t, "betalningsspec-new").PaymentSpecification()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(spec.Deposits) != 4 || len(spec.Payments) != 12 || len(spec.Refunds) != 2 {
+		t.Fatalf("PaymentSpecification() = %d deposits, %d payments, %d refunds", len(spec.Deposits), len(spec.Payments), len(spec.Refunds))
+	}
+
+	deposit := spec.Deposits[0]
+	expectedDeposit := parse.DepositRecord{Row: 2, Code: "15", Account: "00000000000000000008901003232323232", PaymentDate: "20160725", SerialNumber: "00001", Amount: 1500000, Count: 5}
+	if deposit != expectedDeposit {
+		t.Errorf("deposit = %+v, expected %+v", deposit, expectedDeposit)
+	}
+
+	payment := spec.Payments[1]
+	expectedPayment := parse.PaymentRecord{Row: 4, Code: "82", PaymentDate: "20160725", PeriodCode: "1", Renewals: "006", PayerNumber: "0000000000000102", Amount: 300000, Bankgiro: "0009912346", Reference: "0000000FAKTNR156", Result: parse.PaymentResults.Lookup("0")}
+	if payment != expectedPayment {
+		t.Errorf("payment = %+v, expected %+v", payment, expectedPayment)
+	}
+
+	var approved money.Amount
+	for _, p := range spec.Payments {
+		if p.Code == parse.TK_PAYMENT && p.Approved() {
+			approved += p.Amount
+		}
+	}
+	if approved != deposit.Amount {
+		t.Errorf("approved payments = %d, the deposit is %d", approved, deposit.Amount)
+	}
+
+	refund := spec.Refunds[0]
+	if refund.PayerNumber != "0000000000000114" || refund.Amount != 20000 || refund.RefundDate != "20091110" || refund.Reason.Code != "02" {
+		t.Errorf("refund = %+v", refund)
+	}
+
+	if _, err := readAutogiroFile(t, "medgivandeavi-new").PaymentSpecification(); err == nil {
+		t.Error("expected an error for a file without a Betalningsspecifikation")
+	}
+}
+
+func TestRejections(t *testing.T) {
+	for _, name := range []string{"avvisade-new", "avvisade-old"} {
+		t.Run(name, func(t *testing.T) {
+			sec := &readAutogiroFile(t, name).Sections[0]
+			rejections, err := sec.Rejections()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rejections) == 0 {
+				t.Fatal("Rejections() returned no records")
+			}
+
+			for _, r := range rejections {
+				if r.Comment.Code == "" || !r.Comment.Known() {
+					t.Errorf("row %d: comment %+v is not a known code", r.Row, r.Comment)
+				}
+			}
+		})
+	}
+
+	rejections, err := readAutogiroFile(t, "avvisade-new").Sections[0].Rejections()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := parse.RejectedRecord{Row: 2, Code: "82", PaymentDate: "20160725", PeriodCode: "5", Renewals: "006", PayerNumber: "0000000000003333", Amount: 7500, Reference: "RIDLEKTION", Comment: parse.RejectionComments.Lookup("02")}
+	if rejections[0] != expected {
+		t.Errorf("rejection = %+v, expected %+v", rejections[0], expected)
+	}
+	if rejections[0].Comment.English != "The mandate is cancelled" {
+		t.Errorf("comment = %+v", rejections[0].Comment)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	changes, err := readAutogiroFile(t, "andringslista-new").Sections[0].Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 5 {
+		t.Fatalf("Changes() = %d records, expected 5", len(changes))
+	}
+
+	expected := parse.ChangeRecord{Row: 2, Code: "11", PaymentDate: "20160718", PayerNumber: "0000000000000102", PaymentCode: "82", Amount: 10000, Reference: "REFERENS00000000", Comment: parse.ChangeComments.Lookup("12")}
+	if changes[0] != expected {
+		t.Errorf("change = %+v, expected %+v", changes[0], expected)
+	}
+}
+
+func TestMandateNotices(t *testing.T) {
+	notices, err := readAutogiroFile(t, "medgivandeavi-new").Sections[0].MandateNotices()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(notices) != 9 {
+		t.Fatalf("MandateNotices() = %d records, expected 9", len(notices))
+	}
+
+	tests := []struct {
+		payerNumber string
+		information string
+		comment     string
+	}{
+		{"0000000000000103", "04", "32"},
+		{"0000000002222101", "03", "33"},
+		{"0000000002222101", "46", "02"},
+		{"0000000000000102", "43", "07"},
+	}
+	for _, tt := range tests {
+		found := false
+		for _, n := range notices {
+			if n.PayerNumber == tt.payerNumber && n.Information.Code == tt.information && n.Comment.Code == tt.comment {
+				found = n.Information.Known() && n.Comment.Known() && n.Date == "20160725"
+			}
+		}
+		if !found {
+			t.Errorf("no notice for payer %s with information %s and comment %s", tt.payerNumber, tt.information, tt.comment)
+		}
+	}
+
+	if _, err := readAutogiroFile(t, "avvisade-new").Sections[0].MandateNotices(); err == nil {
+		t.Error("expected an error for a section that is not a Medgivandeavisering")
+	}
+}
+
+func TestRecordsMarshal(t *testing.T) {
+	file := readAutogiroFile(t, "betalningsspec-new")
+	spec, err := file.Sections[0].PaymentSpecification()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := map[int]any{}
+	for _, record := range spec.Deposits {
+		records[record.Row] = record
+	}
+	for _, record := range spec.Payments {
+		records[record.Row] = record
+	}
+	for _, record := range spec.Refunds {
+		records[record.Row] = record
+	}
+
+	for row, record := range records {
+		line, err := fixedwidth.Marshal(record)
+		if err != nil {
+			t.Fatalf("row %d: %v", row, err)
+		}
+		expected := file.Sections[0].Rows[row-1]
+		if strings.TrimRight(line, " ") != strings.TrimRight(expected, " ") {
+			t.Errorf("row %d: Marshal() = %q, expected %q", row, line, expected)
+		}
+	}
+}
@@ -0,0 +1,1190 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.1
+// 	protoc        (unknown)
+// source: bankgiro/v1/bankgiro.proto
+
+package bankgirov1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SealRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The file to seal, ISO-8859-1 or UTF-8
+	Content []byte `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	// The seal date as YYMMDD, default is today
+	SealDate string `protobuf:"bytes,2,opt,name=seal_date,json=sealDate,proto3" json:"seal_date,omitempty"`
+}
+
+func (x *SealRequest) Reset() {
+	*x = SealRequest{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SealRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SealRequest) ProtoMessage() {}
+
+func (x *SealRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SealRequest.ProtoReflect.Descriptor instead.
+func (*SealRequest) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SealRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *SealRequest) GetSealDate() string {
+	if x != nil {
+		return x.SealDate
+	}
+	return ""
+}
+
+type SealResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The sealed file, ISO-8859-1
+	Content  []byte `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	SealDate string `protobuf:"bytes,2,opt,name=seal_date,json=sealDate,proto3" json:"seal_date,omitempty"`
+	Kvv      string `protobuf:"bytes,3,opt,name=kvv,proto3" json:"kvv,omitempty"`
+	Mac      string `protobuf:"bytes,4,opt,name=mac,proto3" json:"mac,omitempty"`
+}
+
+func (x *SealResponse) Reset() {
+	*x = SealResponse{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SealResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SealResponse) ProtoMessage() {}
+
+func (x *SealResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SealResponse.ProtoReflect.Descriptor instead.
+func (*SealResponse) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SealResponse) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *SealResponse) GetSealDate() string {
+	if x != nil {
+		return x.SealDate
+	}
+	return ""
+}
+
+func (x *SealResponse) GetKvv() string {
+	if x != nil {
+		return x.Kvv
+	}
+	return ""
+}
+
+func (x *SealResponse) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
+}
+
+type SealStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Chunk []byte `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	// The seal date as YYMMDD, only read from the first message
+	SealDate string `protobuf:"bytes,2,opt,name=seal_date,json=sealDate,proto3" json:"seal_date,omitempty"`
+}
+
+func (x *SealStreamRequest) Reset() {
+	*x = SealStreamRequest{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SealStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SealStreamRequest) ProtoMessage() {}
+
+func (x *SealStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SealStreamRequest.ProtoReflect.Descriptor instead.
+func (*SealStreamRequest) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SealStreamRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *SealStreamRequest) GetSealDate() string {
+	if x != nil {
+		return x.SealDate
+	}
+	return ""
+}
+
+type SealStreamResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Chunk []byte `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	// The seal details are only set on the first message
+	SealDate string `protobuf:"bytes,2,opt,name=seal_date,json=sealDate,proto3" json:"seal_date,omitempty"`
+	Kvv      string `protobuf:"bytes,3,opt,name=kvv,proto3" json:"kvv,omitempty"`
+	Mac      string `protobuf:"bytes,4,opt,name=mac,proto3" json:"mac,omitempty"`
+}
+
+func (x *SealStreamResponse) Reset() {
+	*x = SealStreamResponse{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SealStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SealStreamResponse) ProtoMessage() {}
+
+func (x *SealStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SealStreamResponse.ProtoReflect.Descriptor instead.
+func (*SealStreamResponse) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SealStreamResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *SealStreamResponse) GetSealDate() string {
+	if x != nil {
+		return x.SealDate
+	}
+	return ""
+}
+
+func (x *SealStreamResponse) GetKvv() string {
+	if x != nil {
+		return x.Kvv
+	}
+	return ""
+}
+
+func (x *SealStreamResponse) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
+}
+
+type VerifyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Content []byte `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+}
+
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyRequest) ProtoMessage() {}
+
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VerifyRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type VerifyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Verification *Verification `protobuf:"bytes,1,opt,name=verification,proto3" json:"verification,omitempty"`
+}
+
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyResponse) ProtoMessage() {}
+
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *VerifyResponse) GetVerification() *Verification {
+	if x != nil {
+		return x.Verification
+	}
+	return nil
+}
+
+type VerifyStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Chunk []byte `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+}
+
+func (x *VerifyStreamRequest) Reset() {
+	*x = VerifyStreamRequest{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyStreamRequest) ProtoMessage() {}
+
+func (x *VerifyStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyStreamRequest.ProtoReflect.Descriptor instead.
+func (*VerifyStreamRequest) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VerifyStreamRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type VerifyStreamResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Verification *Verification `protobuf:"bytes,1,opt,name=verification,proto3" json:"verification,omitempty"`
+}
+
+func (x *VerifyStreamResponse) Reset() {
+	*x = VerifyStreamResponse{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyStreamResponse) ProtoMessage() {}
+
+func (x *VerifyStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyStreamResponse.ProtoReflect.Descriptor instead.
+func (*VerifyStreamResponse) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VerifyStreamResponse) GetVerification() *Verification {
+	if x != nil {
+		return x.Verification
+	}
+	return nil
+}
+
+// The result of verifying a sealed file
+type Verification struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Valid       bool   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	KvvMatch    bool   `protobuf:"varint,2,opt,name=kvv_match,json=kvvMatch,proto3" json:"kvv_match,omitempty"`
+	MacMatch    bool   `protobuf:"varint,3,opt,name=mac_match,json=macMatch,proto3" json:"mac_match,omitempty"`
+	SealDate    string `protobuf:"bytes,4,opt,name=seal_date,json=sealDate,proto3" json:"seal_date,omitempty"`
+	Kvv         string `protobuf:"bytes,5,opt,name=kvv,proto3" json:"kvv,omitempty"`
+	ExpectedKvv string `protobuf:"bytes,6,opt,name=expected_kvv,json=expectedKvv,proto3" json:"expected_kvv,omitempty"`
+	Mac         string `protobuf:"bytes,7,opt,name=mac,proto3" json:"mac,omitempty"`
+	ExpectedMac string `protobuf:"bytes,8,opt,name=expected_mac,json=expectedMac,proto3" json:"expected_mac,omitempty"`
+}
+
+func (x *Verification) Reset() {
+	*x = Verification{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Verification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Verification) ProtoMessage() {}
+
+func (x *Verification) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Verification.ProtoReflect.Descriptor instead.
+func (*Verification) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Verification) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *Verification) GetKvvMatch() bool {
+	if x != nil {
+		return x.KvvMatch
+	}
+	return false
+}
+
+func (x *Verification) GetMacMatch() bool {
+	if x != nil {
+		return x.MacMatch
+	}
+	return false
+}
+
+func (x *Verification) GetSealDate() string {
+	if x != nil {
+		return x.SealDate
+	}
+	return ""
+}
+
+func (x *Verification) GetKvv() string {
+	if x != nil {
+		return x.Kvv
+	}
+	return ""
+}
+
+func (x *Verification) GetExpectedKvv() string {
+	if x != nil {
+		return x.ExpectedKvv
+	}
+	return ""
+}
+
+func (x *Verification) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
+}
+
+func (x *Verification) GetExpectedMac() string {
+	if x != nil {
+		return x.ExpectedMac
+	}
+	return ""
+}
+
+type ParseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Content []byte `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+}
+
+func (x *ParseRequest) Reset() {
+	*x = ParseRequest{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseRequest) ProtoMessage() {}
+
+func (x *ParseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseRequest.ProtoReflect.Descriptor instead.
+func (*ParseRequest) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ParseRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type ParseResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HmacHeader  bool       `protobuf:"varint,1,opt,name=hmac_header,json=hmacHeader,proto3" json:"hmac_header,omitempty"`
+	HmacTrailer bool       `protobuf:"varint,2,opt,name=hmac_trailer,json=hmacTrailer,proto3" json:"hmac_trailer,omitempty"`
+	Sections    []*Section `protobuf:"bytes,3,rep,name=sections,proto3" json:"sections,omitempty"`
+}
+
+func (x *ParseResponse) Reset() {
+	*x = ParseResponse{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseResponse) ProtoMessage() {}
+
+func (x *ParseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseResponse.ProtoReflect.Descriptor instead.
+func (*ParseResponse) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ParseResponse) GetHmacHeader() bool {
+	if x != nil {
+		return x.HmacHeader
+	}
+	return false
+}
+
+func (x *ParseResponse) GetHmacTrailer() bool {
+	if x != nil {
+		return x.HmacTrailer
+	}
+	return false
+}
+
+func (x *ParseResponse) GetSections() []*Section {
+	if x != nil {
+		return x.Sections
+	}
+	return nil
+}
+
+type ParseStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Chunk []byte `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+}
+
+func (x *ParseStreamRequest) Reset() {
+	*x = ParseStreamRequest{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseStreamRequest) ProtoMessage() {}
+
+func (x *ParseStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseStreamRequest.ProtoReflect.Descriptor instead.
+func (*ParseStreamRequest) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ParseStreamRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type ParseStreamResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Section *Section `protobuf:"bytes,1,opt,name=section,proto3" json:"section,omitempty"`
+}
+
+func (x *ParseStreamResponse) Reset() {
+	*x = ParseStreamResponse{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseStreamResponse) ProtoMessage() {}
+
+func (x *ParseStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseStreamResponse.ProtoReflect.Descriptor instead.
+func (*ParseStreamResponse) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ParseStreamResponse) GetSection() *Section {
+	if x != nil {
+		return x.Section
+	}
+	return nil
+}
+
+// A section of an Autogiro file, from the opening record to the end record
+type Section struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TypeCode       string   `protobuf:"bytes,1,opt,name=type_code,json=typeCode,proto3" json:"type_code,omitempty"`
+	TypeName       string   `protobuf:"bytes,2,opt,name=type_name,json=typeName,proto3" json:"type_name,omitempty"`
+	CustomerNumber string   `protobuf:"bytes,3,opt,name=customer_number,json=customerNumber,proto3" json:"customer_number,omitempty"`
+	Bankgiro       string   `protobuf:"bytes,4,opt,name=bankgiro,proto3" json:"bankgiro,omitempty"`
+	Rows           []string `protobuf:"bytes,5,rep,name=rows,proto3" json:"rows,omitempty"`
+	SectionSeal    string   `protobuf:"bytes,6,opt,name=section_seal,json=sectionSeal,proto3" json:"section_seal,omitempty"`
+	Errors         []string `protobuf:"bytes,7,rep,name=errors,proto3" json:"errors,omitempty"`
+}
+
+func (x *Section) Reset() {
+	*x = Section{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Section) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Section) ProtoMessage() {}
+
+func (x *Section) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Section.ProtoReflect.Descriptor instead.
+func (*Section) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Section) GetTypeCode() string {
+	if x != nil {
+		return x.TypeCode
+	}
+	return ""
+}
+
+func (x *Section) GetTypeName() string {
+	if x != nil {
+		return x.TypeName
+	}
+	return ""
+}
+
+func (x *Section) GetCustomerNumber() string {
+	if x != nil {
+		return x.CustomerNumber
+	}
+	return ""
+}
+
+func (x *Section) GetBankgiro() string {
+	if x != nil {
+		return x.Bankgiro
+	}
+	return ""
+}
+
+func (x *Section) GetRows() []string {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *Section) GetSectionSeal() string {
+	if x != nil {
+		return x.SectionSeal
+	}
+	return ""
+}
+
+func (x *Section) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type DetectRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Content []byte `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+}
+
+func (x *DetectRequest) Reset() {
+	*x = DetectRequest{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectRequest) ProtoMessage() {}
+
+func (x *DetectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectRequest.ProtoReflect.Descriptor instead.
+func (*DetectRequest) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DetectRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type DetectResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TypeCode       string `protobuf:"bytes,1,opt,name=type_code,json=typeCode,proto3" json:"type_code,omitempty"`
+	TypeName       string `protobuf:"bytes,2,opt,name=type_name,json=typeName,proto3" json:"type_name,omitempty"`
+	CustomerNumber string `protobuf:"bytes,3,opt,name=customer_number,json=customerNumber,proto3" json:"customer_number,omitempty"`
+	Bankgiro       string `protobuf:"bytes,4,opt,name=bankgiro,proto3" json:"bankgiro,omitempty"`
+	Sealed         bool   `protobuf:"varint,5,opt,name=sealed,proto3" json:"sealed,omitempty"`
+}
+
+func (x *DetectResponse) Reset() {
+	*x = DetectResponse{}
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectResponse) ProtoMessage() {}
+
+func (x *DetectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bankgiro_v1_bankgiro_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectResponse.ProtoReflect.Descriptor instead.
+func (*DetectResponse) Descriptor() ([]byte, []int) {
+	return file_bankgiro_v1_bankgiro_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DetectResponse) GetTypeCode() string {
+	if x != nil {
+		return x.TypeCode
+	}
+	return ""
+}
+
+func (x *DetectResponse) GetTypeName() string {
+	if x != nil {
+		return x.TypeName
+	}
+	return ""
+}
+
+func (x *DetectResponse) GetCustomerNumber() string {
+	if x != nil {
+		return x.CustomerNumber
+	}
+	return ""
+}
+
+func (x *DetectResponse) GetBankgiro() string {
+	if x != nil {
+		return x.Bankgiro
+	}
+	return ""
+}
+
+func (x *DetectResponse) GetSealed() bool {
+	if x != nil {
+		return x.Sealed
+	}
+	return false
+}
+
+var File_bankgiro_v1_bankgiro_proto protoreflect.FileDescriptor
+
+var file_bankgiro_v1_bankgiro_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x61,
+	0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x62, 0x61,
+	0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x22, 0x44, 0x0a, 0x0b, 0x53, 0x65, 0x61,
+	0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x61, 0x6c, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x61, 0x6c, 0x44, 0x61, 0x74, 0x65, 0x22,
+	0x69, 0x0a, 0x0c, 0x53, 0x65, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x61,
+	0x6c, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65,
+	0x61, 0x6c, 0x44, 0x61, 0x74, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x76, 0x76, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x76, 0x76, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6d, 0x61, 0x63, 0x22, 0x46, 0x0a, 0x11, 0x53, 0x65,
+	0x61, 0x6c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x63, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x61, 0x6c, 0x5f, 0x64, 0x61,
+	0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x61, 0x6c, 0x44, 0x61,
+	0x74, 0x65, 0x22, 0x6b, 0x0a, 0x12, 0x53, 0x65, 0x61, 0x6c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e,
+	0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x1b,
+	0x0a, 0x09, 0x73, 0x65, 0x61, 0x6c, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x73, 0x65, 0x61, 0x6c, 0x44, 0x61, 0x74, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x76, 0x76, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x76, 0x76, 0x12, 0x10, 0x0a,
+	0x03, 0x6d, 0x61, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6d, 0x61, 0x63, 0x22,
+	0x29, 0x0a, 0x0d, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x4f, 0x0a, 0x0e, 0x56, 0x65,
+	0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x0c,
+	0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x76,
+	0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x2b, 0x0a, 0x13, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x55, 0x0a, 0x14, 0x56, 0x65, 0x72, 0x69,
+	0x66, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x3d, 0x0a, 0x0c, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72,
+	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x0c, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22,
+	0xe5, 0x01, 0x0a, 0x0c, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6b, 0x76, 0x76, 0x5f, 0x6d, 0x61,
+	0x74, 0x63, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6b, 0x76, 0x76, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x63, 0x5f, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6d, 0x61, 0x63, 0x4d, 0x61, 0x74, 0x63, 0x68,
+	0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x61, 0x6c, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x61, 0x6c, 0x44, 0x61, 0x74, 0x65, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x76, 0x76, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x76, 0x76, 0x12,
+	0x21, 0x0a, 0x0c, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x6b, 0x76, 0x76, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x4b,
+	0x76, 0x76, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x61, 0x63, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6d, 0x61, 0x63, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x5f, 0x6d, 0x61, 0x63, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x78, 0x70, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x4d, 0x61, 0x63, 0x22, 0x28, 0x0a, 0x0c, 0x50, 0x61, 0x72, 0x73, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x22, 0x85, 0x01, 0x0a, 0x0d, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x68, 0x6d, 0x61, 0x63, 0x5f, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x68, 0x6d, 0x61, 0x63, 0x48, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x68, 0x6d, 0x61, 0x63, 0x5f, 0x74, 0x72, 0x61,
+	0x69, 0x6c, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x68, 0x6d, 0x61, 0x63,
+	0x54, 0x72, 0x61, 0x69, 0x6c, 0x65, 0x72, 0x12, 0x30, 0x0a, 0x08, 0x73, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x62, 0x61, 0x6e, 0x6b,
+	0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x08, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x2a, 0x0a, 0x12, 0x50, 0x61, 0x72,
+	0x73, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x63, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x45, 0x0a, 0x13, 0x50, 0x61, 0x72, 0x73, 0x65, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x07,
+	0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x07, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xd7, 0x01, 0x0a,
+	0x07, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x79, 0x70, 0x65,
+	0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x79, 0x70,
+	0x65, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x79, 0x70, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x5f, 0x6e,
+	0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x75, 0x73,
+	0x74, 0x6f, 0x6d, 0x65, 0x72, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x62,
+	0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62,
+	0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x18,
+	0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x73,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x65, 0x61, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x61, 0x6c, 0x12, 0x16,
+	0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0x29, 0x0a, 0x0d, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x22, 0xa7, 0x01, 0x0a, 0x0e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x63, 0x6f, 0x64,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x79, 0x70, 0x65, 0x43, 0x6f, 0x64,
+	0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x79, 0x70, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x27,
+	0x0a, 0x0f, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65,
+	0x72, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x61, 0x6e, 0x6b, 0x67,
+	0x69, 0x72, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x61, 0x6e, 0x6b, 0x67,
+	0x69, 0x72, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x61, 0x6c, 0x65, 0x64, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x65, 0x61, 0x6c, 0x65, 0x64, 0x32, 0x94, 0x04, 0x0a, 0x0f,
+	0x42, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x3b, 0x0a, 0x04, 0x53, 0x65, 0x61, 0x6c, 0x12, 0x18, 0x2e, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69,
+	0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x19, 0x2e, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x65, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x51, 0x0a, 0x0a,
+	0x53, 0x65, 0x61, 0x6c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x1e, 0x2e, 0x62, 0x61, 0x6e,
+	0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x6c, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x62, 0x61, 0x6e,
+	0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x6c, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x12,
+	0x41, 0x0a, 0x06, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x12, 0x1a, 0x2e, 0x62, 0x61, 0x6e, 0x6b,
+	0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f,
+	0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x55, 0x0a, 0x0c, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x12, 0x20, 0x2e, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e,
+	0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x12, 0x3e, 0x0a, 0x05, 0x50, 0x61, 0x72,
+	0x73, 0x65, 0x12, 0x19, 0x2e, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e,
+	0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x72, 0x73,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a, 0x0b, 0x50, 0x61, 0x72,
+	0x73, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x1f, 0x2e, 0x62, 0x61, 0x6e, 0x6b, 0x67,
+	0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x62, 0x61, 0x6e, 0x6b,
+	0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x12,
+	0x41, 0x0a, 0x06, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x12, 0x1a, 0x2e, 0x62, 0x61, 0x6e, 0x6b,
+	0x67, 0x69, 0x72, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x42, 0x43, 0x5a, 0x41, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x68, 0x6f, 0x67, 0x6c, 0x61, 0x6e, 0x64, 0x65, 0x74, 0x73, 0x2d, 0x69, 0x74, 0x2f, 0x67,
+	0x6f, 0x2d, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x62, 0x61, 0x6e, 0x6b, 0x67, 0x69, 0x72, 0x6f, 0x2f, 0x76, 0x31, 0x3b, 0x62, 0x61, 0x6e,
+	0x6b, 0x67, 0x69, 0x72, 0x6f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_bankgiro_v1_bankgiro_proto_rawDescOnce sync.Once
+	file_bankgiro_v1_bankgiro_proto_rawDescData = file_bankgiro_v1_bankgiro_proto_rawDesc
+)
+
+func file_bankgiro_v1_bankgiro_proto_rawDescGZIP() []byte {
+	file_bankgiro_v1_bankgiro_proto_rawDescOnce.Do(func() {
+		file_bankgiro_v1_bankgiro_proto_rawDescData = protoimpl.X.CompressGZIP(file_bankgiro_v1_bankgiro_proto_rawDescData)
+	})
+	return file_bankgiro_v1_bankgiro_proto_rawDescData
+}
+
+var file_bankgiro_v1_bankgiro_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_bankgiro_v1_bankgiro_proto_goTypes = []any{
+	(*SealRequest)(nil),          // 0: bankgiro.v1.SealRequest
+	(*SealResponse)(nil),         // 1: bankgiro.v1.SealResponse
+	(*SealStreamRequest)(nil),    // 2: bankgiro.v1.SealStreamRequest
+	(*SealStreamResponse)(nil),   // 3: bankgiro.v1.SealStreamResponse
+	(*VerifyRequest)(nil),        // 4: bankgiro.v1.VerifyRequest
+	(*VerifyResponse)(nil),       // 5: bankgiro.v1.VerifyResponse
+	(*VerifyStreamRequest)(nil),  // 6: bankgiro.v1.VerifyStreamRequest
+	(*VerifyStreamResponse)(nil), // 7: bankgiro.v1.VerifyStreamResponse
+	(*Verification)(nil),         // 8: bankgiro.v1.Verification
+	(*ParseRequest)(nil),         // 9: bankgiro.v1.ParseRequest
+	(*ParseResponse)(nil),        // 10: bankgiro.v1.ParseResponse
+	(*ParseStreamRequest)(nil),   // 11: bankgiro.v1.ParseStreamRequest
+	(*ParseStreamResponse)(nil),  // 12: bankgiro.v1.ParseStreamResponse
+	(*Section)(nil),              // 13: bankgiro.v1.Section
+	(*DetectRequest)(nil),        // 14: bankgiro.v1.DetectRequest
+	(*DetectResponse)(nil),       // 15: bankgiro.v1.DetectResponse
+}
+var file_bankgiro_v1_bankgiro_proto_depIdxs = []int32{
+	8,  // 0: bankgiro.v1.VerifyResponse.verification:type_name -> bankgiro.v1.Verification
+	8,  // 1: bankgiro.v1.VerifyStreamResponse.verification:type_name -> bankgiro.v1.Verification
+	13, // 2: bankgiro.v1.ParseResponse.sections:type_name -> bankgiro.v1.Section
+	13, // 3: bankgiro.v1.ParseStreamResponse.section:type_name -> bankgiro.v1.Section
+	0,  // 4: bankgiro.v1.BankgiroService.Seal:input_type -> bankgiro.v1.SealRequest
+	2,  // 5: bankgiro.v1.BankgiroService.SealStream:input_type -> bankgiro.v1.SealStreamRequest
+	4,  // 6: bankgiro.v1.BankgiroService.Verify:input_type -> bankgiro.v1.VerifyRequest
+	6,  // 7: bankgiro.v1.BankgiroService.VerifyStream:input_type -> bankgiro.v1.VerifyStreamRequest
+	9,  // 8: bankgiro.v1.BankgiroService.Parse:input_type -> bankgiro.v1.ParseRequest
+	11, // 9: bankgiro.v1.BankgiroService.ParseStream:input_type -> bankgiro.v1.ParseStreamRequest
+	14, // 10: bankgiro.v1.BankgiroService.Detect:input_type -> bankgiro.v1.DetectRequest
+	1,  // 11: bankgiro.v1.BankgiroService.Seal:output_type -> bankgiro.v1.SealResponse
+	3,  // 12: bankgiro.v1.BankgiroService.SealStream:output_type -> bankgiro.v1.SealStreamResponse
+	5,  // 13: bankgiro.v1.BankgiroService.Verify:output_type -> bankgiro.v1.VerifyResponse
+	7,  // 14: bankgiro.v1.BankgiroService.VerifyStream:output_type -> bankgiro.v1.VerifyStreamResponse
+	10, // 15: bankgiro.v1.BankgiroService.Parse:output_type -> bankgiro.v1.ParseResponse
+	12, // 16: bankgiro.v1.BankgiroService.ParseStream:output_type -> bankgiro.v1.ParseStreamResponse
+	15, // 17: bankgiro.v1.BankgiroService.Detect:output_type -> bankgiro.v1.DetectResponse
+	11, // [11:18] is the sub-list for method output_type
+	4,  // [4:11] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_bankgiro_v1_bankgiro_proto_init() }
+func file_bankgiro_v1_bankgiro_proto_init() {
+	if File_bankgiro_v1_bankgiro_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_bankgiro_v1_bankgiro_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_bankgiro_v1_bankgiro_proto_goTypes,
+		DependencyIndexes: file_bankgiro_v1_bankgiro_proto_depIdxs,
+		MessageInfos:      file_bankgiro_v1_bankgiro_proto_msgTypes,
+	}.Build()
+	File_bankgiro_v1_bankgiro_proto = out.File
+	file_bankgiro_v1_bankgiro_proto_rawDesc = nil
+	file_bankgiro_v1_bankgiro_proto_goTypes = nil
+	file_bankgiro_v1_bankgiro_proto_depIdxs = nil
+}
@@ -0,0 +1,457 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: bankgiro/v1/bankgiro.proto
+
+package bankgirov1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	BankgiroService_Seal_FullMethodName         = "/bankgiro.v1.BankgiroService/Seal"
+	BankgiroService_SealStream_FullMethodName   = "/bankgiro.v1.BankgiroService/SealStream"
+	BankgiroService_Verify_FullMethodName       = "/bankgiro.v1.BankgiroService/Verify"
+	BankgiroService_VerifyStream_FullMethodName = "/bankgiro.v1.BankgiroService/VerifyStream"
+	BankgiroService_Parse_FullMethodName        = "/bankgiro.v1.BankgiroService/Parse"
+	BankgiroService_ParseStream_FullMethodName  = "/bankgiro.v1.BankgiroService/ParseStream"
+	BankgiroService_Detect_FullMethodName       = "/bankgiro.v1.BankgiroService/Detect"
+)
+
+// BankgiroServiceClient is the client API for BankgiroService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Seals, verifies and parses Bankgiro files
+// The streaming variants send files in chunks, for files larger than the maximum message size
+type BankgiroServiceClient interface {
+	// Seal a file with an HMAC seal
+	Seal(ctx context.Context, in *SealRequest, opts ...grpc.CallOption) (*SealResponse, error)
+	// Seal a file sent in chunks, the sealed file is returned in chunks
+	SealStream(ctx context.Context, opts ...grpc.CallOption) (BankgiroService_SealStreamClient, error)
+	// Verify the HMAC seal of a sealed file
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	// Verify the HMAC seal of a sealed file sent in chunks
+	VerifyStream(ctx context.Context, opts ...grpc.CallOption) (BankgiroService_VerifyStreamClient, error)
+	// Parse an Autogiro file into its sections
+	Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error)
+	// Parse an Autogiro file sent in chunks, the sections are returned one by one
+	ParseStream(ctx context.Context, opts ...grpc.CallOption) (BankgiroService_ParseStreamClient, error)
+	// Detect the file type from the opening record and whether the file is sealed
+	Detect(ctx context.Context, in *DetectRequest, opts ...grpc.CallOption) (*DetectResponse, error)
+}
+
+type bankgiroServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBankgiroServiceClient(cc grpc.ClientConnInterface) BankgiroServiceClient {
+	return &bankgiroServiceClient{cc}
+}
+
+func (c *bankgiroServiceClient) Seal(ctx context.Context, in *SealRequest, opts ...grpc.CallOption) (*SealResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SealResponse)
+	err := c.cc.Invoke(ctx, BankgiroService_Seal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bankgiroServiceClient) SealStream(ctx context.Context, opts ...grpc.CallOption) (BankgiroService_SealStreamClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BankgiroService_ServiceDesc.Streams[0], BankgiroService_SealStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &bankgiroServiceSealStreamClient{ClientStream: stream}
+	return x, nil
+}
+
+type BankgiroService_SealStreamClient interface {
+	Send(*SealStreamRequest) error
+	Recv() (*SealStreamResponse, error)
+	grpc.ClientStream
+}
+
+type bankgiroServiceSealStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *bankgiroServiceSealStreamClient) Send(m *SealStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *bankgiroServiceSealStreamClient) Recv() (*SealStreamResponse, error) {
+	m := new(SealStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *bankgiroServiceClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, BankgiroService_Verify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bankgiroServiceClient) VerifyStream(ctx context.Context, opts ...grpc.CallOption) (BankgiroService_VerifyStreamClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BankgiroService_ServiceDesc.Streams[1], BankgiroService_VerifyStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &bankgiroServiceVerifyStreamClient{ClientStream: stream}
+	return x, nil
+}
+
+type BankgiroService_VerifyStreamClient interface {
+	Send(*VerifyStreamRequest) error
+	CloseAndRecv() (*VerifyStreamResponse, error)
+	grpc.ClientStream
+}
+
+type bankgiroServiceVerifyStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *bankgiroServiceVerifyStreamClient) Send(m *VerifyStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *bankgiroServiceVerifyStreamClient) CloseAndRecv() (*VerifyStreamResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(VerifyStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *bankgiroServiceClient) Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParseResponse)
+	err := c.cc.Invoke(ctx, BankgiroService_Parse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bankgiroServiceClient) ParseStream(ctx context.Context, opts ...grpc.CallOption) (BankgiroService_ParseStreamClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BankgiroService_ServiceDesc.Streams[2], BankgiroService_ParseStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &bankgiroServiceParseStreamClient{ClientStream: stream}
+	return x, nil
+}
+
+type BankgiroService_ParseStreamClient interface {
+	Send(*ParseStreamRequest) error
+	Recv() (*ParseStreamResponse, error)
+	grpc.ClientStream
+}
+
+type bankgiroServiceParseStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *bankgiroServiceParseStreamClient) Send(m *ParseStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *bankgiroServiceParseStreamClient) Recv() (*ParseStreamResponse, error) {
+	m := new(ParseStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *bankgiroServiceClient) Detect(ctx context.Context, in *DetectRequest, opts ...grpc.CallOption) (*DetectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DetectResponse)
+	err := c.cc.Invoke(ctx, BankgiroService_Detect_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BankgiroServiceServer is the server API for BankgiroService service.
+// All implementations must embed UnimplementedBankgiroServiceServer
+// for forward compatibility
+//
+// Seals, verifies and parses Bankgiro files
+// The streaming variants send files in chunks, for files larger than the maximum message size
+type BankgiroServiceServer interface {
+	// Seal a file with an HMAC seal
+	Seal(context.Context, *SealRequest) (*SealResponse, error)
+	// Seal a file sent in chunks, the sealed file is returned in chunks
+	SealStream(BankgiroService_SealStreamServer) error
+	// Verify the HMAC seal of a sealed file
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	// Verify the HMAC seal of a sealed file sent in chunks
+	VerifyStream(BankgiroService_VerifyStreamServer) error
+	// Parse an Autogiro file into its sections
+	Parse(context.Context, *ParseRequest) (*ParseResponse, error)
+	// Parse an Autogiro file sent in chunks, the sections are returned one by one
+	ParseStream(BankgiroService_ParseStreamServer) error
+	// Detect the file type from the opening record and whether the file is sealed
+	Detect(context.Context, *DetectRequest) (*DetectResponse, error)
+	mustEmbedUnimplementedBankgiroServiceServer()
+}
+
+// UnimplementedBankgiroServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedBankgiroServiceServer struct {
+}
+
+func (UnimplementedBankgiroServiceServer) Seal(context.Context, *SealRequest) (*SealResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Seal not implemented")
+}
+func (UnimplementedBankgiroServiceServer) SealStream(BankgiroService_SealStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SealStream not implemented")
+}
+func (UnimplementedBankgiroServiceServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedBankgiroServiceServer) VerifyStream(BankgiroService_VerifyStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method VerifyStream not implemented")
+}
+func (UnimplementedBankgiroServiceServer) Parse(context.Context, *ParseRequest) (*ParseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Parse not implemented")
+}
+func (UnimplementedBankgiroServiceServer) ParseStream(BankgiroService_ParseStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ParseStream not implemented")
+}
+func (UnimplementedBankgiroServiceServer) Detect(context.Context, *DetectRequest) (*DetectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Detect not implemented")
+}
+func (UnimplementedBankgiroServiceServer) mustEmbedUnimplementedBankgiroServiceServer() {}
+
+// UnsafeBankgiroServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BankgiroServiceServer will
+// result in compilation errors.
+type UnsafeBankgiroServiceServer interface {
+	mustEmbedUnimplementedBankgiroServiceServer()
+}
+
+func RegisterBankgiroServiceServer(s grpc.ServiceRegistrar, srv BankgiroServiceServer) {
+	s.RegisterService(&BankgiroService_ServiceDesc, srv)
+}
+
+func _BankgiroService_Seal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SealRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BankgiroServiceServer).Seal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BankgiroService_Seal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BankgiroServiceServer).Seal(ctx, req.(*SealRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BankgiroService_SealStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BankgiroServiceServer).SealStream(&bankgiroServiceSealStreamServer{ServerStream: stream})
+}
+
+type BankgiroService_SealStreamServer interface {
+	Send(*SealStreamResponse) error
+	Recv() (*SealStreamRequest, error)
+	grpc.ServerStream
+}
+
+type bankgiroServiceSealStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *bankgiroServiceSealStreamServer) Send(m *SealStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *bankgiroServiceSealStreamServer) Recv() (*SealStreamRequest, error) {
+	m := new(SealStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _BankgiroService_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BankgiroServiceServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BankgiroService_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BankgiroServiceServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BankgiroService_VerifyStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BankgiroServiceServer).VerifyStream(&bankgiroServiceVerifyStreamServer{ServerStream: stream})
+}
+
+type BankgiroService_VerifyStreamServer interface {
+	SendAndClose(*VerifyStreamResponse) error
+	Recv() (*VerifyStreamRequest, error)
+	grpc.ServerStream
+}
+
+type bankgiroServiceVerifyStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *bankgiroServiceVerifyStreamServer) SendAndClose(m *VerifyStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *bankgiroServiceVerifyStreamServer) Recv() (*VerifyStreamRequest, error) {
+	m := new(VerifyStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _BankgiroService_Parse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BankgiroServiceServer).Parse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BankgiroService_Parse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BankgiroServiceServer).Parse(ctx, req.(*ParseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BankgiroService_ParseStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BankgiroServiceServer).ParseStream(&bankgiroServiceParseStreamServer{ServerStream: stream})
+}
+
+type BankgiroService_ParseStreamServer interface {
+	Send(*ParseStreamResponse) error
+	Recv() (*ParseStreamRequest, error)
+	grpc.ServerStream
+}
+
+type bankgiroServiceParseStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *bankgiroServiceParseStreamServer) Send(m *ParseStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *bankgiroServiceParseStreamServer) Recv() (*ParseStreamRequest, error) {
+	m := new(ParseStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _BankgiroService_Detect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BankgiroServiceServer).Detect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BankgiroService_Detect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BankgiroServiceServer).Detect(ctx, req.(*DetectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BankgiroService_ServiceDesc is the grpc.ServiceDesc for BankgiroService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BankgiroService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bankgiro.v1.BankgiroService",
+	HandlerType: (*BankgiroServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Seal",
+			Handler:    _BankgiroService_Seal_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _BankgiroService_Verify_Handler,
+		},
+		{
+			MethodName: "Parse",
+			Handler:    _BankgiroService_Parse_Handler,
+		},
+		{
+			MethodName: "Detect",
+			Handler:    _BankgiroService_Detect_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SealStream",
+			Handler:       _BankgiroService_SealStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "VerifyStream",
+			Handler:       _BankgiroService_VerifyStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ParseStream",
+			Handler:       _BankgiroService_ParseStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "bankgiro/v1/bankgiro.proto",
+}
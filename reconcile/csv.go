@@ -0,0 +1,94 @@
+package reconcile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hoglandets-it/go-bankgiro/money"
+)
+
+// The CSV columns read into a Charge, payer_number and amount are required
+var csvColumns = []string{"id", "payer_number", "amount", "date", "reference"}
+
+// Read expected charges from a CSV file, see ReadCsv
+func LoadCsv(path string) (Charges, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	charges, err := ReadCsv(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return charges, nil
+}
+
+// Read expected charges from CSV with a header row naming the columns id, payer_number, amount, date and reference
+// Columns are separated by comma or semicolon, amounts are in kronor with . or , as decimal separator
+func ReadCsv(r io.Reader) (Charges, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(br.Size())
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if i := bytes.IndexByte(header, '\n'); i >= 0 {
+		header = header[:i]
+	}
+
+	reader := csv.NewReader(br)
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no header row found")
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range csvColumns[1:3] {
+		if _, found := columns[required]; !found {
+			return nil, fmt.Errorf("column %s is required", required)
+		}
+	}
+
+	charges := Charges{}
+	for i, row := range rows[1:] {
+		value := func(name string) string {
+			if column, found := columns[name]; found && column < len(row) {
+				return strings.TrimSpace(row[column])
+			}
+			return ""
+		}
+
+		amount, err := money.Parse(value("amount"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+
+		charges = append(charges, Charge{
+			Id:          value("id"),
+			PayerNumber: value("payer_number"),
+			Amount:      amount,
+			Date:        strings.ReplaceAll(value("date"), "-", ""),
+			Reference:   value("reference"),
+		})
+	}
+
+	return charges, nil
+}
@@ -0,0 +1,275 @@
+package reconcile
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hoglandets-it/go-bankgiro/money"
+	"github.com/hoglandets-it/go-bankgiro/parse"
+)
+
+// The outcome of an expected charge or a record in the payment specification
+type Status string
+
+const (
+	StatusPaid       Status = "paid"
+	StatusRejected   Status = "rejected"
+	StatusUnexpected Status = "unexpected"
+	StatusMissing    Status = "missing"
+)
+
+// The statuses in the order they are reported
+var Statuses = []Status{StatusPaid, StatusRejected, StatusUnexpected, StatusMissing}
+
+// A charge expected in the payment specification, e.g. an invoice in the ledger
+// Id: the ledger's identifier, only used in the report
+// Amount: negative for credits (TK32)
+// Date: the payment date YYYYMMDD, optional
+// Reference: the reference sent with the payment, optional
+type Charge struct {
+	Id          string       `json:"id"`
+	PayerNumber string       `json:"payerNumber"`
+	Amount      money.Amount `json:"amount"`
+	Date        string       `json:"date"`
+	Reference   string       `json:"reference"`
+}
+
+// A source of expected charges
+type Ledger interface {
+	Charges() ([]Charge, error)
+}
+
+// A fixed list of expected charges
+type Charges []Charge
+
+func (c Charges) Charges() ([]Charge, error) {
+	return c, nil
+}
+
+// A reconciled charge or record
+// Charge is nil for unexpected records, Payment and Refund are nil for missing charges
+// Reason: the payment result or refund reason of a rejected charge
+type Item struct {
+	Status  Status               `json:"status"`
+	Charge  *Charge              `json:"charge,omitempty"`
+	Payment *parse.PaymentRecord `json:"payment,omitempty"`
+	Refund  *parse.RefundRecord  `json:"refund,omitempty"`
+	Reason  *parse.Code          `json:"reason,omitempty"`
+	Note    string               `json:"note,omitempty"`
+}
+
+// The number and amount of the items with a status
+type Total struct {
+	Count  int          `json:"count"`
+	Amount money.Amount `json:"amount"`
+}
+
+// The result of reconciling a payment specification against the expected charges
+type Report struct {
+	Summary map[Status]*Total `json:"summary"`
+	Items   []Item            `json:"items"`
+}
+
+// Match the payment and refund records to the expected charges and classify each
+// Records are matched on payer number and reference, then on payer number, amount and date, then on payer number and amount
+// Approved payments are paid, payments with another result and refunded payments are rejected
+func Reconcile(spec *parse.PaymentSpecification, ledger Ledger) (*Report, error) {
+	charges, err := ledger.Charges()
+	if err != nil {
+		return nil, err
+	}
+
+	m := matcher{charges: charges, items: make([]*Item, len(charges))}
+
+	unexpected := []Item{}
+	for i := range spec.Payments {
+		payment := &spec.Payments[i]
+		amount := payment.Amount
+		if payment.Code == parse.TK_CREDIT {
+			amount = -amount
+		}
+
+		item := m.match(payment.PayerNumber, amount, payment.PaymentDate, payment.Reference)
+		if item == nil {
+			unexpected = append(unexpected, Item{Status: StatusUnexpected, Payment: payment})
+			continue
+		}
+
+		item.Payment = payment
+		item.Status = StatusPaid
+		if !payment.Approved() {
+			item.Status = StatusRejected
+			item.Reason = &payment.Result
+		}
+		if item.Charge.Amount != amount {
+			item.Note = fmt.Sprintf("amount %s, expected %s", amount, item.Charge.Amount)
+		}
+	}
+
+	for i := range spec.Refunds {
+		refund := &spec.Refunds[i]
+
+		// A refund of a payment in the same specification replaces its outcome
+		item := m.matched(refund.PayerNumber, refund.Amount, refund.Reference)
+		if item == nil {
+			item = m.match(refund.PayerNumber, refund.Amount, refund.PaymentDate, refund.Reference)
+		}
+		if item == nil {
+			unexpected = append(unexpected, Item{Status: StatusUnexpected, Refund: refund})
+			continue
+		}
+
+		item.Refund = refund
+		item.Status = StatusRejected
+		item.Reason = &refund.Reason
+	}
+
+	report := &Report{Summary: map[Status]*Total{}}
+	for _, status := range Statuses {
+		report.Summary[status] = &Total{}
+	}
+
+	for i, item := range m.items {
+		if item == nil {
+			item = &Item{Status: StatusMissing, Charge: &m.charges[i]}
+		}
+		report.add(*item)
+	}
+	for _, item := range unexpected {
+		report.add(item)
+	}
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		return statusOrder(report.Items[i].Status) < statusOrder(report.Items[j].Status)
+	})
+
+	return report, nil
+}
+
+func (r *Report) add(item Item) {
+	r.Items = append(r.Items, item)
+	r.Summary[item.Status].Count++
+	r.Summary[item.Status].Amount += item.Amount()
+}
+
+// Get the amount of the item, from the record when there is one
+func (item Item) Amount() money.Amount {
+	switch {
+	case item.Refund != nil:
+		return item.Refund.Amount
+	case item.Payment != nil && item.Payment.Code == parse.TK_CREDIT:
+		return -item.Payment.Amount
+	case item.Payment != nil:
+		return item.Payment.Amount
+	}
+
+	return item.Charge.Amount
+}
+
+func statusOrder(status Status) int {
+	for i, s := range Statuses {
+		if s == status {
+			return i
+		}
+	}
+
+	return len(Statuses)
+}
+
+// Matches records to charges, each charge is matched at most once
+type matcher struct {
+	charges []Charge
+	items   []*Item
+}
+
+// Find the best unmatched charge for a record and mark it matched
+func (m *matcher) match(payerNumber string, amount money.Amount, date string, reference string) *Item {
+	best, bestScore := -1, 0
+	for i, charge := range m.charges {
+		if m.items[i] != nil || !samePayer(charge.PayerNumber, payerNumber) {
+			continue
+		}
+
+		score := 0
+		switch {
+		case charge.Reference != "" && sameReference(charge.Reference, reference):
+			score = 3
+		case charge.Amount == amount && charge.Date == date:
+			score = 2
+		case charge.Amount == amount && charge.Date == "":
+			score = 1
+		}
+
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	if best < 0 {
+		return nil
+	}
+
+	m.items[best] = &Item{Charge: &m.charges[best]}
+
+	return m.items[best]
+}
+
+// Find a charge already matched to a payment with the same payer, amount and reference
+func (m *matcher) matched(payerNumber string, amount money.Amount, reference string) *Item {
+	for _, item := range m.items {
+		if item != nil && item.Payment != nil && item.Refund == nil && samePayer(item.Payment.PayerNumber, payerNumber) &&
+			item.Payment.Amount == amount && sameReference(item.Payment.Reference, reference) {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// Payer numbers are compared without leading zeros
+func samePayer(a string, b string) bool {
+	return strings.TrimLeft(a, "0") == strings.TrimLeft(b, "0")
+}
+
+// References are compared without case and leading zeros, the bank pads them with zeros
+func sameReference(a string, b string) bool {
+	return strings.EqualFold(strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0"))
+}
+
+// Write the summary and the items as text, one line per item
+func (r *Report) Write(w io.Writer) error {
+	var out strings.Builder
+
+	for _, status := range Statuses {
+		total := r.Summary[status]
+		fmt.Fprintf(&out, "%-10s %5d %15s\r\n", status, total.Count, total.Amount)
+	}
+
+	for _, item := range r.Items {
+		out.WriteString("\r\n")
+		fmt.Fprintf(&out, "%-10s %12s", item.Status, item.Amount())
+
+		if item.Charge != nil {
+			fmt.Fprintf(&out, "  charge %s payer %s", item.Charge.Id, item.Charge.PayerNumber)
+		}
+		if item.Payment != nil {
+			fmt.Fprintf(&out, "  TK%s row %d payer %s date %s ref %q", item.Payment.Code, item.Payment.Row, item.Payment.PayerNumber, item.Payment.PaymentDate, item.Payment.Reference)
+		}
+		if item.Refund != nil {
+			fmt.Fprintf(&out, "  TK%s row %d payer %s refunded %s ref %q", parse.TK_REFUND, item.Refund.Row, item.Refund.PayerNumber, item.Refund.RefundDate, item.Refund.Reference)
+		}
+		if item.Reason != nil {
+			fmt.Fprintf(&out, "  reason %s", item.Reason)
+		}
+		if item.Note != "" {
+			fmt.Fprintf(&out, "  (%s)", item.Note)
+		}
+	}
+	out.WriteString("\r\n")
+
+	_, err := io.WriteString(w, out.String())
+
+	return err
+}
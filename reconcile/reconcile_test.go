@@ -0,0 +1,119 @@
+package reconcile_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/parse"
+	"github.com/hoglandets-it/go-bankgiro/reconcile"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+)
+
+const ledger = `id;payer_number;amount;date;reference
+A1;101;3 000,00;2016-07-25;
+A2;102;3000;;FAKTNR156
+A3;106;3000.00;20160725;
+A4;114;200;;FAKTNR150
+A5;999;100.00;;
+A6;109;-1000;;
+A7;103;2500;;FAKTNR157
+`
+
+func readSpecification(t *testing.T) *parse.PaymentSpecification {
+	t.Helper()
+
+	content, err := os.ReadFile("../tests/normalization/betalningsspec-new.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isoContent, err := tools.BytesToIsoString(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agFile := parse.AutogiroFile{}
+	if err := agFile.ParseFile(isoContent); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := agFile.PaymentSpecification()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return spec
+}
+
+func TestReconcile(t *testing.T) {
+	charges, err := reconcile.ReadCsv(strings.NewReader(ledger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := reconcile.Reconcile(readSpecification(t), charges)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id     string
+		status reconcile.Status
+		reason string
+		note   bool
+	}{
+		{"A1", reconcile.StatusPaid, "", false},
+		{"A2", reconcile.StatusPaid, "", false},
+		{"A3", reconcile.StatusRejected, "1", false},
+		{"A4", reconcile.StatusRejected, "02", false},
+		{"A5", reconcile.StatusMissing, "", false},
+		{"A6", reconcile.StatusPaid, "", false},
+		{"A7", reconcile.StatusPaid, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			for _, item := range report.Items {
+				if item.Charge == nil || item.Charge.Id != tt.id {
+					continue
+				}
+				reason := ""
+				if item.Reason != nil {
+					reason = item.Reason.Code
+				}
+				if item.Status != tt.status || reason != tt.reason || (item.Note != "") != tt.note {
+					t.Errorf("item = %+v, expected %s reason %q", item, tt.status, tt.reason)
+				}
+				return
+			}
+			t.Errorf("charge %s not in the report", tt.id)
+		})
+	}
+
+	// 12 payments and 2 refunds, 6 of them matched
+	expected := map[reconcile.Status]reconcile.Total{
+		reconcile.StatusPaid:       {Count: 4, Amount: 300000 + 300000 - 100000 + 300000},
+		reconcile.StatusRejected:   {Count: 2, Amount: 300000 + 20000},
+		reconcile.StatusUnexpected: {Count: 8, Amount: 4*300000 - 3*100000 + 50000},
+		reconcile.StatusMissing:    {Count: 1, Amount: 10000},
+	}
+	for status, total := range expected {
+		if *report.Summary[status] != total {
+			t.Errorf("summary %s = %+v, expected %+v", status, *report.Summary[status], total)
+		}
+	}
+
+	var out strings.Builder
+	if err := report.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "missing        1          100.00") {
+		t.Errorf("Write() = %s", out.String())
+	}
+}
+
+func TestReadCsvMissingColumn(t *testing.T) {
+	if _, err := reconcile.ReadCsv(strings.NewReader("id,amount\n1,10\n")); err == nil || !strings.Contains(err.Error(), "payer_number") {
+		t.Errorf("ReadCsv() error = %v, expected payer_number to be required", err)
+	}
+}
@@ -0,0 +1,18 @@
+package rpc
+
+import "context"
+
+// Bearer token credentials for clients of the service, use with grpc.WithPerRPCCredentials
+// Insecure allows the token to be sent without TLS, e.g. over a local connection
+type TokenCredentials struct {
+	Token    string
+	Insecure bool
+}
+
+func (tc TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + tc.Token}, nil
+}
+
+func (tc TokenCredentials) RequireTransportSecurity() bool {
+	return !tc.Insecure
+}
@@ -0,0 +1,463 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/audit"
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/parse"
+	bankgirov1 "github.com/hoglandets-it/go-bankgiro/proto/bankgiro/v1"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"github.com/hoglandets-it/go-bankgiro/server"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// The size of the chunks files are streamed back in
+const ChunkSize = 64 << 10
+
+// The response header listing the payment dates of a sealed file that Bankgirot would move or reject, one value per payment
+const WarningHeader = "bankgiro-warning"
+
+// Addr: address to listen on, e.g. :9090
+// Tokens: accepted bearer tokens, at least one is required
+// MaxFileSize: maximum size of a file, sent whole or in chunks
+// ShutdownTimeout: time given to running calls on shutdown
+// AuditLog: when set, each seal is recorded in the audit log
+// Profile: the normalization rules, nil for the default
+// TLSCert/TLSKey: serve with TLS using the given certificate and key files
+type Config struct {
+	Addr            string
+	Tokens          []string
+	MaxFileSize     int64
+	ShutdownTimeout time.Duration
+	TLSCert         string
+	TLSKey          string
+	AuditLog        *audit.Log
+	Profile         *seal.NormalizationProfile
+}
+
+// The gRPC sealing, verification and parsing service
+type Server struct {
+	bankgirov1.UnimplementedBankgiroServiceServer
+
+	Config Config
+	Keys   server.KeyProvider
+	Logger *log.Logger
+}
+
+func New(config Config, keyProvider server.KeyProvider) (*Server, error) {
+	if len(config.Tokens) == 0 {
+		return nil, fmt.Errorf("at least one bearer token is required")
+	}
+
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = server.DefaultMaxBodySize
+	}
+
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = 30 * time.Second
+	}
+
+	return &Server{
+		Config: config,
+		Keys:   keyProvider,
+		Logger: log.New(os.Stdout, "", log.LstdFlags),
+	}, nil
+}
+
+// Create a gRPC server with the service registered and bearer token authentication
+func (s *Server) GrpcServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.MaxRecvMsgSize(int(s.Config.MaxFileSize)+ChunkSize),
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := s.authenticate(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.authenticate(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	)
+
+	grpcServer := grpc.NewServer(opts...)
+	bankgirov1.RegisterBankgiroServiceServer(grpcServer, s)
+
+	return grpcServer
+}
+
+// Listen and serve until the context is cancelled, then stop gracefully
+func (s *Server) Run(ctx context.Context) error {
+	opts := []grpc.ServerOption{}
+	if s.Config.TLSCert != "" {
+		creds, err := credentials.NewServerTLSFromFile(s.Config.TLSCert, s.Config.TLSKey)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", s.Config.Addr)
+	if err != nil {
+		return err
+	}
+
+	grpcServer := s.GrpcServer(opts...)
+
+	errs := make(chan error, 1)
+	go func() {
+		s.Logger.Printf("grpc listening on %s", listener.Addr())
+		errs <- grpcServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.Logger.Printf("grpc shutting down")
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(s.Config.ShutdownTimeout):
+		grpcServer.Stop()
+	}
+
+	return <-errs
+}
+
+// Check the bearer token in the authorization metadata
+func (s *Server) authenticate(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, authorization := range md.Get("authorization") {
+		if server.Authorized(s.Config.Tokens, authorization) {
+			return nil
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "a valid bearer token is required")
+}
+
+func (s *Server) Seal(ctx context.Context, req *bankgirov1.SealRequest) (*bankgirov1.SealResponse, error) {
+	bgFile, err := s.seal(ctx, req.GetContent(), req.GetSealDate())
+	if err != nil {
+		return nil, err
+	}
+
+	return &bankgirov1.SealResponse{
+		Content:  bgFile.Bytes(),
+		SealDate: bgFile.Seal.SealDate,
+		Kvv:      bgFile.Seal.GetKvvBgFormat(),
+		Mac:      bgFile.Seal.GetMacBgFormat(),
+	}, nil
+}
+
+func (s *Server) SealStream(stream bankgirov1.BankgiroService_SealStreamServer) error {
+	sealDate := ""
+	content, err := s.receive(func(first bool) ([]byte, error) {
+		req, err := stream.Recv()
+		if first && err == nil {
+			sealDate = req.GetSealDate()
+		}
+		return req.GetChunk(), err
+	})
+	if err != nil {
+		return err
+	}
+
+	bgFile, err := s.seal(stream.Context(), content, sealDate)
+	if err != nil {
+		return err
+	}
+
+	sealed := bgFile.Bytes()
+	for offset := 0; offset < len(sealed); offset += ChunkSize {
+		res := &bankgirov1.SealStreamResponse{Chunk: sealed[offset:min(offset+ChunkSize, len(sealed))]}
+		if offset == 0 {
+			res.SealDate = bgFile.Seal.SealDate
+			res.Kvv = bgFile.Seal.GetKvvBgFormat()
+			res.Mac = bgFile.Seal.GetMacBgFormat()
+		}
+
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) Verify(ctx context.Context, req *bankgirov1.VerifyRequest) (*bankgirov1.VerifyResponse, error) {
+	verification, err := s.verify(req.GetContent())
+	if err != nil {
+		return nil, err
+	}
+
+	return &bankgirov1.VerifyResponse{Verification: verification}, nil
+}
+
+func (s *Server) VerifyStream(stream bankgirov1.BankgiroService_VerifyStreamServer) error {
+	content, err := s.receive(func(bool) ([]byte, error) {
+		req, err := stream.Recv()
+		return req.GetChunk(), err
+	})
+	if err != nil {
+		return err
+	}
+
+	verification, err := s.verify(content)
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&bankgirov1.VerifyStreamResponse{Verification: verification})
+}
+
+func (s *Server) Parse(ctx context.Context, req *bankgirov1.ParseRequest) (*bankgirov1.ParseResponse, error) {
+	agFile, err := s.parse(req.GetContent())
+	if err != nil {
+		return nil, err
+	}
+
+	res := &bankgirov1.ParseResponse{
+		HmacHeader:  agFile.HMACStartFound,
+		HmacTrailer: agFile.HMACEndFound,
+	}
+	for i := range agFile.Sections {
+		res.Sections = append(res.Sections, section(&agFile.Sections[i]))
+	}
+
+	return res, nil
+}
+
+func (s *Server) ParseStream(stream bankgirov1.BankgiroService_ParseStreamServer) error {
+	content, err := s.receive(func(bool) ([]byte, error) {
+		req, err := stream.Recv()
+		return req.GetChunk(), err
+	})
+	if err != nil {
+		return err
+	}
+
+	agFile, err := s.parse(content)
+	if err != nil {
+		return err
+	}
+
+	for i := range agFile.Sections {
+		if err := stream.Send(&bankgirov1.ParseStreamResponse{Section: section(&agFile.Sections[i])}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) Detect(ctx context.Context, req *bankgirov1.DetectRequest) (*bankgirov1.DetectResponse, error) {
+	content, err := tools.BytesToIsoString(req.GetContent())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	sectionType, _, err := parse.FindOpeningRecord(content)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	customerNumber, bankgiro, err := parse.OpeningNumbers(content)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &bankgirov1.DetectResponse{
+		TypeCode:       sectionType.Code,
+		TypeName:       sectionType.Name,
+		CustomerNumber: customerNumber,
+		Bankgiro:       bankgiro,
+		Sealed:         sign.IsSealed(req.GetContent()),
+	}, nil
+}
+
+// Receive a file sent in chunks, recv is called until the client closes the stream
+func (s *Server) receive(recv func(first bool) ([]byte, error)) ([]byte, error) {
+	var content bytes.Buffer
+	for first := true; ; first = false {
+		chunk, err := recv(first)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if int64(content.Len()+len(chunk)) > s.Config.MaxFileSize {
+			return nil, status.Errorf(codes.ResourceExhausted, "file exceeds %d bytes", s.Config.MaxFileSize)
+		}
+		content.Write(chunk)
+	}
+
+	return content.Bytes(), nil
+}
+
+func (s *Server) seal(ctx context.Context, content []byte, sealDate string) (*sign.BankgiroFile, error) {
+	if len(content) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "file is empty")
+	}
+
+	if sealDate != "" {
+		if _, err := time.Parse("060102", sealDate); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "seal date must be formatted as YYMMDD")
+		}
+	}
+
+	if err := sign.Validate(content); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	bgFile := sign.NewBankgiroFile(charset.Options{})
+	bgFile.Write(content)
+	if err := bgFile.Prepare(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if sealDate != "" {
+		bgFile.SetSealDate(sealDate)
+	}
+
+	// Payment dates Bankgirot would move or reject are returned in the response header
+	if len(bgFile.DateProblems) > 0 {
+		md := metadata.MD{}
+		for _, problem := range bgFile.DateProblems {
+			md.Append(WarningHeader, strings.Trim(strconv.QuoteToASCII(problem.String()), `"`))
+		}
+		if err := grpc.SetHeader(ctx, md); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	sealDay, err := bgFile.SealDay()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	customerNumber, bankgiro, _ := bgFile.OpeningNumbers()
+	signer, release, err := s.Keys.Signer(customerNumber, bankgiro, sealDay)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	defer release()
+
+	if err := bgFile.SetNormalizationProfile(s.Config.Profile); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err := bgFile.SetSigner(signer); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	bgFile.Source = "grpc"
+	if p, ok := peer.FromContext(ctx); ok {
+		bgFile.Source += ":" + p.Addr.String()
+	}
+	bgFile.AuditLog = s.Config.AuditLog
+
+	if err := bgFile.Sign(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return bgFile, nil
+}
+
+func (s *Server) verify(content []byte) (*bankgirov1.Verification, error) {
+	sealed, err := sign.ParseSealedFile(content)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	sealDate, err := time.Parse("060102", sealed.SealDate)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid seal date %q", sealed.SealDate)
+	}
+
+	customerNumber, bankgiro, _ := parse.OpeningNumbers(sealed.Content)
+	signer, release, err := s.Keys.Signer(customerNumber, bankgiro, sealDate)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	defer release()
+
+	verification, err := sign.VerifySealedFileWithProfile(content, signer, s.Config.Profile)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &bankgirov1.Verification{
+		Valid:       verification.Valid,
+		KvvMatch:    verification.KvvMatch,
+		MacMatch:    verification.MacMatch,
+		SealDate:    verification.SealDate,
+		Kvv:         verification.Kvv,
+		ExpectedKvv: verification.ExpectedKvv,
+		Mac:         verification.Mac,
+		ExpectedMac: verification.ExpectedMac,
+	}, nil
+}
+
+func (s *Server) parse(content []byte) (*parse.AutogiroFile, error) {
+	isoContent, err := tools.BytesToIsoString(content)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	agFile := parse.AutogiroFile{}
+	if err := agFile.ParseFile(isoContent); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &agFile, nil
+}
+
+// Convert a parsed section, rows are converted from ISO-8859-1 to UTF-8 as required for protobuf strings
+func section(sec *parse.AutogiroSection) *bankgirov1.Section {
+	res := &bankgirov1.Section{
+		TypeCode:    sec.SectionType.Code,
+		TypeName:    sec.SectionType.Name,
+		SectionSeal: sec.SectionSeal,
+		Errors:      charset.IsoToUtf8Strings(sec.Errors),
+		Rows:        charset.IsoToUtf8Strings(sec.Rows),
+	}
+
+	if len(sec.Rows) > 0 && len(sec.Rows[0]) >= sec.SectionType.AccountNumber[1] && len(sec.Rows[0]) >= sec.SectionType.CustomerNumber[1] {
+		res.CustomerNumber = sec.GetCustomerNumber()
+		res.Bankgiro = sec.GetAccountNumber()
+	}
+
+	return res
+}
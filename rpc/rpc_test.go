@@ -0,0 +1,238 @@
+package rpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	bankgirov1 "github.com/hoglandets-it/go-bankgiro/proto/bankgiro/v1"
+	"github.com/hoglandets-it/go-bankgiro/rpc"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	testKey   = "1234567890ABCDEF1234567890ABCDEF"
+	testKvv   = "FF365893D899291C3BF505FB3175E880"
+	testDate  = "240429"
+	testToken = "secret-token"
+)
+
+// A key only valid on the test date, so the seal date must be used to select it
+type staticKey struct{}
+
+func (staticKey) Signer(customerNumber string, bankgiro string, sealDate time.Time) (seal.Signer, func(), error) {
+	if sealDate.Format("060102") != testDate {
+		return nil, nil, fmt.Errorf("no valid key found on %s", sealDate.Format("20060102"))
+	}
+
+	signer, err := seal.NewSoftwareSigner([]byte(testKey))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return signer, signer.Clear, nil
+}
+
+func newClient(t *testing.T, token string, maxFileSize int64) bankgirov1.BankgiroServiceClient {
+	srv, err := rpc.New(rpc.Config{Tokens: []string{testToken}, MaxFileSize: maxFileSize}, staticKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := srv.GrpcServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(rpc.TokenCredentials{Token: token, Insecure: true}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return bankgirov1.NewBankgiroServiceClient(conn)
+}
+
+func TestSealAndVerify(t *testing.T) {
+	client := newClient(t, testToken, 0)
+	ctx := context.Background()
+
+	unsigned, err := os.ReadFile("../tests/sealFile/basic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var header metadata.MD
+	sealed, err := client.Seal(ctx, &bankgirov1.SealRequest{Content: unsigned, SealDate: testDate}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if warnings := header.Get(rpc.WarningHeader); len(warnings) != 3 || !strings.HasPrefix(warnings[0], "row 2: TK82 payment date 20240422 has passed") {
+		t.Errorf("unexpected warnings: %q", warnings)
+	}
+	if sealed.Kvv != testKvv || sealed.SealDate != testDate {
+		t.Errorf("unexpected seal: kvv %s, date %s", sealed.Kvv, sealed.SealDate)
+	}
+
+	verified, err := client.Verify(ctx, &bankgirov1.VerifyRequest{Content: sealed.Content})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified.Verification.Valid || verified.Verification.Mac != sealed.Mac {
+		t.Errorf("sealed file did not verify: %v", verified.Verification)
+	}
+
+	detected, err := client.Detect(ctx, &bankgirov1.DetectRequest{Content: sealed.Content})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !detected.Sealed || detected.TypeCode == "" {
+		t.Errorf("unexpected detection: %v", detected)
+	}
+}
+
+func TestStreams(t *testing.T) {
+	client := newClient(t, testToken, 0)
+	ctx := context.Background()
+
+	unsigned, err := os.ReadFile("../tests/sealFile/basic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	whole, err := client.Seal(ctx, &bankgirov1.SealRequest{Content: unsigned, SealDate: testDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealStream, err := client.SealStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for offset := 0; offset < len(unsigned); offset += 100 {
+		req := &bankgirov1.SealStreamRequest{Chunk: unsigned[offset:min(offset+100, len(unsigned))]}
+		if offset == 0 {
+			req.SealDate = testDate
+		}
+		if err := sealStream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sealStream.CloseSend()
+
+	sealed := []byte{}
+	for {
+		res, err := sealStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sealed) == 0 && res.Mac != whole.Mac {
+			t.Errorf("streamed mac %s does not match %s", res.Mac, whole.Mac)
+		}
+		sealed = append(sealed, res.Chunk...)
+	}
+	if string(sealed) != string(whole.Content) {
+		t.Error("streamed sealed file does not match")
+	}
+
+	verifyStream, err := client.VerifyStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyStream.Send(&bankgirov1.VerifyStreamRequest{Chunk: sealed[:200]})
+	verifyStream.Send(&bankgirov1.VerifyStreamRequest{Chunk: sealed[200:]})
+	verified, err := verifyStream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified.Verification.Valid {
+		t.Errorf("streamed file did not verify: %v", verified.Verification)
+	}
+
+	parseStream, err := client.ParseStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	incoming, err := os.ReadFile("../tests/normalization/betalningsspec-new.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parseStream.Send(&bankgirov1.ParseStreamRequest{Chunk: incoming})
+	parseStream.CloseSend()
+	sections := 0
+	for {
+		res, err := parseStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Section.Rows) == 0 {
+			t.Error("streamed section has no rows")
+		}
+		sections++
+	}
+	if sections == 0 {
+		t.Error("no sections streamed")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	unsigned, _ := os.ReadFile("../tests/sealFile/basic.txt")
+
+	tests := []struct {
+		name string
+		call func(client bankgirov1.BankgiroServiceClient) error
+		code codes.Code
+	}{
+		{"unauthenticated", func(client bankgirov1.BankgiroServiceClient) error {
+			_, err := newClient(t, "wrong-token", 0).Seal(ctx, &bankgirov1.SealRequest{Content: unsigned})
+			return err
+		}, codes.Unauthenticated},
+		{"invalid date", func(client bankgirov1.BankgiroServiceClient) error {
+			_, err := client.Seal(ctx, &bankgirov1.SealRequest{Content: unsigned, SealDate: "2404"})
+			return err
+		}, codes.InvalidArgument},
+		{"not sealed", func(client bankgirov1.BankgiroServiceClient) error {
+			_, err := client.Verify(ctx, &bankgirov1.VerifyRequest{Content: unsigned})
+			return err
+		}, codes.InvalidArgument},
+		{"too large", func(client bankgirov1.BankgiroServiceClient) error {
+			stream, err := newClient(t, testToken, 64).VerifyStream(ctx)
+			if err != nil {
+				return err
+			}
+			stream.Send(&bankgirov1.VerifyStreamRequest{Chunk: unsigned})
+			_, err = stream.CloseAndRecv()
+			return err
+		}, codes.ResourceExhausted},
+	}
+
+	client := newClient(t, testToken, 0)
+	for _, test := range tests {
+		err := test.call(client)
+		if status.Code(err) != test.code {
+			t.Errorf("%s: expected %s, got %v", test.name, test.code, err)
+		}
+	}
+}
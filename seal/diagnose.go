@@ -0,0 +1,250 @@
+package seal
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// Characters commonly found in keys and KVVs copied from letters, e-mails or spreadsheets
+const KeySeparators = " -:.\t_"
+
+// Hash functions a KVV may mistakenly have been calculated with
+var DiagnoseHashFunctions = []struct {
+	Name string
+	Hash func() hash.Hash
+}{
+	{"HMAC-SHA1", sha1.New},
+	{"HMAC-SHA224", sha256.New224},
+	{"HMAC-SHA384", sha512.New384},
+	{"HMAC-SHA512", sha512.New},
+	{"HMAC-MD5", md5.New},
+}
+
+// Letters mistyped for digits when keys are read aloud or copied from print
+var DiagnoseLookalikes = map[byte]byte{
+	'O': '0',
+	'I': '1',
+	'L': '1',
+	'S': '5',
+	'G': '6',
+	'Z': '2',
+}
+
+// The provided KVV does not match the KVV calculated from the key
+type KvvMismatchError struct {
+	Provided   string
+	Calculated string
+}
+
+func (e *KvvMismatchError) Error() string {
+	return fmt.Sprintf("provided and calculated kvv do not match:\r\nProvided:   %s\r\nCalculated: %s", e.Provided, e.Calculated)
+}
+
+// A key or KVV error together with the likely causes found by DiagnoseKvv
+type DiagnosedError struct {
+	Err    error
+	Causes []string
+}
+
+func (e *DiagnosedError) Error() string {
+	msg := e.Err.Error()
+	for _, cause := range e.Causes {
+		msg += "\r\nLikely cause: " + cause
+	}
+
+	return msg
+}
+
+func (e *DiagnosedError) Unwrap() error {
+	return e.Err
+}
+
+// Attach the likely causes of a key or KVV error
+// key is the key as entered (hex encoded), kvv the KVV as entered, may be empty
+func DiagnoseKeyError(err error, key []byte, kvv string) error {
+	if err == nil {
+		return nil
+	}
+
+	causes := DiagnoseKvv(key, kvv)
+	if len(causes) == 0 {
+		return err
+	}
+
+	return &DiagnosedError{Err: err, Causes: causes}
+}
+
+// Find the likely causes why a key cannot be used or does not produce the given KVV
+// The key itself is never included in the returned causes
+func DiagnoseKvv(key []byte, kvv string) []string {
+	causes := []string{}
+
+	normalizedKvv := stripSeparators(kvv)
+	if normalizedKvv != strings.ToUpper(strings.TrimSpace(kvv)) {
+		causes = append(causes, "the KVV contains spaces, dashes or other separators, they have to be removed")
+	}
+
+	keyText := string(key)
+	normalizedKey := stripSeparators(keyText)
+	keyHadSeparators := normalizedKey != strings.ToUpper(strings.TrimSpace(keyText))
+
+	// The key as entered can not be decoded, look for typing mistakes
+	if !isHexKey(normalizedKey) {
+		if len(normalizedKey) == 16 && kvv != "" && kvvMatches(rawKvv([]byte(strings.TrimSpace(keyText)), sha256.New), normalizedKvv) {
+			return append(causes, "the key was entered as 16 ASCII characters, it has to be entered as 32 hexadecimal characters")
+		}
+
+		if positions := nonHexPositions(normalizedKey); len(positions) > 0 {
+			cause := fmt.Sprintf("the key contains characters that are not hexadecimal (0-9, A-F) at positions %s", strings.Join(positions, ", "))
+			if fixed := replaceLookalikes(normalizedKey); isHexKey(fixed) && (kvv == "" || kvvMatches(hexKvv(fixed, sha256.New), normalizedKvv)) {
+				cause += ", letters such as O and I appear to have been typed instead of the digits 0 and 1"
+			}
+			causes = append(causes, cause)
+		}
+
+		if len(normalizedKey) != 32 {
+			causes = append(causes, fmt.Sprintf("the key is %d characters long, expected 32 hexadecimal characters", len(normalizedKey)))
+		}
+
+		if keyHadSeparators && len(normalizedKey) == 32 {
+			causes = append(causes, "the key contains spaces, dashes or other separators, they have to be removed")
+		}
+
+		return causes
+	}
+
+	if keyHadSeparators {
+		cause := "the key contains spaces, dashes or other separators, they have to be removed"
+		if kvv != "" && kvvMatches(hexKvv(normalizedKey, sha256.New), normalizedKvv) {
+			cause += " (the key matches the KVV without them)"
+		}
+		causes = append(causes, cause)
+	}
+
+	if kvv == "" {
+		return causes
+	}
+
+	calculated := hexKvv(normalizedKey, sha256.New)
+	if kvvMatches(calculated, normalizedKvv) {
+		return causes
+	}
+
+	switch {
+	case len(normalizedKvv) == 64 && normalizedKvv[:32] == calculated[:32]:
+		causes = append(causes, "only the first 32 characters of the 64 character KVV match, Bankgiro KVVs are the first 32 characters of the HMAC")
+	case len(normalizedKvv) == 32 && normalizedKvv == calculated[32:]:
+		causes = append(causes, "the KVV is the second half of the 64 character HMAC, Bankgiro KVVs are the first 32 characters")
+	case len(normalizedKvv) > 32 && normalizedKvv[:32] == calculated[:32]:
+		causes = append(causes, fmt.Sprintf("the KVV has %d extra characters after the first 32, which match", len(normalizedKvv)-32))
+	case len(normalizedKvv) != 32 && len(normalizedKvv) != 64:
+		causes = append(causes, fmt.Sprintf("the KVV is %d characters long, expected 32 (or the full 64 character HMAC)", len(normalizedKvv)))
+	}
+
+	if swapped := normalizedKey[16:] + normalizedKey[:16]; kvvMatches(hexKvv(swapped, sha256.New), normalizedKvv) {
+		causes = append(causes, "the two halves of the key appear to be swapped")
+	}
+
+	if kvvMatches(rawKvv([]byte(normalizedKey), sha256.New), normalizedKvv) {
+		causes = append(causes, "the KVV was calculated using the key text as ASCII instead of the hex decoded key")
+	}
+
+	for _, hf := range DiagnoseHashFunctions {
+		if kvvMatches(hexKvv(normalizedKey, hf.Hash), normalizedKvv) {
+			causes = append(causes, fmt.Sprintf("the KVV was calculated with %s, Bankgiro uses HMAC-SHA256", hf.Name))
+		}
+	}
+
+	if fixed := replaceLookalikes(strings.ToUpper(strings.TrimSpace(keyText))); fixed != normalizedKey && isHexKey(fixed) && kvvMatches(hexKvv(fixed, sha256.New), normalizedKvv) {
+		causes = append(causes, "letters such as O and I appear to have been typed instead of the digits 0 and 1")
+	}
+
+	if len(causes) == 0 {
+		causes = append(causes, "no typing mistake explains the difference, the key and KVV probably belong to different keys (check the key name, validity and customer number)")
+	}
+
+	return causes
+}
+
+// Remove separators and convert to upper case
+func stripSeparators(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(KeySeparators, r) {
+			return -1
+		}
+		return r
+	}, s)
+
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+func isHexKey(s string) bool {
+	if len(s) != 32 {
+		return false
+	}
+
+	_, err := hex.DecodeString(s)
+
+	return err == nil
+}
+
+func nonHexPositions(s string) []string {
+	positions := []string{}
+	for i, r := range s {
+		if !strings.ContainsRune("0123456789ABCDEF", r) {
+			positions = append(positions, fmt.Sprint(i+1))
+		}
+	}
+
+	return positions
+}
+
+func replaceLookalikes(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if replacement, ok := DiagnoseLookalikes[c]; ok {
+			b[i] = replacement
+		}
+	}
+
+	return string(b)
+}
+
+// Calculate the KVV of a hex encoded key with the given hash function
+func hexKvv(key string, hashFunc func() hash.Hash) string {
+	raw, err := hex.DecodeString(key)
+	if err != nil {
+		return ""
+	}
+
+	return rawKvv(raw, hashFunc)
+}
+
+// Calculate the KVV of a raw key with the given hash function
+func rawKvv(key []byte, hashFunc func() hash.Hash) string {
+	kvv, err := (&SoftwareSigner{Key: key, Hash: hashFunc}).ComputeKVV()
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToUpper(hex.EncodeToString(kvv))
+}
+
+// Check a provided KVV against a calculated KVV, accepting the first 32 characters or the full value
+func kvvMatches(calculated string, provided string) bool {
+	if calculated == "" || len(provided) < 32 {
+		return false
+	}
+
+	if len(provided) == 32 {
+		return calculated[:32] == provided
+	}
+
+	return calculated == provided
+}
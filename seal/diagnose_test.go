@@ -0,0 +1,154 @@
+package seal_test
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/seal"
+)
+
+const DiagnoseTestKey = "00112233445566778899AABBCCDDEEFF"
+
+func testKvv(t *testing.T, key []byte, hashFunc func() hash.Hash) string {
+	kvv, err := (&seal.SoftwareSigner{Key: key, Hash: hashFunc}).ComputeKVV()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.ToUpper(hex.EncodeToString(kvv))
+}
+
+func TestDiagnoseKvv(t *testing.T) {
+	rawKey, _ := hex.DecodeString(DiagnoseTestKey)
+	fullKvv := testKvv(t, rawKey, sha256.New)
+
+	tests := []struct {
+		name  string
+		key   string
+		kvv   string
+		cause string
+	}{
+		{
+			name:  "Matching Key",
+			key:   DiagnoseTestKey,
+			kvv:   fullKvv[:32],
+			cause: "",
+		},
+		{
+			name:  "Spaces in Key",
+			key:   "0011 2233 4455 6677 8899 AABB CCDD EEFF",
+			kvv:   fullKvv[:32],
+			cause: "separators",
+		},
+		{
+			name:  "Dashes in KVV",
+			key:   DiagnoseTestKey,
+			kvv:   fullKvv[:8] + "-" + fullKvv[8:16] + "-" + fullKvv[16:32],
+			cause: "the KVV contains spaces, dashes",
+		},
+		{
+			name:  "Swapped Halves",
+			key:   DiagnoseTestKey[16:] + DiagnoseTestKey[:16],
+			kvv:   fullKvv[:32],
+			cause: "swapped",
+		},
+		{
+			name:  "ASCII Key",
+			key:   "0123456789abcdef",
+			kvv:   testKvv(t, []byte("0123456789abcdef"), sha256.New)[:32],
+			cause: "16 ASCII characters",
+		},
+		{
+			name:  "KVV From Key Text",
+			key:   DiagnoseTestKey,
+			kvv:   testKvv(t, []byte(DiagnoseTestKey), sha256.New)[:32],
+			cause: "key text as ASCII",
+		},
+		{
+			name:  "Second Half of KVV",
+			key:   DiagnoseTestKey,
+			kvv:   fullKvv[32:],
+			cause: "second half",
+		},
+		{
+			name:  "Broken Full KVV",
+			key:   DiagnoseTestKey,
+			kvv:   fullKvv[:32] + strings.Repeat("0", 32),
+			cause: "only the first 32 characters",
+		},
+		{
+			name:  "Wrong KVV Length",
+			key:   DiagnoseTestKey,
+			kvv:   fullKvv[:31],
+			cause: "31 characters long",
+		},
+		{
+			name:  "SHA1 KVV",
+			key:   DiagnoseTestKey,
+			kvv:   testKvv(t, rawKey, sha1.New)[:32],
+			cause: "HMAC-SHA1",
+		},
+		{
+			name:  "Letter O Instead of Zero",
+			key:   "OO112233445566778899AABBCCDDEEFF",
+			kvv:   fullKvv[:32],
+			cause: "letters such as O",
+		},
+		{
+			name:  "Short Key",
+			key:   DiagnoseTestKey[:30],
+			kvv:   fullKvv[:32],
+			cause: "30 characters long",
+		},
+		{
+			name:  "Different Key",
+			key:   "FFEEDDCCBBAA99887766554433221100",
+			kvv:   fullKvv[:32],
+			cause: "different keys",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			causes := seal.DiagnoseKvv([]byte(tt.key), tt.kvv)
+			joined := strings.Join(causes, "\n")
+
+			if tt.cause == "" && len(causes) != 0 {
+				t.Errorf("DiagnoseKvv() = %v, want no causes", causes)
+			}
+			if !strings.Contains(joined, tt.cause) {
+				t.Errorf("DiagnoseKvv() = %v, want cause containing %q", causes, tt.cause)
+			}
+			if strings.Contains(joined, DiagnoseTestKey) {
+				t.Errorf("DiagnoseKvv() leaked the key: %v", causes)
+			}
+		})
+	}
+}
+
+func TestDiagnoseKeyError(t *testing.T) {
+	sealer := seal.HmacSealer{}
+	if err := sealer.SetKey(DiagnoseTestKey[16:] + DiagnoseTestKey[:16]); err != nil {
+		t.Fatal(err)
+	}
+
+	rawKey, _ := hex.DecodeString(DiagnoseTestKey)
+	err := sealer.CheckKvv(testKvv(t, rawKey, sha256.New)[:32])
+
+	var mismatch *seal.KvvMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("CheckKvv() error = %v, want KvvMismatchError", err)
+	}
+
+	diagnosed := seal.DiagnoseKeyError(err, []byte(DiagnoseTestKey[16:]+DiagnoseTestKey[:16]), mismatch.Provided)
+	if !errors.As(diagnosed, &mismatch) {
+		t.Error("diagnosed error should wrap the KvvMismatchError")
+	}
+	if !strings.Contains(diagnosed.Error(), "Likely cause: the two halves of the key appear to be swapped") {
+		t.Errorf("diagnosed error = %s", diagnosed)
+	}
+}
@@ -0,0 +1,300 @@
+package seal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hoglandets-it/go-bankgiro/diff"
+)
+
+// What normalization did to a byte of the input
+type ByteAction string
+
+const (
+	ActionKept      ByteAction = "kept"
+	ActionLineBreak ByteAction = "line-break"
+	ActionMapped    ByteAction = "mapped"
+	ActionReplaced  ByteAction = "replaced"
+	ActionBlankRow  ByteAction = "blank-row"
+	ActionTrimmed   ByteAction = "trimmed"
+	ActionTruncated ByteAction = "truncated"
+)
+
+// Offset: position of the byte in the input
+// OutputOffset: position of the byte in the normalized data, -1 if it was dropped
+type ByteExplanation struct {
+	Offset       int
+	OutputOffset int
+	Input        byte
+	Output       byte
+	Action       ByteAction
+}
+
+// The bytes of an input line, including its line ending, and what they were normalized to
+type LineExplanation struct {
+	Number int
+	Input  []byte
+	Output []byte
+	Bytes  []ByteExplanation
+}
+
+// The normalized data with the origin of every byte
+type Explanation struct {
+	Profile    *NormalizationProfile
+	Lines      []LineExplanation
+	Normalized []byte
+}
+
+// A byte of the content tracked through normalization, origin is -1 for bytes added by a step
+type trackedByte struct {
+	b      byte
+	origin int
+}
+
+// Explain how NormalizeContent normalizes the content with the default profile, byte for byte
+func ExplainNormalization(content []byte) *Explanation {
+	return DefaultProfile.Explain(content)
+}
+
+// Explain how the profile normalizes the content, byte for byte
+// Each step of the normalization is repeated while tracking where the bytes came from
+func (p *NormalizationProfile) Explain(content []byte) *Explanation {
+	actions := make([]ByteAction, len(content))
+	outputs := make([]int, len(content))
+	for i := range outputs {
+		outputs[i] = -1
+	}
+
+	tracked := trackCrlf(content)
+	tracked = trackRemoveBlankRows(tracked, actions)
+	tracked = trackRemoveBlankRows(tracked, actions)
+	tracked = trackTrimRight(tracked, actions)
+	tracked = trackFirstLine(tracked, actions, p.FirstLineLength)
+
+	explanation := &Explanation{Profile: p, Normalized: []byte{}}
+	for _, tb := range tracked {
+		nb := p.NormalizeByte(tb.b)
+		if nb != 0 {
+			explanation.Normalized = append(explanation.Normalized, nb)
+		}
+
+		if tb.origin < 0 {
+			continue
+		}
+
+		switch {
+		case nb == 0:
+			actions[tb.origin] = ActionLineBreak
+		case tb.b >= p.LowerLimit && tb.b <= p.UpperLimit:
+			actions[tb.origin] = ActionKept
+			outputs[tb.origin] = len(explanation.Normalized) - 1
+		case isSpecial(p, tb.b):
+			actions[tb.origin] = ActionMapped
+			outputs[tb.origin] = len(explanation.Normalized) - 1
+		default:
+			actions[tb.origin] = ActionReplaced
+			outputs[tb.origin] = len(explanation.Normalized) - 1
+		}
+	}
+
+	line := LineExplanation{Number: 1}
+	for i, b := range content {
+		be := ByteExplanation{Offset: i, OutputOffset: outputs[i], Input: b, Action: actions[i]}
+		if be.OutputOffset >= 0 {
+			be.Output = explanation.Normalized[be.OutputOffset]
+			line.Output = append(line.Output, be.Output)
+		}
+
+		line.Input = append(line.Input, b)
+		line.Bytes = append(line.Bytes, be)
+
+		// Lines end with LF, or a CR not followed by LF
+		if b == NormLfChar || (b == NormCrChar && (i+1 == len(content) || content[i+1] != NormLfChar)) {
+			explanation.Lines = append(explanation.Lines, line)
+			line = LineExplanation{Number: line.Number + 1}
+		}
+	}
+
+	if len(line.Input) > 0 {
+		explanation.Lines = append(explanation.Lines, line)
+	}
+
+	return explanation
+}
+
+// Convert line endings to CRLF, as tools.EnsureCrlfBytes
+func trackCrlf(content []byte) []trackedByte {
+	tracked := make([]trackedByte, 0, len(content))
+	for i := 0; i < len(content); i++ {
+		switch {
+		case content[i] == NormCrChar && i+1 < len(content) && content[i+1] == NormLfChar:
+			tracked = append(tracked, trackedByte{NormCrChar, i}, trackedByte{NormLfChar, i + 1})
+			i++
+		case content[i] == NormCrChar:
+			tracked = append(tracked, trackedByte{NormCrChar, i}, trackedByte{NormLfChar, -1})
+		case content[i] == NormLfChar:
+			tracked = append(tracked, trackedByte{NormCrChar, -1}, trackedByte{NormLfChar, i})
+		default:
+			tracked = append(tracked, trackedByte{content[i], i})
+		}
+	}
+
+	return tracked
+}
+
+func trackedBytes(tracked []trackedByte) []byte {
+	b := make([]byte, len(tracked))
+	for i, tb := range tracked {
+		b[i] = tb.b
+	}
+
+	return b
+}
+
+// Replace blank rows with a single CRLF, as one pass of RemoveBlankRows
+func trackRemoveBlankRows(tracked []trackedByte, actions []ByteAction) []trackedByte {
+	result := make([]trackedByte, 0, len(tracked))
+
+	last := 0
+	for _, match := range RegexMatchEmptyLines.FindAllIndex(trackedBytes(tracked), -1) {
+		result = append(result, tracked[last:match[0]+2]...)
+		for _, tb := range tracked[match[0]+2 : match[1]] {
+			if tb.origin >= 0 {
+				actions[tb.origin] = ActionBlankRow
+			}
+		}
+		last = match[1]
+	}
+
+	return append(result, tracked[last:]...)
+}
+
+// Trim trailing line breaks and tabs, as RemoveBlankRows
+func trackTrimRight(tracked []trackedByte, actions []ByteAction) []trackedByte {
+	end := len(tracked)
+	for end > 0 && strings.IndexByte("\r\n\t", tracked[end-1].b) >= 0 {
+		end--
+		if tracked[end].origin >= 0 {
+			actions[tracked[end].origin] = ActionTrimmed
+		}
+	}
+
+	return tracked[:end]
+}
+
+// Truncate the first line to the given length, as NormalizeFirstLine
+func trackFirstLine(tracked []trackedByte, actions []ByteAction, length int) []trackedByte {
+	if length == 0 {
+		return tracked
+	}
+
+	end := bytes.Index(trackedBytes(tracked), []byte{NormCrChar, NormLfChar})
+	if end < 0 {
+		end = len(tracked)
+	}
+
+	if end <= length {
+		return tracked
+	}
+
+	for _, tb := range tracked[length:end] {
+		if tb.origin >= 0 {
+			actions[tb.origin] = ActionTruncated
+		}
+	}
+
+	return append(tracked[:length:length], tracked[end:]...)
+}
+
+// Check if normalization did more to the line than dropping its CRLF line break
+// Lines ending with a lone LF or CR are changed, the sealer reads them as CRLF
+func (l *LineExplanation) Changed() bool {
+	lineBreak := []byte{}
+	for _, be := range l.Bytes {
+		if be.Action == ActionLineBreak {
+			lineBreak = append(lineBreak, be.Input)
+		} else if be.Action != ActionKept {
+			return true
+		}
+	}
+
+	return len(lineBreak) > 0 && string(lineBreak) != "\r\n"
+}
+
+// Write an annotated view of the normalization, listing every byte that was not kept as is
+// Only changed lines are written unless all is set, line breaks are summarized per line
+func (e *Explanation) Write(w io.Writer, all bool) error {
+	var out strings.Builder
+
+	for _, line := range e.Lines {
+		if !all && !line.Changed() {
+			continue
+		}
+
+		fmt.Fprintf(&out, "line %d\r\n", line.Number)
+		fmt.Fprintf(&out, "  input:  %s\r\n", diff.Escape(line.Input))
+		fmt.Fprintf(&out, "  output: %s\r\n", diff.Escape(line.Output))
+
+		lineBreaks := []byte{}
+		for start := 0; start < len(line.Bytes); {
+			be := line.Bytes[start]
+			end := start + 1
+			for end < len(line.Bytes) && line.Bytes[end].Action == be.Action && be.Action != ActionMapped && be.Action != ActionReplaced {
+				end++
+			}
+
+			span := line.Bytes[start:end]
+			input := make([]byte, len(span))
+			for i, b := range span {
+				input[i] = b.Input
+			}
+
+			column := fmt.Sprintf("col %d", start+1)
+			if end-start > 1 {
+				column = fmt.Sprintf("col %d-%d", start+1, end)
+			}
+
+			switch be.Action {
+			case ActionLineBreak:
+				lineBreaks = append(lineBreaks, input...)
+			case ActionMapped:
+				fmt.Fprintf(&out, "  %s: %s (%d) mapped to %s (%d)\r\n", column, diff.Escape(input), be.Input, diff.Escape([]byte{be.Output}), be.Output)
+			case ActionReplaced:
+				fmt.Fprintf(&out, "  %s: %s (%d) outside %d-%d, replaced with %d\r\n", column, diff.Escape(input), be.Input, e.Profile.LowerLimit, e.Profile.UpperLimit, e.Profile.Replacement)
+			case ActionBlankRow:
+				fmt.Fprintf(&out, "  %s: \"%s\" removed with a blank row\r\n", column, diff.Escape(input))
+			case ActionTrimmed:
+				fmt.Fprintf(&out, "  %s: \"%s\" trimmed from the end of the file\r\n", column, diff.Escape(input))
+			case ActionTruncated:
+				fmt.Fprintf(&out, "  %s: \"%s\" truncated, the first line is limited to %d characters\r\n", column, diff.Escape(input), e.Profile.FirstLineLength)
+			}
+
+			start = end
+		}
+
+		if len(lineBreaks) > 0 {
+			fmt.Fprintf(&out, "  line break %s dropped\r\n", diff.Escape(lineBreaks))
+		}
+	}
+
+	fmt.Fprintf(&out, "%d bytes normalized into %d bytes\r\n", e.inputLength(), len(e.Normalized))
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func (e *Explanation) inputLength() int {
+	length := 0
+	for _, line := range e.Lines {
+		length += len(line.Input)
+	}
+
+	return length
+}
+
+func isSpecial(p *NormalizationProfile, b byte) bool {
+	replacement, found := p.special(b)
+	return found && replacement != 0
+}
@@ -0,0 +1,94 @@
+package seal_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/seal"
+)
+
+func TestExplainNormalizationMatches(t *testing.T) {
+	files, err := filepath.Glob("../tests/*/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := [][]byte{
+		[]byte(""),
+		[]byte("\r\n\r\n"),
+		[]byte("  \r\n01\n\n \t\n82\r99  \t\r\n\r\n"),
+		[]byte(strings.Repeat("A", 100) + "\r\n" + strings.Repeat("B", 100)),
+		[]byte("01\xc4\xe9\x00\x7f\r\n\t\r\n   \r\n82"),
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, content)
+	}
+
+	for i, input := range inputs {
+		explanation := seal.ExplainNormalization(input)
+		if !bytes.Equal(explanation.Normalized, seal.NormalizeContent(input)) {
+			t.Errorf("input %d: explanation does not match NormalizeContent", i)
+		}
+
+		joined := []byte{}
+		for _, line := range explanation.Lines {
+			joined = append(joined, line.Input...)
+		}
+		if !bytes.Equal(joined, input) {
+			t.Errorf("input %d: lines do not cover the input", i)
+		}
+	}
+}
+
+func TestExplainNormalization(t *testing.T) {
+	input := []byte(strings.Repeat("A", 82) + "\r\n\xc4\xe8B\r\n  \r\nC\r\n\t")
+	explanation := seal.ExplainNormalization(input)
+
+	actions := map[int]seal.ByteAction{}
+	for _, line := range explanation.Lines {
+		for _, be := range line.Bytes {
+			actions[be.Offset] = be.Action
+		}
+	}
+
+	tests := []struct {
+		offset int
+		action seal.ByteAction
+	}{
+		{0, seal.ActionKept},
+		{80, seal.ActionTruncated},
+		{81, seal.ActionTruncated},
+		{82, seal.ActionLineBreak},
+		{84, seal.ActionMapped},
+		{85, seal.ActionReplaced},
+		{86, seal.ActionKept},
+		{89, seal.ActionBlankRow},
+		{94, seal.ActionTrimmed},
+	}
+
+	for _, test := range tests {
+		if actions[test.offset] != test.action {
+			t.Errorf("byte %d: expected %s, got %s", test.offset, test.action, actions[test.offset])
+		}
+	}
+
+	var out bytes.Buffer
+	explanation.Write(&out, false)
+	for _, expected := range []string{"col 81-82", `\xC4 (196) mapped to [ (91)`, `\xE8 (232) outside 32-126, replaced with 195`, "removed with a blank row"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("explanation does not contain %q:\n%s", expected, out.String())
+		}
+	}
+
+	lines := seal.ExplainNormalization([]byte("01\r\n82\xc4")).Lines
+	if len(lines) != 2 || lines[0].Changed() || !lines[1].Changed() {
+		t.Errorf("unexpected changed lines: %+v", lines)
+	}
+}
@@ -18,6 +18,10 @@ const NormOutOfRangeReplacement = 195
 
 var RegexMatchEmptyLines = regexp.MustCompile(`\r\n[\s\t]*\r\n`)
 
+// The special character table of the bankgiro profile, DefaultProfile reads it when normalizing
+//
+// Deprecated: changing the table changes every seal made with DefaultProfile.
+// Use a NormalizationProfile to normalize with other replacements.
 var NormSpecialReplacement map[int]int = map[int]int{
 	201: 64,
 	196: 91,
@@ -31,48 +35,19 @@ var NormSpecialReplacement map[int]int = map[int]int{
 	252: 126,
 }
 
-// Normalize a single character
+// Normalize a single character with the default profile
 func NormalizeByte(b byte) byte {
-	intChar := int(b)
-
-	// Carriage returns and line feeds should be entirely ignored
-	if NormLfChar == intChar || NormCrChar == intChar {
-		return byte(0)
-	}
-
-	// Values outside of the printable ASCII range should be replaced
-	if intChar < NormLowerLimit || intChar > NormUpperLimit {
-
-		// Some characters want special replacements, others are replaced with a generic replacement char (195)
-		if NormSpecialReplacement[intChar] != 0 {
-			return byte(NormSpecialReplacement[intChar])
-		}
-
-		return byte(195)
-	}
-
-	return b
+	return DefaultProfile.NormalizeByte(b)
 }
 
-// Normalize a range of bytes
+// Normalize a range of bytes with the default profile
 func NormalizeBytes(b []byte, buf *bytes.Buffer) {
-	for _, ub := range b {
-		nb := NormalizeByte(ub)
-		if nb != 0 {
-			buf.WriteByte(nb)
-		}
-	}
+	DefaultProfile.NormalizeBytes(b, buf)
 }
 
 // Ensure that the first line of the content is a maximum of 80 characters long
 func NormalizeFirstLine(b []byte) []byte {
-	lines := bytes.Split(b, []byte{NormCrChar, NormLfChar})
-	if len(lines) == 0 || len(lines[0]) <= 80 {
-		return b
-	}
-
-	lines[0] = lines[0][:80]
-	return bytes.Join(lines, []byte{NormCrChar, NormLfChar})
+	return DefaultProfile.NormalizeFirstLine(b)
 }
 
 func RemoveBlankRows(b []byte) []byte {
@@ -95,20 +70,102 @@ func FormatContent(b []byte) []byte {
 	return fmtContent
 }
 
+// Format the content as FormatContent does, in a single pass
+// Line breaks are converted to CRLF, whitespace-only rows other than the first and last are removed
+// and trailing CR, LF and tab characters trimmed
+// The content is returned as is, without copying, when it is already formatted
+func FormatBytes(b []byte) []byte {
+	if isFormatted(b) {
+		return b
+	}
+
+	// Usually only the trailing line break has to go, which needs no copy
+	if trimmed := bytes.TrimRight(b, "\r\n\t"); isFormatted(trimmed) {
+		if start := bytes.LastIndexByte(trimmed, NormLfChar) + 1; start == 0 || !isBlank(trimmed[start:]) {
+			return trimmed
+		}
+	}
+
+	out := make([]byte, 0, len(b)+len(b)/40)
+	first := true
+
+	for start := 0; start <= len(b); {
+		end, next := lineEnd(b, start)
+		line := b[start:end]
+
+		// Whitespace-only rows between two line breaks are removed
+		if first || next > len(b) || !isBlank(line) {
+			if !first {
+				out = append(out, NormCrChar, NormLfChar)
+			}
+			out = append(out, line...)
+			first = false
+		}
+
+		start = next
+	}
+
+	return bytes.TrimRight(out, "\r\n\t")
+}
+
+// Find the end of the line starting at start, and the start of the next line, past the end of b for the last line
+func lineEnd(b []byte, start int) (end int, next int) {
+	i := bytes.IndexAny(b[start:], "\r\n")
+	if i < 0 {
+		return len(b), len(b) + 1
+	}
+
+	end = start + i
+	if b[end] == NormCrChar && end+1 < len(b) && b[end+1] == NormLfChar {
+		return end, end + 2
+	}
+
+	return end, end + 1
+}
+
+// Check if the row only has the whitespace matched by RegexMatchEmptyLines
+func isBlank(line []byte) bool {
+	for _, c := range line {
+		if c != ' ' && c != '\t' && c != '\f' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Check if FormatBytes would leave the content unchanged
+func isFormatted(b []byte) bool {
+	if len(bytes.TrimRight(b, "\r\n\t")) != len(b) {
+		return false
+	}
+
+	for start := 0; start <= len(b); {
+		end, next := lineEnd(b, start)
+
+		if end < len(b) && (b[end] != NormCrChar || next != end+2) {
+			return false
+		}
+
+		if start > 0 && next <= len(b) && isBlank(b[start:end]) {
+			return false
+		}
+
+		start = next
+	}
+
+	return true
+}
+
 func FormatContentString(s string) string {
 	fmtContent := tools.EnsureCrlfString(s)
 	fmtContent = RemoveBlankRowsString(fmtContent)
 	return fmtContent
 }
 
+// Normalize the content for HMAC calculation with the default profile
 func NormalizeContent(content []byte) []byte {
-	var buf bytes.Buffer
-	fmtContent := FormatContent(content)
-	fmtContent = NormalizeFirstLine(fmtContent)
-
-	NormalizeBytes(fmtContent, &buf)
-
-	return buf.Bytes()
+	return DefaultProfile.NormalizeContent(content)
 }
 
 func NormalizeContentString(content string) []byte {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"math/rand"
 	"os"
 	"testing"
 
@@ -145,3 +146,42 @@ func TestFileNormalization(t *testing.T) {
 	}
 
 }
+
+func TestFormatBytesMatchesFormatContent(t *testing.T) {
+	alphabet := []byte{'a', '0', ' ', '\t', '\f', '\v', '\r', '\n', 0xC4}
+	random := rand.New(rand.NewSource(1))
+
+	inputs := [][]byte{
+		{},
+		[]byte("\r\n"),
+		[]byte("a\r\n \r\n\r\nb\n\n"),
+		[]byte("\r\n\r\na"),
+		[]byte("a\r\n\t\r\n  "),
+	}
+	for i := 0; i < 20000; i++ {
+		input := make([]byte, random.Intn(24))
+		for j := range input {
+			input[j] = alphabet[random.Intn(len(alphabet))]
+		}
+		inputs = append(inputs, input)
+	}
+
+	for _, input := range inputs {
+		expected := seal.FormatContent(input)
+		if got := seal.FormatBytes(input); !bytes.Equal(got, expected) {
+			t.Fatalf("FormatBytes(%q) = %q, expected %q", input, got, expected)
+		}
+
+		normalized := seal.NormalizeContent(input)
+		if got := seal.DefaultProfile.NormalizeFormatted(seal.FormatBytes(input)); !bytes.Equal(got, normalized) {
+			t.Fatalf("NormalizeFormatted(%q) = %q, expected %q", input, got, normalized)
+		}
+	}
+}
+
+func TestFormatBytesNoCopy(t *testing.T) {
+	input := []byte("0120240416AUTOGIRO\r\n82202404220")
+	if got := seal.FormatBytes(input); &got[0] != &input[0] {
+		t.Error("expected formatted content to be returned without copying")
+	}
+}
@@ -0,0 +1,248 @@
+package seal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// The rules used to normalize content before calculating the HMAC
+// LowerLimit/UpperLimit: the range of bytes kept as is
+// Replacement: the byte replacing bytes outside the range without a special replacement
+// Special: replacements for bytes outside the range
+// FirstLineLength: the maximum length of the first line, 0 for no limit
+type NormalizationProfile struct {
+	Name            string        `json:"name"`
+	Description     string        `json:"description,omitempty"`
+	LowerLimit      byte          `json:"lowerLimit"`
+	UpperLimit      byte          `json:"upperLimit"`
+	Replacement     byte          `json:"replacement"`
+	Special         map[byte]byte `json:"special"`
+	FirstLineLength int           `json:"firstLineLength"`
+}
+
+// The normalization rules published by Bankgirot, used unless another profile is selected
+// It is the only built-in profile, other rules are loaded from JSON profile files or registered with RegisterProfile
+// Its special replacements are read from NormSpecialReplacement when normalizing, so changes to that table still apply.
+// Special holds the table as published by Bankgirot
+var DefaultProfile = &NormalizationProfile{
+	Name:            "bankgiro",
+	Description:     "Bankgirot HMAC seal rules: printable ASCII kept, Swedish letters mapped, other bytes replaced with 195",
+	LowerLimit:      NormLowerLimit,
+	UpperLimit:      NormUpperLimit,
+	Replacement:     NormOutOfRangeReplacement,
+	Special:         specialBytes(NormSpecialReplacement),
+	FirstLineLength: 80,
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]*NormalizationProfile{DefaultProfile.Name: DefaultProfile}
+)
+
+func specialBytes(special map[int]int) map[byte]byte {
+	converted := make(map[byte]byte, len(special))
+	for from, to := range special {
+		converted[byte(from)] = byte(to)
+	}
+
+	return converted
+}
+
+// Register a profile so it can be selected by name
+func RegisterProfile(profile *NormalizationProfile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	if _, found := profiles[profile.Name]; found {
+		return fmt.Errorf("normalization profile %s is already registered", profile.Name)
+	}
+
+	profiles[profile.Name] = profile
+
+	return nil
+}
+
+// Get a registered profile by name
+func GetProfile(name string) (*NormalizationProfile, error) {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	profile, found := profiles[name]
+	if !found {
+		return nil, fmt.Errorf("unknown normalization profile: %s", name)
+	}
+
+	return profile, nil
+}
+
+// Get the registered profiles sorted by name
+func Profiles() []*NormalizationProfile {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	list := make([]*NormalizationProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		list = append(list, profile)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// Read a profile from a JSON file
+func LoadProfile(path string) (*NormalizationProfile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &NormalizationProfile{}
+	if err := json.Unmarshal(content, profile); err != nil {
+		return nil, fmt.Errorf("invalid normalization profile %s: %w", path, err)
+	}
+
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid normalization profile %s: %w", path, err)
+	}
+
+	return profile, nil
+}
+
+// Get a registered profile by name, or read it from a JSON file if no profile has the name
+func FindProfile(nameOrPath string) (*NormalizationProfile, error) {
+	if profile, err := GetProfile(nameOrPath); err == nil {
+		return profile, nil
+	}
+
+	if _, err := os.Stat(nameOrPath); err != nil {
+		return nil, fmt.Errorf("unknown normalization profile: %s", nameOrPath)
+	}
+
+	return LoadProfile(nameOrPath)
+}
+
+// Check that the profile can be used to normalize content
+// Line breaks are always dropped, so they may not be kept or be the result of a replacement
+func (p *NormalizationProfile) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+
+	if p.LowerLimit > p.UpperLimit {
+		return fmt.Errorf("lower limit %d is above upper limit %d", p.LowerLimit, p.UpperLimit)
+	}
+
+	if p.LowerLimit <= NormCrChar && p.UpperLimit >= NormLfChar {
+		return fmt.Errorf("line breaks must be outside the range %d-%d", p.LowerLimit, p.UpperLimit)
+	}
+
+	if p.Replacement == 0 || p.Replacement == NormLfChar || p.Replacement == NormCrChar {
+		return fmt.Errorf("invalid replacement byte: %d", p.Replacement)
+	}
+
+	for from, to := range p.Special {
+		if to == 0 || to == NormLfChar || to == NormCrChar {
+			return fmt.Errorf("invalid replacement for %d: %d", from, to)
+		}
+	}
+
+	if p.FirstLineLength < 0 {
+		return fmt.Errorf("invalid first line length: %d", p.FirstLineLength)
+	}
+
+	return nil
+}
+
+// Normalize a single character, line breaks are dropped and returned as 0
+func (p *NormalizationProfile) NormalizeByte(b byte) byte {
+	if b == NormLfChar || b == NormCrChar {
+		return 0
+	}
+
+	if b >= p.LowerLimit && b <= p.UpperLimit {
+		return b
+	}
+
+	if replacement, found := p.special(b); found {
+		return replacement
+	}
+
+	return p.Replacement
+}
+
+// Get the special replacement for a byte outside the range
+// The default profile reads NormSpecialReplacement, which callers may still change
+func (p *NormalizationProfile) special(b byte) (byte, bool) {
+	if p == DefaultProfile {
+		replacement, found := NormSpecialReplacement[int(b)]
+		return byte(replacement), found
+	}
+
+	replacement, found := p.Special[b]
+	return replacement, found
+}
+
+// Normalize a range of bytes
+func (p *NormalizationProfile) NormalizeBytes(b []byte, buf *bytes.Buffer) {
+	for _, ub := range b {
+		if nb := p.NormalizeByte(ub); nb != 0 {
+			buf.WriteByte(nb)
+		}
+	}
+}
+
+// Ensure that the first line of the content is at most FirstLineLength characters long
+func (p *NormalizationProfile) NormalizeFirstLine(b []byte) []byte {
+	if p.FirstLineLength == 0 {
+		return b
+	}
+
+	lines := bytes.Split(b, []byte{NormCrChar, NormLfChar})
+	if len(lines) == 0 || len(lines[0]) <= p.FirstLineLength {
+		return b
+	}
+
+	lines[0] = lines[0][:p.FirstLineLength]
+	return bytes.Join(lines, []byte{NormCrChar, NormLfChar})
+}
+
+// Normalize the content for HMAC calculation
+func (p *NormalizationProfile) NormalizeContent(content []byte) []byte {
+	var buf bytes.Buffer
+	fmtContent := FormatContent(content)
+	fmtContent = p.NormalizeFirstLine(fmtContent)
+
+	p.NormalizeBytes(fmtContent, &buf)
+
+	return buf.Bytes()
+}
+
+// Normalize content already formatted with FormatContent or FormatBytes, in a single pass
+// The first line is cut to FirstLineLength and every byte normalized, as NormalizeContent does
+func (p *NormalizationProfile) NormalizeFormatted(formatted []byte) []byte {
+	firstLine := bytes.Index(formatted, []byte{NormCrChar, NormLfChar})
+	if firstLine < 0 {
+		firstLine = len(formatted)
+	}
+
+	out := make([]byte, 0, len(formatted))
+	for i, b := range formatted {
+		if p.FirstLineLength > 0 && i >= p.FirstLineLength && i < firstLine {
+			continue
+		}
+
+		if nb := p.NormalizeByte(b); nb != 0 {
+			out = append(out, nb)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,231 @@
+package seal_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/seal"
+)
+
+const (
+	ConformanceKey = "1234567890ABCDEF1234567890ABCDEF"
+	ConformanceKvv = "FF365893D899291C3BF505FB3175E880"
+)
+
+// The test vectors every registered profile must pass
+// KVV: the key verification value for ConformanceKey
+// Bytes: single bytes and their normalized value
+// SealedFiles: sealed fixtures whose MAC must be reproduced from the content before the trailer row
+type profileVectors struct {
+	Kvv         string
+	Bytes       map[byte]byte
+	SealedFiles []string
+}
+
+var ConformanceVectors = map[string]profileVectors{
+	"bankgiro": {
+		Kvv: ConformanceKvv,
+		Bytes: map[byte]byte{
+			' ':  ' ',
+			'A':  'A',
+			'~':  '~',
+			0x00: 195,
+			0x1F: 195,
+			0x7F: 195,
+			0xC9: '@',  // É
+			0xC4: '[',  // Ä
+			0xD6: '\\', // Ö
+			0xC5: ']',  // Å
+			0xDC: '^',  // Ü
+			0xE9: '`',  // é
+			0xE4: '{',  // ä
+			0xF6: '|',  // ö
+			0xE5: '}',  // å
+			0xFC: '~',  // ü
+			0xE8: 195,
+			0xFF: 195,
+		},
+		SealedFiles: []string{
+			"../tests/sealFile/basic-signed.txt",
+			"../tests/sealFile/blank-rows-signed.txt",
+		},
+	},
+}
+
+func TestProfileConformance(t *testing.T) {
+	for _, profile := range seal.Profiles() {
+		t.Run(profile.Name, func(t *testing.T) {
+			vectors, found := ConformanceVectors[profile.Name]
+			if !found {
+				t.Fatalf("no conformance vectors for profile %s", profile.Name)
+			}
+
+			hm := seal.HmacSealer{}
+			hm.SetProfile(profile)
+			if err := hm.SetKey(ConformanceKey); err != nil {
+				t.Fatal(err)
+			}
+			if err := hm.CheckKvv(vectors.Kvv); err != nil {
+				t.Error(err)
+			}
+
+			for from, to := range vectors.Bytes {
+				if got := profile.NormalizeByte(from); got != to {
+					t.Errorf("NormalizeByte(%d) = %d, expected %d", from, got, to)
+				}
+			}
+
+			for _, file := range vectors.SealedFiles {
+				content, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				trailerStart := bytes.LastIndex(bytes.TrimRight(content, "\r\n"), []byte("\n")) + 1
+				trailer := string(content[trailerStart:])
+
+				hm := seal.HmacSealer{}
+				hm.SetProfile(profile)
+				hm.SetKey(ConformanceKey)
+				hm.SetSealDate(trailer[2:8])
+				hm.SetDataBytes(content[:trailerStart])
+				if err := hm.Calculate(); err != nil {
+					t.Fatal(err)
+				}
+
+				if expected := trailer[40:72]; hm.GetMacBgFormat() != expected {
+					t.Errorf("%s: MAC %s, expected %s", file, hm.GetMacBgFormat(), expected)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultProfileMatchesNormalize(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		if got, expected := seal.DefaultProfile.NormalizeByte(byte(i)), seal.NormalizeByte(byte(i)); got != expected {
+			t.Errorf("NormalizeByte(%d) = %d, expected %d", i, got, expected)
+		}
+	}
+}
+
+func TestDefaultProfileReadsSpecialReplacement(t *testing.T) {
+	// é (233) is mapped to ` (96) by Bankgirot
+	defer func() { seal.NormSpecialReplacement[233] = 96 }()
+
+	seal.NormSpecialReplacement[233] = 'e'
+	if got := seal.DefaultProfile.NormalizeByte(233); got != 'e' {
+		t.Errorf("NormalizeByte(233) = %d, expected the changed replacement %d", got, 'e')
+	}
+	if got := seal.NormalizeContent([]byte("caf\xe9")); string(got) != "cafe" {
+		t.Errorf("NormalizeContent() = %q, expected the changed replacement", got)
+	}
+
+	delete(seal.NormSpecialReplacement, 233)
+	if got := seal.DefaultProfile.NormalizeByte(233); got != seal.NormOutOfRangeReplacement {
+		t.Errorf("NormalizeByte(233) = %d, expected the removed replacement to fall back to %d", got, seal.NormOutOfRangeReplacement)
+	}
+
+	// Profiles copied from the default use their own table
+	custom := *seal.DefaultProfile
+	if got := custom.NormalizeByte(233); got != 96 {
+		t.Errorf("copied profile NormalizeByte(233) = %d, expected 96", got)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			content: `{"name":"strict-ascii","lowerLimit":32,"upperLimit":126,"replacement":63,"special":{},"firstLineLength":80}`,
+		},
+		{
+			name:    "no name",
+			content: `{"lowerLimit":32,"upperLimit":126,"replacement":63}`,
+			wantErr: "profile name is required",
+		},
+		{
+			name:    "line breaks kept",
+			content: `{"name":"x","lowerLimit":0,"upperLimit":126,"replacement":63}`,
+			wantErr: "line breaks must be outside the range",
+		},
+		{
+			name:    "line break replacement",
+			content: `{"name":"x","lowerLimit":32,"upperLimit":126,"replacement":63,"special":{"196":10}}`,
+			wantErr: "invalid replacement for 196",
+		},
+		{
+			name:    "invalid json",
+			content: `{"name":`,
+			wantErr: "invalid normalization profile",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profile.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			profile, err := seal.FindProfile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("FindProfile() error = %v, expected %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := profile.NormalizeContent([]byte("\xc5\xc4\xd6\x01abc")); string(got) != "????abc" {
+				t.Errorf("NormalizeContent() = %q", got)
+			}
+		})
+	}
+}
+
+func TestProfileChangesMac(t *testing.T) {
+	content := []byte("00240429HMAC" + strings.Repeat(" ", 68) + "\r\n01 \xc5\xc4\xd6\r\n")
+
+	mac := func(profile *seal.NormalizationProfile) string {
+		hm := seal.HmacSealer{}
+		hm.SetProfile(profile)
+		hm.SetKey(ConformanceKey)
+		hm.SetDataBytes(content)
+		if err := hm.Calculate(); err != nil {
+			t.Fatal(err)
+		}
+		return hm.GetMac()
+	}
+
+	custom := *seal.DefaultProfile
+	custom.Name = "custom"
+	custom.Special = map[byte]byte{}
+
+	if mac(seal.DefaultProfile) == mac(&custom) {
+		t.Error("expected the profile to change the MAC")
+	}
+}
+
+func TestFindProfile(t *testing.T) {
+	profile, err := seal.FindProfile("bankgiro")
+	if err != nil || profile != seal.DefaultProfile {
+		t.Errorf("FindProfile(bankgiro) = %v, %v", profile, err)
+	}
+
+	if _, err := seal.FindProfile("does-not-exist"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+
+	if err := seal.RegisterProfile(seal.DefaultProfile); err == nil {
+		t.Error("expected an error when registering a profile twice")
+	}
+}
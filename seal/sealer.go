@@ -2,24 +2,25 @@ package seal
 
 import (
 	"bytes"
-	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"hash"
 	"strings"
 	"time"
-
-	"github.com/hoglandets-it/go-bankgiro/tools"
 )
 
 // Key: the hex-decoded HMAC key used to seal the file
 // KeyVer (KVV, KeyVerificationValue) is the the value used to verify the key, obtained by sealing the string "00000000"
 // HashFunc: the hash function used to calculate the HMAC seal, default is sha256
+// Signer: an external signer used instead of Key/HashFunc, e.g. a PKCS#11 HSM
+// Profile: the normalization rules, default is DefaultProfile
 type HmacSealer struct {
 	Key            []byte
 	KeyVer         []byte
 	Hash           func() hash.Hash
+	Signer         Signer
+	Profile        *NormalizationProfile
 	Mac            []byte
 	SealDate       string
 	OriginalData   []byte
@@ -47,6 +48,29 @@ func (hm *HmacSealer) SetHashFunction(hashFunc func() hash.Hash) error {
 	return nil
 }
 
+// Set the normalization rules used to calculate the seal
+func (hm *HmacSealer) SetProfile(profile *NormalizationProfile) error {
+	if err := hm.EnsureNoSignature(); err != nil {
+		return err
+	}
+
+	hm.Profile = profile
+	if len(hm.OriginalData) == 0 {
+		return nil
+	}
+
+	return hm.UpdateFormatted()
+}
+
+// Get the normalization rules used to calculate the seal
+func (hm *HmacSealer) GetProfile() *NormalizationProfile {
+	if hm.Profile == nil {
+		return DefaultProfile
+	}
+
+	return hm.Profile
+}
+
 // Set the key used to seal the file
 func (hm *HmacSealer) SetKey(key string) error {
 	return hm.SetKeyBytes([]byte(key))
@@ -58,35 +82,78 @@ func (hm *HmacSealer) SetKeyBytes(key []byte) error {
 		return err
 	}
 
+	hm.ClearKey()
+	if hex.DecodedLen(len(key)) > 16 {
+		return fmt.Errorf("invalid key length: %d, expected 16", hex.DecodedLen(len(key)))
+	}
+
 	hm.Key = make([]byte, 16)
 	hexLen, err := hex.Decode(hm.Key, key)
 	if err != nil {
+		hm.ClearKey()
 		return fmt.Errorf("invalid key provided: %v", err)
 	}
 
 	if hexLen != 16 {
+		hm.ClearKey()
 		return fmt.Errorf("invalid key length: %d, expected 16", hexLen)
 	}
 
 	return hm.GenerateKvv()
 }
 
-// Generate and store the KVV Value
-func (hm *HmacSealer) GenerateKvv() error {
-	if hm.Key == nil {
-		return fmt.Errorf("key not set")
+// Use an external signer instead of an in-memory key
+func (hm *HmacSealer) SetSigner(signer Signer) error {
+	if err := hm.EnsureNoSignature(); err != nil {
+		return err
 	}
 
-	kvvCalcValue := []byte("00000000")
+	hm.ClearKey()
+	hm.Signer = signer
+
+	return hm.GenerateKvv()
+}
+
+// Get the signer used to calculate the seal
+// Without an external signer, a SoftwareSigner using Key and Hash is returned
+func (hm *HmacSealer) GetSigner() Signer {
+	if hm.Signer != nil {
+		return hm.Signer
+	}
 
 	if hm.Hash == nil {
 		hm.SetHashFunction(sha256.New)
 	}
 
-	hmhash := hmac.New(hm.Hash, hm.Key)
-	hmhash.Write(kvvCalcValue)
+	return &SoftwareSigner{Key: hm.Key, Hash: hm.Hash}
+}
+
+// Check if a key or signer is available to seal with
+func (hm *HmacSealer) HasKey() bool {
+	return hm.Signer != nil || len(hm.Key) != 0
+}
+
+// Overwrite the key in memory with zeroes and remove it from the sealer
+// The calculated MAC and KVV are kept, so the signed content can still be retrieved
+func (hm *HmacSealer) ClearKey() {
+	for i := range hm.Key {
+		hm.Key[i] = 0
+	}
+	hm.Key = nil
+}
+
+// Generate and store the KVV Value
+func (hm *HmacSealer) GenerateKvv() error {
+	if !hm.HasKey() {
+		return fmt.Errorf("key not set")
+	}
+
+	kvv, err := hm.GetSigner().ComputeKVV()
+	if err != nil {
+		return fmt.Errorf("could not calculate kvv: %w", err)
+	}
 
-	hm.KeyVer = hmhash.Sum([]byte{})
+	hm.KeyVer = kvv
 
 	return nil
 }
@@ -104,14 +171,14 @@ func (hm *HmacSealer) CheckKvv(kvv string) error {
 		if providedKvvHex == storedKvvHex {
 			return nil
 		}
-		return fmt.Errorf("provided and calculated kvv do not match:\r\nProvided:   %s\r\nCalculated: %s", providedKvvHex, storedKvvHex)
+		return &KvvMismatchError{Provided: providedKvvHex, Calculated: storedKvvHex}
 	}
 
 	if len(kvv) == 32 {
 		if providedKvvHex == storedKvvHex[0:32] {
 			return nil
 		}
-		return fmt.Errorf("provided and calculated kvv do not match:\r\nProvided:   %s\r\nCalculated: %s", providedKvvHex, storedKvvHex[0:32])
+		return &KvvMismatchError{Provided: providedKvvHex, Calculated: storedKvvHex[0:32]}
 	}
 
 	return fmt.Errorf("invalid KVV length: %d, expected 32 or 64", len(kvv))
@@ -139,8 +206,8 @@ func (hm *HmacSealer) UpdateFormatted() error {
 		hm.SealDate = time.Now().Format("060102")
 	}
 	hm.PrefixedData = PrefixContent(hm.OriginalData, hm.SealDate)
-	hm.FormattedData = FormatContent(hm.PrefixedData)
-	hm.NormalizedData = NormalizeContent(hm.PrefixedData)
+	hm.FormattedData = FormatBytes(hm.PrefixedData)
+	hm.NormalizedData = hm.GetProfile().NormalizeFormatted(hm.FormattedData)
 
 	return nil
 }
@@ -198,7 +265,7 @@ func (hm *HmacSealer) Validate() (err error) {
 		return fmt.Errorf("verification failed: no data present to be signed")
 	}
 
-	if len(hm.Key) == 0 {
+	if !hm.HasKey() {
 		return fmt.Errorf("verification failed: no key present to sign data")
 	}
 
@@ -211,14 +278,12 @@ func (hm *HmacSealer) Calculate() (err error) {
 		return err
 	}
 
-	if hm.Hash == nil {
-		hm.SetHashFunction(sha256.New)
+	mac, err := hm.GetSigner().ComputeMAC(hm.NormalizedData)
+	if err != nil {
+		return fmt.Errorf("could not calculate mac: %w", err)
 	}
 
-	hmhash := hmac.New(hm.Hash, hm.Key)
-	hmhash.Write(hm.NormalizedData)
-
-	hm.Mac = hmhash.Sum([]byte{})
+	hm.Mac = mac
 
 	return
 }
@@ -248,22 +313,30 @@ func (hm *HmacSealer) GetKvvBgFormat() string {
 	return hm.GetKvv()[0:32]
 }
 
-func (hm *HmacSealer) GetSignedContent() string {
-	signature := fmt.Sprintf(
+// Get the 99 trailer row carrying the seal date, KVV and MAC
+// The trailer is dated with SealDate like the HMAC header, not with the day it is written
+func (hm *HmacSealer) Trailer() []byte {
+	return []byte(fmt.Sprintf(
 		"99%s%s%s%s",
-		time.Now().Format("060102"),
+		hm.SealDate,
 		hm.GetKvvBgFormat(),
 		hm.GetMacBgFormat(),
 		strings.Repeat(" ", 8),
-	)
-
-	return tools.StringEnsureIso(
-		strings.Join(
-			[]string{
-				string(hm.PrefixedData),
-				signature,
-			},
-			"\r\n",
-		),
-	)
+	))
+}
+
+// Get the sealed content, the data with the HMAC header followed by the trailer row
+func (hm *HmacSealer) GetSignedBytes() []byte {
+	trailer := hm.Trailer()
+
+	signed := make([]byte, 0, len(hm.PrefixedData)+2+len(trailer))
+	signed = append(signed, hm.PrefixedData...)
+	signed = append(signed, NormCrChar, NormLfChar)
+
+	return append(signed, trailer...)
+}
+
+// Get the sealed content as a string
+func (hm *HmacSealer) GetSignedContent() string {
+	return string(hm.GetSignedBytes())
 }
@@ -495,3 +495,25 @@ func TestHmacSealer_Calculate(t *testing.T) {
 		})
 	}
 }
+
+func TestHmacSealer_Trailer(t *testing.T) {
+	hm := &HmacSealer{}
+	if err := hm.SetKey("1234567890ABCDEF1234567890ABCDEF"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.SetDataBytes([]byte("0120240416AUTOGIRO")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A back-dated seal carries the seal date in the trailer as well as in the header
+	if err := hm.SetSealDate("240101"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if trailer := string(hm.Trailer()); trailer[:8] != "99240101" || len(trailer) != 80 {
+		t.Errorf("Trailer() = %q, expected the seal date 240101", trailer)
+	}
+}
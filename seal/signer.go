@@ -0,0 +1,67 @@
+package seal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+)
+
+// The value sealed to obtain the KVV (KeyVerificationValue)
+const KvvCalcValue = "00000000"
+
+// Signer calculates the HMAC values used to seal a file
+// Implementations can keep the key outside of process memory, e.g. in an HSM or KMS
+type Signer interface {
+	// Calculate the HMAC of the normalized data
+	ComputeMAC(data []byte) ([]byte, error)
+	// Calculate the KVV, the HMAC of KvvCalcValue
+	ComputeKVV() ([]byte, error)
+}
+
+// The default Signer, calculating the HMAC in software with a key held in memory
+type SoftwareSigner struct {
+	Key  []byte
+	Hash func() hash.Hash
+}
+
+// Calculate the HMAC of the given data with the in-memory key
+func (s *SoftwareSigner) ComputeMAC(data []byte) ([]byte, error) {
+	if len(s.Key) == 0 {
+		return nil, fmt.Errorf("key not set")
+	}
+
+	hashFunc := s.Hash
+	if hashFunc == nil {
+		hashFunc = sha256.New
+	}
+
+	hmhash := hmac.New(hashFunc, s.Key)
+	hmhash.Write(data)
+
+	return hmhash.Sum([]byte{}), nil
+}
+
+// Calculate the KVV with the in-memory key
+func (s *SoftwareSigner) ComputeKVV() ([]byte, error) {
+	return s.ComputeMAC([]byte(KvvCalcValue))
+}
+
+// Create a software signer from a hex encoded key
+// The signer holds its own copy of the decoded key, zero it with Clear when done
+func NewSoftwareSigner(hexKey []byte) (*SoftwareSigner, error) {
+	sealer := HmacSealer{}
+	if err := sealer.SetKeyBytes(hexKey); err != nil {
+		return nil, err
+	}
+
+	return &SoftwareSigner{Key: sealer.Key, Hash: sealer.Hash}, nil
+}
+
+// Overwrite the key held by the signer with zeroes
+func (s *SoftwareSigner) Clear() {
+	for i := range s.Key {
+		s.Key[i] = 0
+	}
+	s.Key = nil
+}
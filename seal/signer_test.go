@@ -0,0 +1,63 @@
+package seal_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/seal"
+)
+
+// A signer holding the key outside of the sealer, counting the calls made to it
+type countingSigner struct {
+	inner seal.SoftwareSigner
+	calls int
+}
+
+func (s *countingSigner) ComputeMAC(data []byte) ([]byte, error) {
+	s.calls++
+	return s.inner.ComputeMAC(data)
+}
+
+func (s *countingSigner) ComputeKVV() ([]byte, error) {
+	s.calls++
+	return s.inner.ComputeKVV()
+}
+
+func TestExternalSignerMatchesKey(t *testing.T) {
+	const key = "1234567890ABCDEF1234567890ABCDEF"
+	const content = "0120240416AUTOGIRO\r\n82202404220    000000000020790200000008000000099252560040106553200145"
+
+	withKey := seal.HmacSealer{}
+	withKey.SetKey(key)
+	withKey.SetSealDate("240429")
+	withKey.SetData(content)
+	if err := withKey.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+
+	rawKey, _ := hex.DecodeString(key)
+	signer := &countingSigner{inner: seal.SoftwareSigner{Key: rawKey}}
+
+	withSigner := seal.HmacSealer{}
+	if err := withSigner.SetSigner(signer); err != nil {
+		t.Fatal(err)
+	}
+	withSigner.SetSealDate("240429")
+	withSigner.SetData(content)
+	if err := withSigner.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if withSigner.Key != nil {
+		t.Error("sealer with external signer should not hold a key")
+	}
+	if signer.calls != 2 {
+		t.Errorf("signer called %d times, want 2", signer.calls)
+	}
+	if withSigner.GetKvv() != withKey.GetKvv() {
+		t.Errorf("KVV = %s, want %s", withSigner.GetKvv(), withKey.GetKvv())
+	}
+	if withSigner.GetMac() != withKey.GetMac() {
+		t.Errorf("MAC = %s, want %s", withSigner.GetMac(), withKey.GetMac())
+	}
+}
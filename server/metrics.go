@@ -0,0 +1,116 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Request and seal counters exposed in the Prometheus text format
+type Metrics struct {
+	mu        sync.Mutex
+	requests  map[requestKey]int
+	durations map[string]float64
+	counts    map[string]int
+	sealed    int
+	verified  map[bool]int
+	started   time.Time
+}
+
+type requestKey struct {
+	path string
+	code int
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:  map[requestKey]int{},
+		durations: map[string]float64{},
+		counts:    map[string]int{},
+		verified:  map[bool]int{},
+		started:   time.Now(),
+	}
+}
+
+// Record a handled request
+func (m *Metrics) ObserveRequest(path string, code int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{path, code}]++
+	m.durations[path] += duration.Seconds()
+	m.counts[path]++
+}
+
+// Record a sealed file
+func (m *Metrics) ObserveSeal() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sealed++
+}
+
+// Record a verified file
+func (m *Metrics) ObserveVerify(valid bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.verified[valid]++
+}
+
+// Write the metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var written int64
+	printf := func(format string, args ...interface{}) {
+		n, _ := fmt.Fprintf(w, format, args...)
+		written += int64(n)
+	}
+
+	printf("# HELP bankgiro_http_requests_total HTTP requests handled, by path and status code.\n")
+	printf("# TYPE bankgiro_http_requests_total counter\n")
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+		return keys[i].code < keys[j].code
+	})
+	for _, key := range keys {
+		printf("bankgiro_http_requests_total{path=%q,code=\"%d\"} %d\n", key.path, key.code, m.requests[key])
+	}
+
+	printf("# HELP bankgiro_http_request_duration_seconds Time spent handling HTTP requests, by path.\n")
+	printf("# TYPE bankgiro_http_request_duration_seconds summary\n")
+	paths := make([]string, 0, len(m.counts))
+	for path := range m.counts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		printf("bankgiro_http_request_duration_seconds_sum{path=%q} %g\n", path, m.durations[path])
+		printf("bankgiro_http_request_duration_seconds_count{path=%q} %d\n", path, m.counts[path])
+	}
+
+	printf("# HELP bankgiro_files_sealed_total Files sealed.\n")
+	printf("# TYPE bankgiro_files_sealed_total counter\n")
+	printf("bankgiro_files_sealed_total %d\n", m.sealed)
+
+	printf("# HELP bankgiro_files_verified_total Files verified, by result.\n")
+	printf("# TYPE bankgiro_files_verified_total counter\n")
+	printf("bankgiro_files_verified_total{valid=\"true\"} %d\n", m.verified[true])
+	printf("bankgiro_files_verified_total{valid=\"false\"} %d\n", m.verified[false])
+
+	printf("# HELP bankgiro_start_time_seconds Start time of the service since unix epoch.\n")
+	printf("# TYPE bankgiro_start_time_seconds gauge\n")
+	printf("bankgiro_start_time_seconds %d\n", m.started.Unix())
+
+	return written, nil
+}
@@ -0,0 +1,429 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/audit"
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/parse"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+)
+
+// The default maximum request body size, 10 MB
+const DefaultMaxBodySize = 10 << 20
+
+// Provides the signer for a file with the given TK01 customer and bankgiro number, sealed on the given date
+// The returned function releases the signer once the request is done
+type KeyProvider interface {
+	Signer(customerNumber string, bankgiro string, sealDate time.Time) (seal.Signer, func(), error)
+}
+
+// Addr: address to listen on, e.g. :8080
+// Tokens: accepted bearer tokens, at least one is required
+// MaxBodySize: maximum request body size in bytes
+// ShutdownTimeout: time given to running requests on shutdown
+// AuditLog: when set, each seal is recorded in the audit log
+// Profile: the normalization rules, nil for the default
+// TLSCert/TLSKey: serve HTTPS with the given certificate and key files
+type Config struct {
+	Addr            string
+	Tokens          []string
+	MaxBodySize     int64
+	ShutdownTimeout time.Duration
+	TLSCert         string
+	TLSKey          string
+	AuditLog        *audit.Log
+	Profile         *seal.NormalizationProfile
+}
+
+// The HTTP sealing and verification service
+type Server struct {
+	Config  Config
+	Keys    KeyProvider
+	Metrics *Metrics
+	Logger  *log.Logger
+}
+
+// An error returned to the client as JSON
+type Error struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// The response of GET /kvv
+type KvvResponse struct {
+	Kvv string `json:"kvv"`
+}
+
+func New(config Config, keyProvider KeyProvider) (*Server, error) {
+	if len(config.Tokens) == 0 {
+		return nil, fmt.Errorf("at least one bearer token is required")
+	}
+
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = 30 * time.Second
+	}
+
+	return &Server{
+		Config:  config,
+		Keys:    keyProvider,
+		Metrics: NewMetrics(),
+		Logger:  log.New(os.Stdout, "", log.LstdFlags),
+	}, nil
+}
+
+// Get the HTTP handler serving the API
+// /metrics and /healthz are served without authentication
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/seal", s.endpoint(http.MethodPost, s.handleSeal))
+	mux.Handle("/verify", s.endpoint(http.MethodPost, s.handleVerify))
+	mux.Handle("/parse", s.endpoint(http.MethodPost, s.handleParse))
+	mux.Handle("/kvv", s.endpoint(http.MethodGet, s.handleKvv))
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		s.Metrics.WriteTo(w)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+
+	return mux
+}
+
+// Listen and serve until the context is cancelled, then shut down gracefully
+func (s *Server) Run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.Config.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       60 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    16 << 10,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		s.Logger.Printf("listening on %s", s.Config.Addr)
+		if s.Config.TLSCert != "" {
+			errs <- srv.ListenAndServeTLS(s.Config.TLSCert, s.Config.TLSKey)
+		} else {
+			errs <- srv.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.Logger.Printf("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.Config.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Wrap a handler with method checks, authentication, body limits, error handling and metrics
+func (s *Server) endpoint(method string, handler func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		err := func() error {
+			if r.Method != method {
+				recorder.Header().Set("Allow", method)
+				return &Error{http.StatusMethodNotAllowed, "method_not_allowed", fmt.Sprintf("%s requires %s", r.URL.Path, method)}
+			}
+
+			if !s.authorized(r) {
+				recorder.Header().Set("WWW-Authenticate", `Bearer realm="go-bankgiro"`)
+				return &Error{http.StatusUnauthorized, "unauthorized", "a valid bearer token is required"}
+			}
+
+			r.Body = http.MaxBytesReader(recorder, r.Body, s.Config.MaxBodySize)
+
+			return handler(recorder, r)
+		}()
+
+		if err != nil {
+			writeError(recorder, err)
+			if recorder.status >= 500 {
+				s.Logger.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+			}
+		}
+
+		s.Metrics.ObserveRequest(r.URL.Path, recorder.status, time.Since(started))
+	})
+}
+
+// Check the bearer token of the request
+func (s *Server) authorized(r *http.Request) bool {
+	return Authorized(s.Config.Tokens, r.Header.Get("Authorization"))
+}
+
+// Check an Authorization header value in constant time against the accepted bearer tokens
+func Authorized(tokens []string, authorization string) bool {
+	token, found := strings.CutPrefix(authorization, "Bearer ")
+	if !found || token == "" {
+		return false
+	}
+
+	authorized := 0
+	for _, valid := range tokens {
+		authorized |= subtle.ConstantTimeCompare([]byte(token), []byte(valid))
+	}
+
+	return authorized == 1
+}
+
+// Read the request body, reporting oversized bodies
+func readBody(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, &Error{http.StatusRequestEntityTooLarge, "payload_too_large", fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit)}
+		}
+
+		return nil, &Error{http.StatusBadRequest, "invalid_body", err.Error()}
+	}
+
+	if len(body) == 0 {
+		return nil, &Error{http.StatusBadRequest, "empty_body", "request body is empty"}
+	}
+
+	return body, nil
+}
+
+// POST /seal: seal the file in the body, the sealed file is returned
+// The seal date can be set with ?date=YYMMDD, the KVV and MAC are returned in headers
+// Payment dates Bankgirot would move or reject are returned as X-Bankgiro-Warning headers
+func (s *Server) handleSeal(w http.ResponseWriter, r *http.Request) error {
+	date := r.URL.Query().Get("date")
+	if date != "" {
+		if _, err := time.Parse("060102", date); err != nil {
+			return &Error{http.StatusBadRequest, "invalid_date", "date must be formatted as YYMMDD"}
+		}
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	if err := sign.Validate(body); err != nil {
+		return &Error{http.StatusUnprocessableEntity, "invalid_file", err.Error()}
+	}
+
+	bgFile := sign.NewBankgiroFile(charset.Options{})
+	bgFile.Write(body)
+	if err := bgFile.Prepare(); err != nil {
+		return &Error{http.StatusUnprocessableEntity, "invalid_file", err.Error()}
+	}
+
+	if date != "" {
+		bgFile.SetSealDate(date)
+	}
+
+	sealDate, err := bgFile.SealDay()
+	if err != nil {
+		return err
+	}
+
+	customerNumber, bankgiro, _ := bgFile.OpeningNumbers()
+	signer, release, err := s.Keys.Signer(customerNumber, bankgiro, sealDate)
+	if err != nil {
+		return &Error{http.StatusUnprocessableEntity, "no_key", err.Error()}
+	}
+	defer release()
+
+	if err := bgFile.SetNormalizationProfile(s.Config.Profile); err != nil {
+		return err
+	}
+
+	if err := bgFile.SetSigner(signer); err != nil {
+		return err
+	}
+
+	bgFile.Source = "http:" + r.RemoteAddr
+	bgFile.AuditLog = s.Config.AuditLog
+
+	if err := bgFile.Sign(); err != nil {
+		return err
+	}
+
+	s.Metrics.ObserveSeal()
+
+	w.Header().Set("Content-Type", "text/plain; charset=iso-8859-1")
+	w.Header().Set("X-Bankgiro-Seal-Date", bgFile.Seal.SealDate)
+	w.Header().Set("X-Bankgiro-Kvv", bgFile.Seal.GetKvvBgFormat())
+	w.Header().Set("X-Bankgiro-Mac", bgFile.Seal.GetMacBgFormat())
+	for _, problem := range bgFile.DateProblems {
+		w.Header().Add("X-Bankgiro-Warning", problem.String())
+	}
+	_, err = bgFile.WriteTo(w)
+
+	return err
+}
+
+// POST /verify: verify the seal of the sealed file in the body
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := sign.ParseSealedFile(body)
+	if err != nil {
+		return &Error{http.StatusUnprocessableEntity, "invalid_file", err.Error()}
+	}
+
+	sealDate, err := time.Parse("060102", sealed.SealDate)
+	if err != nil {
+		return &Error{http.StatusUnprocessableEntity, "invalid_file", fmt.Sprintf("invalid seal date %q", sealed.SealDate)}
+	}
+
+	customerNumber, bankgiro, _ := parse.OpeningNumbers(sealed.Content)
+	signer, release, err := s.Keys.Signer(customerNumber, bankgiro, sealDate)
+	if err != nil {
+		return &Error{http.StatusUnprocessableEntity, "no_key", err.Error()}
+	}
+	defer release()
+
+	verification, err := sign.VerifySealedFileWithProfile(body, signer, s.Config.Profile)
+	if err != nil {
+		return &Error{http.StatusUnprocessableEntity, "invalid_file", err.Error()}
+	}
+
+	s.Metrics.ObserveVerify(verification.Valid)
+
+	return writeJSON(w, http.StatusOK, verification)
+}
+
+// POST /parse: parse the Autogiro file in the body
+func (s *Server) handleParse(w http.ResponseWriter, r *http.Request) error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	isoContent, err := tools.BytesToIsoString(body)
+	if err != nil {
+		return &Error{http.StatusUnprocessableEntity, "invalid_encoding", err.Error()}
+	}
+
+	agFile := parse.AutogiroFile{}
+	if err := agFile.ParseFile(isoContent); err != nil {
+		return &Error{http.StatusUnprocessableEntity, "invalid_file", err.Error()}
+	}
+
+	return writeJSON(w, http.StatusOK, utf8File(agFile))
+}
+
+// Convert the rows of a parsed file from ISO-8859-1 to UTF-8 for the JSON response
+func utf8File(agFile parse.AutogiroFile) parse.AutogiroFile {
+	agFile.HmacData = charset.IsoToUtf8(agFile.HmacData)
+	agFile.Content = charset.IsoToUtf8Strings(agFile.Content)
+	agFile.SealCalcContent = charset.IsoToUtf8Strings(agFile.SealCalcContent)
+
+	sections := make([]parse.AutogiroSection, len(agFile.Sections))
+	for i, sec := range agFile.Sections {
+		sec.SectionSeal = charset.IsoToUtf8(sec.SectionSeal)
+		sec.Rows = charset.IsoToUtf8Strings(sec.Rows)
+		sec.SealCalcContent = charset.IsoToUtf8Strings(sec.SealCalcContent)
+		sec.Errors = charset.IsoToUtf8Strings(sec.Errors)
+		sections[i] = sec
+	}
+	agFile.Sections = sections
+
+	return agFile
+}
+
+// GET /kvv: get the KVV of the key, ?customerNumber=, ?bankgiro= and ?date= select the key from a key store
+func (s *Server) handleKvv(w http.ResponseWriter, r *http.Request) error {
+	date := time.Now()
+	if value := r.URL.Query().Get("date"); value != "" {
+		var err error
+		if date, err = time.Parse("060102", value); err != nil {
+			return &Error{http.StatusBadRequest, "invalid_date", "date must be formatted as YYMMDD"}
+		}
+	}
+
+	signer, release, err := s.Keys.Signer(r.URL.Query().Get("customerNumber"), r.URL.Query().Get("bankgiro"), date)
+	if err != nil {
+		return &Error{http.StatusUnprocessableEntity, "no_key", err.Error()}
+	}
+	defer release()
+
+	sealer := seal.HmacSealer{}
+	if err := sealer.SetSigner(signer); err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, KvvResponse{Kvv: sealer.GetKvvBgFormat()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Write an error as JSON, errors that are not an *Error are reported as internal errors
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = &Error{http.StatusInternalServerError, "internal_error", "internal server error"}
+	}
+
+	writeJSON(w, apiErr.Status, struct {
+		Error *Error `json:"error"`
+	}{apiErr})
+}
+
+// Records the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
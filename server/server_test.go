@@ -0,0 +1,176 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"github.com/hoglandets-it/go-bankgiro/server"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+)
+
+const (
+	testKey   = "1234567890ABCDEF1234567890ABCDEF"
+	testKvv   = "FF365893D899291C3BF505FB3175E880"
+	testDate  = "240429"
+	testToken = "secret-token"
+)
+
+// A key only valid on the test date, so the seal date must be used to select it
+type staticKey struct{}
+
+func (staticKey) Signer(customerNumber string, bankgiro string, sealDate time.Time) (seal.Signer, func(), error) {
+	if sealDate.Format("060102") != testDate {
+		return nil, nil, fmt.Errorf("no valid key found on %s", sealDate.Format("20060102"))
+	}
+
+	signer, err := seal.NewSoftwareSigner([]byte(testKey))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return signer, signer.Clear, nil
+}
+
+func newTestServer(t *testing.T, maxBodySize int64) *httptest.Server {
+	srv, err := server.New(server.Config{Tokens: []string{"other-token", testToken}, MaxBodySize: maxBodySize}, staticKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Logger.SetOutput(io.Discard)
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func request(t *testing.T, method string, url string, token string, body []byte) (*http.Response, []byte) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp, respBody
+}
+
+func TestSealAndVerify(t *testing.T) {
+	ts := newTestServer(t, 0)
+
+	for _, file := range []string{"basic", "blank-rows"} {
+		unsigned, err := os.ReadFile("../tests/sealFile/" + file + ".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, sealed := request(t, http.MethodPost, ts.URL+"/seal?date="+testDate, testToken, unsigned)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: seal returned %d: %s", file, resp.StatusCode, sealed)
+		}
+		if resp.Header.Get("X-Bankgiro-Kvv") != testKvv || resp.Header.Get("X-Bankgiro-Seal-Date") != testDate {
+			t.Errorf("%s: unexpected seal headers: %v", file, resp.Header)
+		}
+		if warnings := resp.Header.Values("X-Bankgiro-Warning"); len(warnings) == 0 || !strings.Contains(warnings[0], "has passed") {
+			t.Errorf("%s: expected the payment dates of 2024 to be flagged, got %q", file, warnings)
+		}
+		if !sign.IsSealed(sealed) {
+			t.Errorf("%s: returned file is not sealed", file)
+		}
+
+		resp, body := request(t, http.MethodPost, ts.URL+"/verify", testToken, sealed)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: verify returned %d: %s", file, resp.StatusCode, body)
+		}
+
+		verification := sign.Verification{}
+		if err := json.Unmarshal(body, &verification); err != nil {
+			t.Fatal(err)
+		}
+		if !verification.Valid || !strings.HasPrefix(verification.Mac, resp.Header.Get("X-Bankgiro-Mac")) {
+			t.Errorf("%s: sealed file did not verify: %+v", file, verification)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ts := newTestServer(t, 64)
+	unsigned, _ := os.ReadFile("../tests/sealFile/basic.txt")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   []byte
+		status int
+		code   string
+	}{
+		{"missing token", http.MethodPost, "/seal", "", unsigned, http.StatusUnauthorized, "unauthorized"},
+		{"wrong token", http.MethodPost, "/seal", "secret", unsigned, http.StatusUnauthorized, "unauthorized"},
+		{"wrong method", http.MethodGet, "/seal", testToken, nil, http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"too large", http.MethodPost, "/seal", testToken, unsigned, http.StatusRequestEntityTooLarge, "payload_too_large"},
+		{"empty body", http.MethodPost, "/verify", testToken, nil, http.StatusBadRequest, "empty_body"},
+		{"invalid date", http.MethodPost, "/seal?date=2404", testToken, []byte("01\r\n"), http.StatusBadRequest, "invalid_date"},
+		{"not sealed", http.MethodPost, "/verify", testToken, []byte("0120240429\r\n"), http.StatusUnprocessableEntity, "invalid_file"},
+	}
+
+	for _, test := range tests {
+		resp, body := request(t, test.method, ts.URL+test.path, test.token, test.body)
+		if resp.StatusCode != test.status {
+			t.Errorf("%s: expected status %d, got %d: %s", test.name, test.status, resp.StatusCode, body)
+			continue
+		}
+
+		apiErr := struct {
+			Error server.Error `json:"error"`
+		}{}
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			t.Errorf("%s: invalid error body %q: %v", test.name, body, err)
+			continue
+		}
+		if apiErr.Error.Code != test.code {
+			t.Errorf("%s: expected error code %s, got %s", test.name, test.code, apiErr.Error.Code)
+		}
+	}
+}
+
+func TestKvvAndMetrics(t *testing.T) {
+	ts := newTestServer(t, 0)
+
+	resp, body := request(t, http.MethodGet, ts.URL+"/kvv?date="+testDate, testToken, nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), testKvv) {
+		t.Errorf("unexpected kvv response %d: %s", resp.StatusCode, body)
+	}
+
+	resp, body = request(t, http.MethodGet, ts.URL+"/healthz", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("healthz returned %d", resp.StatusCode)
+	}
+
+	resp, body = request(t, http.MethodGet, ts.URL+"/metrics", "", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `bankgiro_http_requests_total{path="/kvv",code="200"} 1`) {
+		t.Errorf("unexpected metrics response %d: %s", resp.StatusCode, body)
+	}
+}
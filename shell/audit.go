@@ -0,0 +1,42 @@
+package shell
+
+import (
+	"fmt"
+
+	"github.com/hoglandets-it/go-bankgiro/audit"
+	"github.com/urfave/cli/v2"
+)
+
+// Verify the hash chain of an audit log, exits with code 2 if the log has been tampered with
+func AuditVerify(c *cli.Context) error {
+	path := c.Args().First()
+	if path == "" {
+		path = c.String("audit-log")
+	}
+	if path == "" {
+		return cli.Exit("audit log is required", 1)
+	}
+
+	verification, err := audit.Verify(path)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	for _, problem := range verification.Problems {
+		fmt.Println(problem)
+	}
+
+	fmt.Printf("%d records, head %s\r\n", verification.Records, verification.Head)
+
+	if head := c.String("head"); head != "" && head != verification.Head {
+		return cli.Exit(fmt.Sprintf("head %s does not match the expected %s, records have been removed or added", verification.Head, head), 2)
+	}
+
+	if !verification.Valid() {
+		return cli.Exit(fmt.Sprintf("audit log has been tampered with: %d problems found", len(verification.Problems)), 2)
+	}
+
+	fmt.Println("Audit log is intact")
+
+	return nil
+}
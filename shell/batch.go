@@ -0,0 +1,86 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hoglandets-it/go-bankgiro/batch"
+	"github.com/urfave/cli/v2"
+)
+
+// Seal all matching files in a directory
+func SealBatch(c *cli.Context) error {
+	inputDir := c.Args().First()
+	if inputDir == "" {
+		return cli.Exit("input directory is required", 1)
+	}
+
+	if info, err := os.Stat(inputDir); err != nil || !info.IsDir() {
+		return cli.Exit(fmt.Sprintf("%s is not a directory", inputDir), 1)
+	}
+
+	outputDir := c.String("output-dir")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	opts, err := CharsetOptions(c)
+	if err != nil {
+		return err
+	}
+
+	sealKey, err := OpenSealKey(c)
+	if err != nil {
+		return err
+	}
+	defer sealKey.Close()
+
+	manifest, err := batch.Run(batch.Options{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Patterns:  c.StringSlice("pattern"),
+		Recursive: c.Bool("recursive"),
+		Workers:   c.Int("workers"),
+		Overwrite: c.Bool("overwrite"),
+		Charset:   opts,
+	}, sealKey.Seal)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range manifest.Files {
+		switch entry.Status {
+		case batch.StatusSealed:
+			fmt.Printf("sealed   %s -> %s\r\n", entry.Input, entry.Output)
+			if len(entry.Substitutions) > 0 {
+				fmt.Printf("         %d characters transliterated, see the manifest\r\n", len(entry.Substitutions))
+			}
+			for _, problem := range entry.DateProblems {
+				fmt.Printf("         warning: %s\r\n", problem)
+			}
+		case batch.StatusSkipped:
+			fmt.Printf("skipped  %s: %s\r\n", entry.Input, entry.Reason)
+		case batch.StatusFailed:
+			fmt.Printf("failed   %s: %s\r\n", entry.Input, entry.Reason)
+		}
+	}
+
+	manifestPath := c.String("manifest")
+	if manifestPath == "" {
+		manifestPath = filepath.Join(outputDir, batch.ManifestName)
+	}
+
+	if err := manifest.Write(manifestPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("%d sealed, %d skipped, %d failed\r\n", manifest.Sealed, manifest.Skipped, manifest.Failed)
+	fmt.Println("Manifest saved to", manifestPath)
+
+	if code := manifest.ExitCode(); code != 0 {
+		return cli.Exit(fmt.Sprintf("%d files could not be sealed", manifest.Failed), code)
+	}
+
+	return nil
+}
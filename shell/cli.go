@@ -1,19 +1,22 @@
 package shell
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
 
+	"github.com/hoglandets-it/go-bankgiro/calendar"
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/diff"
 	"github.com/hoglandets-it/go-bankgiro/sign"
 	"github.com/urfave/cli/v2"
 )
 
 func ParseVars(c *cli.Context) error {
-	key := c.String("key")
 	kvv := c.String("kvv")
 
-	if key == "" {
-		return cli.Exit("key is required", 1)
+	if err := CheckKeySource(c); err != nil {
+		return err
 	}
 
 	if kvv == "" {
@@ -38,7 +41,7 @@ func ParseVars(c *cli.Context) error {
 		output = fmt.Sprintf("%s-signed", file)
 	}
 
-	if _, err := os.Stat(output); err == nil {
+	if _, err := os.Stat(output); err == nil && !c.Bool("dry-run") {
 		overwrite := c.Bool("overwrite")
 		if !overwrite {
 			return cli.Exit(fmt.Sprintf("%s already exists, use -f to overwrite", output), 1)
@@ -51,44 +54,181 @@ func ParseVars(c *cli.Context) error {
 }
 
 func SealFile(c *cli.Context) error {
-	file, err := os.ReadFile(c.Args().First())
+	opts, err := CharsetOptions(c)
 	if err != nil {
 		return err
 	}
 
-	bgFile, err := sign.CreateBankgiroFileBytes(file)
+	file, err := os.Open(c.Args().First())
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 
-	key := c.String("key")
-	err = bgFile.SetSealKey(key)
-	if err != nil {
+	bgFile := sign.NewBankgiroFile(opts)
+	if _, err := bgFile.ReadFrom(file); err != nil {
 		return err
 	}
-
-	kvv := c.String("kvv")
-	if kvv != "" {
-		err = bgFile.CheckKvv(kvv)
-		if err != nil {
-			return err
-		}
+	if err := bgFile.Prepare(); err != nil {
+		return cli.Exit(err.Error(), 1)
 	}
+	bgFile.Source = c.Args().First()
+	fmt.Println("Encoding:", bgFile.Encoding)
+	printSubstitutions(bgFile.Substitutions)
+	printDateProblems(bgFile.DateProblems)
 
-	err = bgFile.Sign()
+	sealKey, err := OpenSealKey(c)
 	if err != nil {
 		return err
 	}
+	defer sealKey.Close()
 
-	fmt.Println("File signed successfully")
+	err = sealKey.Seal(bgFile)
+	if err != nil {
+		return err
+	}
 
-	content := bgFile.GetSignedData()
 	output := c.String("output")
 	if output == "" {
 		output = fmt.Sprintf("%s-signed", c.Args().First())
 	}
 
+	if c.Bool("dry-run") {
+		printDryRun(c.Args().First(), output, bgFile)
+		return nil
+	}
+
+	fmt.Println("File signed successfully")
+
 	fmt.Println("File saved to", output)
 
-	return os.WriteFile(output, []byte(content), 0644)
+	out, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := bgFile.WriteTo(out); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// Print how sealing changes the file, and the normalized data the HMAC is calculated over
+func printDryRun(input string, output string, bgFile *sign.BankgiroFile) {
+	fmt.Println("Dry run, nothing is written")
+	fmt.Println()
+
+	// Line endings are compared separately, converting them would otherwise change every line
+	inputLines, inputEnding := diff.LinesAndEnding(bgFile.Input())
+	outputLines, outputEnding := diff.LinesAndEnding(bgFile.Bytes())
+	if inputEnding != outputEnding {
+		fmt.Printf("Line endings changed from %s to %s\r\n", endingName(inputEnding), endingName(outputEnding))
+	}
+
+	changes := diff.Unified(input, output, inputLines, outputLines, 3)
+	if changes == "" && inputEnding == outputEnding {
+		fmt.Println("The sealed file is identical to the input")
+	}
+	fmt.Print(changes)
+
+	fmt.Println()
+	fmt.Printf("HMAC input, %d bytes after normalization:\r\n", len(bgFile.Seal.NormalizedData))
+	fmt.Print(hex.Dump(bgFile.Seal.NormalizedData))
+
+	fmt.Println()
+	fmt.Printf("Seal date: %s\r\nKVV:       %s\r\nMAC:       %s\r\n", bgFile.Seal.SealDate, bgFile.Seal.GetKvvBgFormat(), bgFile.Seal.GetMacBgFormat())
+}
+
+// Describe a line ending returned by diff.LinesAndEnding
+func endingName(ending string) string {
+	if ending == "" {
+		return "none"
+	}
+
+	return ending
+}
+
+// Select the encoding input files are read as
+func EncodingFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "encoding",
+		Usage:   "encoding of the input file, one of utf-8, utf-8-bom, iso-8859-1, windows-1252, ibm437, ibm850, utf-16le, utf-16be, default is to detect it",
+		EnvVars: []string{"BG_ENCODING"},
+	}
+}
+
+// Get the encoding selected with --encoding, empty to detect it
+func Encoding(c *cli.Context) (charset.Encoding, error) {
+	name := c.String("encoding")
+	if name == "" {
+		return "", nil
+	}
+
+	encoding, err := charset.ParseEncoding(name)
+	if err != nil {
+		return "", cli.Exit(err.Error(), 1)
+	}
+
+	return encoding, nil
+}
+
+// Transliterate characters that cannot be represented in ISO-8859-1 instead of failing
+func TransliterateFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:    "transliterate",
+		Usage:   "replace characters that cannot be represented in ISO-8859-1, e.g. ł with l, instead of failing",
+		EnvVars: []string{"BG_TRANSLITERATE"},
+	}
+}
+
+// Add replacements to the default transliteration table
+func TransliterationTableFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "transliteration-table",
+		Usage:   "JSON file with replacements added to the default transliteration table, e.g. {\"€\": \"E\"}, implies --transliterate",
+		EnvVars: []string{"BG_TRANSLITERATION_TABLE"},
+	}
+}
+
+// Get how input files are converted to ISO-8859-1 from --encoding and the transliteration flags
+func CharsetOptions(c *cli.Context) (charset.Options, error) {
+	encoding, err := Encoding(c)
+	if err != nil {
+		return charset.Options{}, err
+	}
+
+	opts := charset.Options{Encoding: encoding}
+
+	if table := c.String("transliteration-table"); table != "" {
+		transliterator, err := charset.LoadTransliterator(table)
+		if err != nil {
+			return charset.Options{}, cli.Exit(err.Error(), 1)
+		}
+		opts.Transliterator = transliterator
+	} else if c.Bool("transliterate") {
+		opts.Transliterator = charset.DefaultTransliterator
+	}
+
+	return opts, nil
+}
+
+// Print a warning for every payment date Bankgirot would move or reject
+func printDateProblems(problems []calendar.DateProblem) {
+	for _, problem := range problems {
+		fmt.Printf("Warning: %s\r\n", problem)
+	}
+}
+
+// Print every substitution made by transliteration
+func printSubstitutions(substitutions []charset.Substitution) {
+	if len(substitutions) == 0 {
+		return
+	}
+
+	fmt.Printf("Transliterated %d characters:\r\n", len(substitutions))
+	for _, substitution := range substitutions {
+		fmt.Printf("  %s\r\n", substitution)
+	}
 }
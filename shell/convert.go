@@ -0,0 +1,77 @@
+package shell
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hoglandets-it/go-bankgiro/parse"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+	"github.com/urfave/cli/v2"
+)
+
+// Convert the old format sections of an Autogiro file to the new format
+func Convert(c *cli.Context) error {
+	input := c.Args().First()
+	if input == "" {
+		return cli.Exit("file is required", 1)
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	agFile, err := readAutogiroFile(content)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	converted := 0
+	for _, sec := range agFile.Sections {
+		if sec.OldFormat() {
+			converted++
+		}
+	}
+	if converted == 0 {
+		return cli.Exit("the file has no old format sections", 1)
+	}
+
+	newFile, err := agFile.ToNewFormat()
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	if agFile.HMACStartFound {
+		fmt.Println("The seal is not kept in the converted file")
+	}
+
+	output, err := createOutput(c, input, "new")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(output, newFile.Text()); err != nil {
+		output.Close()
+		return err
+	}
+
+	fmt.Printf("Converted %d of %d sections, file saved to %s\r\n", converted, len(agFile.Sections), output.Name())
+
+	return output.Close()
+}
+
+// Parse the Autogiro file
+func readAutogiroFile(content []byte) (*parse.AutogiroFile, error) {
+	isoContent, err := tools.BytesToIsoString(content)
+	if err != nil {
+		return nil, err
+	}
+
+	agFile := &parse.AutogiroFile{}
+	if err := agFile.ParseFile(isoContent); err != nil {
+		return nil, err
+	}
+
+	return agFile, nil
+}
@@ -0,0 +1,124 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hoglandets-it/go-bankgiro/emandate"
+	"github.com/urfave/cli/v2"
+)
+
+// An e-mandate with the problems found validating it
+type checkedMandate struct {
+	emandate.Mandate
+	Problems []string `json:"problems"`
+}
+
+// Print the e-mandates of an AG-EMEDGIV file with the problems found validating them
+// With --output, TK04 records approving the valid mandates and rejecting the others are written for the next submission file
+func Emandate(c *cli.Context) error {
+	input := c.Args().First()
+	if input == "" {
+		return cli.Exit("file is required", 1)
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	agFile, err := readAutogiroFile(content)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	mandates, err := emandate.ReadFile(agFile)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	checked := []checkedMandate{}
+	for _, m := range mandates {
+		problems := []string{}
+		if err := m.Validate(); err != nil {
+			problems = strings.Split(err.Error(), "\n")
+		}
+		checked = append(checked, checkedMandate{Mandate: m, Problems: problems})
+	}
+
+	if c.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(checked); err != nil {
+			return err
+		}
+	} else {
+		printMandates(checked)
+	}
+
+	if c.String("output") == "" {
+		return nil
+	}
+
+	output, err := createOutput(c, input, "tk04")
+	if err != nil {
+		return err
+	}
+
+	var records strings.Builder
+	approved := 0
+	for _, m := range checked {
+		if len(m.Problems) == 0 {
+			records.WriteString(m.ApprovalRecord())
+			approved++
+		} else {
+			records.WriteString(m.RejectionRecord())
+		}
+		records.WriteString("\r\n")
+	}
+
+	if _, err := output.WriteString(records.String()); err != nil {
+		output.Close()
+		return err
+	}
+
+	if !c.Bool("json") {
+		fmt.Printf("\r\nApproved %d and rejected %d mandates, TK04 records saved to %s\r\n", approved, len(checked)-approved, output.Name())
+	}
+
+	return output.Close()
+}
+
+func printMandates(mandates []checkedMandate) {
+	for i, m := range mandates {
+		if i > 0 {
+			fmt.Print("\r\n")
+		}
+
+		status := "valid"
+		if len(m.Problems) > 0 {
+			status = "invalid"
+		}
+		fmt.Printf("row %-4d %-8s payer %s  account %s %s  id %s\r\n", m.Row, status, m.PayerNumber, m.Clearing, m.Account, m.IdentityNumber)
+		fmt.Printf("         %s\r\n", strings.Join(nonEmpty(m.Name, m.ExtraName, m.Address, m.ExtraAddress, strings.TrimSpace(m.PostalCode+" "+m.City)), ", "))
+		if m.Information != "" {
+			fmt.Printf("         %q\r\n", m.Information)
+		}
+		for _, problem := range m.Problems {
+			fmt.Printf("         - %s\r\n", problem)
+		}
+	}
+}
+
+func nonEmpty(values ...string) []string {
+	result := []string{}
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
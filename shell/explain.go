@@ -0,0 +1,62 @@
+package shell
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+	"github.com/urfave/cli/v2"
+)
+
+// Print an annotated view of how the file is normalized into the HMAC input
+func Explain(c *cli.Context) error {
+	input := c.Args().First()
+	if input == "" {
+		return cli.Exit("file is required", 1)
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	profile, err := NormalizationProfile(c)
+	if err != nil {
+		return err
+	}
+
+	opts, err := CharsetOptions(c)
+	if err != nil {
+		return err
+	}
+
+	explanation, err := sign.ExplainFile(content, opts, c.String("date"), profile)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	if sign.IsSealed(content) {
+		fmt.Println("Sealed file, explaining the data before the trailer row")
+	} else {
+		fmt.Println("Unsealed file, explaining the data as it would be sealed, with the HMAC header added")
+	}
+	// ExplainFile succeeded, so the conversion does too
+	conversion, _ := charset.Convert(content, opts)
+	fmt.Printf("Read as %s\r\n", conversion.Detection)
+	printSubstitutions(conversion.Substitutions)
+	fmt.Println("Lines are shown as read by the sealer, after conversion to ISO-8859-1")
+	fmt.Println()
+
+	if err := explanation.Write(os.Stdout, c.Bool("all")); err != nil {
+		return err
+	}
+
+	if c.Bool("hex") {
+		fmt.Println()
+		fmt.Print(hex.Dump(explanation.Normalized))
+	}
+
+	return nil
+}
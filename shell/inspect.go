@@ -0,0 +1,175 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hoglandets-it/go-bankgiro/parse"
+	"github.com/urfave/cli/v2"
+)
+
+// A section of an inspected file with its typed records
+// ConvertedFrom: the section type of an old format section, converted to the new format to read its records
+// Records is nil for section types without typed records
+type inspectedSection struct {
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	ConvertedFrom string `json:"convertedFrom,omitempty"`
+	Bankgiro      string `json:"bankgiro"`
+	Rows          int    `json:"rows"`
+	Records       any    `json:"records,omitempty"`
+}
+
+// Print the sections of an Autogiro file with the status and comment codes of the records decoded
+func Inspect(c *cli.Context) error {
+	if c.Bool("codes") {
+		return printCodeTables(c.Bool("json"))
+	}
+
+	input := c.Args().First()
+	if input == "" {
+		return cli.Exit("file is required", 1)
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	agFile, err := readAutogiroFile(content)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	sections := []inspectedSection{}
+	for i := range agFile.Sections {
+		sec, err := inspectSection(&agFile.Sections[i])
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("section %d: %s", i+1, err), 1)
+		}
+		sections = append(sections, sec)
+	}
+
+	if c.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sections)
+	}
+
+	for i, sec := range sections {
+		if i > 0 {
+			fmt.Print("\r\n")
+		}
+		fmt.Printf("Section %d: %s, bankgiro %s, %d rows\r\n", i+1, sec.Name, sec.Bankgiro, sec.Rows)
+		if sec.ConvertedFrom != "" {
+			fmt.Printf("  converted from %s\r\n", sec.ConvertedFrom)
+		}
+		printRecords(sec.Records)
+	}
+
+	return nil
+}
+
+// Get the typed records of the section, when its type has them
+func inspectSection(sec *parse.AutogiroSection) (inspectedSection, error) {
+	convertedFrom := ""
+	if sec.OldFormat() {
+		converted, err := sec.ToNewFormat()
+		if err != nil {
+			return inspectedSection{}, err
+		}
+		convertedFrom, sec = sec.SectionType.Name, &converted
+	}
+
+	inspected := inspectedSection{Type: sec.SectionType.Code, Name: sec.SectionType.Name, ConvertedFrom: convertedFrom, Rows: len(sec.Rows)}
+
+	if account := sec.SectionType.AccountNumber; len(sec.Rows) > 0 && account[1] > account[0] && len(sec.Rows[0]) >= account[1] {
+		inspected.Bankgiro = strings.TrimSpace(sec.Rows[0][account[0]:account[1]])
+	}
+
+	var err error
+	switch {
+	case strings.HasPrefix(sec.SectionType.Code, "betalningsspec"):
+		inspected.Records, err = sec.PaymentSpecification()
+	case strings.HasPrefix(sec.SectionType.Code, "avvisade"):
+		inspected.Records, err = sec.Rejections()
+	case strings.HasPrefix(sec.SectionType.Code, "andringslista"):
+		inspected.Records, err = sec.Changes()
+	case strings.HasPrefix(sec.SectionType.Code, "medgivandeavi"):
+		inspected.Records, err = sec.MandateNotices()
+	}
+
+	return inspected, err
+}
+
+// Print one line per record, followed by its decoded codes
+func printRecords(records any) {
+	switch records := records.(type) {
+	case *parse.PaymentSpecification:
+		for _, d := range records.Deposits {
+			fmt.Printf("  row %-4d TK%s  date %s  %d records  %s\r\n", d.Row, d.Code, d.PaymentDate, d.Count, d.Amount)
+		}
+		for _, p := range records.Payments {
+			fmt.Printf("  row %-4d TK%s  date %s  payer %s  %s  ref %q\r\n", p.Row, p.Code, p.PaymentDate, p.PayerNumber, p.Amount, p.Reference)
+			printCode("result", p.Result)
+		}
+		for _, r := range records.Refunds {
+			fmt.Printf("  row %-4d TK%s  refunded %s  payer %s  %s  ref %q\r\n", r.Row, parse.TK_REFUND, r.RefundDate, r.PayerNumber, r.Amount, r.Reference)
+			printCode("reason", r.Reason)
+		}
+	case []parse.RejectedRecord:
+		for _, r := range records {
+			fmt.Printf("  row %-4d TK%s  date %s  payer %s  %s  ref %q\r\n", r.Row, r.Code, r.PaymentDate, r.PayerNumber, r.Amount, r.Reference)
+			printCode("comment", r.Comment)
+		}
+	case []parse.ChangeRecord:
+		for _, r := range records {
+			fmt.Printf("  row %-4d TK%s  date %s  payer %s  %s  ref %q", r.Row, r.Code, r.PaymentDate, r.PayerNumber, r.Amount, r.Reference)
+			if r.NewPaymentDate != "" {
+				fmt.Printf("  new date %s", r.NewPaymentDate)
+			}
+			fmt.Print("\r\n")
+			printCode("comment", r.Comment)
+		}
+	case []parse.MandateRecord:
+		for _, r := range records {
+			fmt.Printf("  row %-4d TK73  date %s  payer %s  account %s %s  id %s\r\n", r.Row, r.Date, r.PayerNumber, r.Clearing, r.Account, r.IdentityNumber)
+			printCode("information", r.Information)
+			printCode("comment", r.Comment)
+		}
+	}
+}
+
+func printCode(field string, code parse.Code) {
+	if code.Code != "" {
+		fmt.Printf("           %-12s %s\r\n", field, code)
+	}
+}
+
+// Print the code tables used to decode the records
+func printCodeTables(asJson bool) error {
+	if asJson {
+		tables := map[string][]parse.Code{}
+		for _, table := range parse.CodeTables {
+			tables[table.Name] = table.List()
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(tables)
+	}
+
+	for i, table := range parse.CodeTables {
+		if i > 0 {
+			fmt.Print("\r\n")
+		}
+		fmt.Printf("%s\r\n", table.Name)
+		for _, code := range table.List() {
+			fmt.Printf("  %s\r\n", code)
+		}
+	}
+
+	return nil
+}
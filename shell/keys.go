@@ -0,0 +1,434 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/hoglandets-it/go-bankgiro/audit"
+	"github.com/hoglandets-it/go-bankgiro/hsm"
+	"github.com/hoglandets-it/go-bankgiro/keys"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+	"github.com/urfave/cli/v2"
+)
+
+// Flags selecting where the seal key is read from, the audit log and normalization profile, shared by all commands that seal
+func KeyFlags() []cli.Flag {
+	return []cli.Flag{
+		NormalizationProfileFlag(),
+		&cli.StringFlag{
+			Name:    "audit-log",
+			Usage:   "record every seal in a tamper-evident audit log",
+			EnvVars: []string{"BG_AUDIT_LOG"},
+		},
+		&cli.StringFlag{
+			Name:    "key",
+			Aliases: []string{"k"},
+			Usage:   "key to seal the file with (visible in process listings, prefer the other key sources)",
+			EnvVars: []string{"BG_SEAL_KEY"},
+		},
+		&cli.StringFlag{
+			Name:    "key-file",
+			Usage:   "read the key from a file only readable by its owner",
+			EnvVars: []string{"BG_SEAL_KEY_FILE"},
+		},
+		&cli.BoolFlag{
+			Name:  "key-stdin",
+			Usage: "read the key from stdin",
+		},
+		&cli.StringFlag{
+			Name:    "key-command",
+			Usage:   "read the key from the output of a command",
+			EnvVars: []string{"BG_SEAL_KEY_COMMAND"},
+		},
+		&cli.StringFlag{
+			Name:    "key-name",
+			Usage:   "read the key with the given name from the key store",
+			EnvVars: []string{"BG_SEAL_KEY_NAME"},
+		},
+		&cli.StringFlag{
+			Name:    "key-store",
+			Usage:   "path to the encrypted key store",
+			EnvVars: []string{"BG_KEY_STORE"},
+		},
+		&cli.StringFlag{
+			Name:    "key-store-passphrase-file",
+			Usage:   "read the key store passphrase from a file, default is the BG_KEY_STORE_PASSPHRASE variable",
+			EnvVars: []string{"BG_KEY_STORE_PASSPHRASE_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "key-store-identity",
+			Usage:   "decrypt the key store with an age X25519 identity file instead of a passphrase",
+			EnvVars: []string{"BG_KEY_STORE_IDENTITY"},
+		},
+		&cli.StringFlag{
+			Name:    "pkcs11-key",
+			Usage:   "seal inside a PKCS#11 token using the key with the given label, the PIN is read from BG_PKCS11_PIN",
+			EnvVars: []string{"BG_PKCS11_KEY"},
+		},
+		&cli.StringFlag{
+			Name:    "pkcs11-module",
+			Usage:   "path to the PKCS#11 module",
+			EnvVars: []string{"BG_PKCS11_MODULE"},
+		},
+		&cli.StringFlag{
+			Name:    "pkcs11-token",
+			Usage:   "label of the PKCS#11 token holding the key",
+			EnvVars: []string{"BG_PKCS11_TOKEN"},
+		},
+	}
+}
+
+// Get the names of the key sources set on the command line
+func keySources(c *cli.Context) []string {
+	sources := []string{}
+	for _, name := range []string{"key", "key-file", "key-stdin", "key-command", "key-name", "pkcs11-key"} {
+		if c.IsSet(name) {
+			sources = append(sources, name)
+		}
+	}
+
+	return sources
+}
+
+// Ensure exactly one key source is provided
+func CheckKeySource(c *cli.Context) error {
+	sources := keySources(c)
+	if len(sources) == 0 && c.String("key-store") != "" {
+		return nil
+	}
+
+	if len(sources) == 0 {
+		return cli.Exit("key is required, use one of --key, --key-file, --key-stdin, --key-command, --key-name, --key-store or --pkcs11-key", 1)
+	}
+
+	if len(sources) > 1 {
+		return cli.Exit(fmt.Sprintf("only one key source can be used, got --%s", strings.Join(sources, ", --")), 1)
+	}
+
+	if c.IsSet("key-name") && c.String("key-store") == "" {
+		return cli.Exit("--key-store is required when using --key-name", 1)
+	}
+
+	if c.IsSet("pkcs11-key") && (c.String("pkcs11-module") == "" || c.String("pkcs11-token") == "") {
+		return cli.Exit("--pkcs11-module and --pkcs11-token are required when using --pkcs11-key", 1)
+	}
+
+	return nil
+}
+
+// A seal key resolved once from the selected key source and applied to one or more files
+// With --key-store and no other key source, the key is selected per file from its TK01 opening record
+type SealKey struct {
+	kvv      string
+	signer   seal.Signer
+	store    *keys.Store
+	auditLog *audit.Log
+	profile  *seal.NormalizationProfile
+	close    func()
+}
+
+// Resolve the seal key from the selected key source
+// If --kvv is given, the key is checked against it and the likely causes of a mismatch are reported
+// Close must be called once sealing is done to release the key
+func OpenSealKey(c *cli.Context) (*SealKey, error) {
+	if err := CheckKeySource(c); err != nil {
+		return nil, err
+	}
+
+	profile, err := NormalizationProfile(c)
+	if err != nil {
+		return nil, err
+	}
+
+	sk := &SealKey{kvv: c.String("kvv"), profile: profile}
+	if path := c.String("audit-log"); path != "" && !c.Bool("dry-run") {
+		sk.auditLog = audit.Open(path)
+	}
+
+	if c.IsSet("pkcs11-key") {
+		signer, err := hsm.OpenPkcs11(hsm.Pkcs11Config{
+			Module:     c.String("pkcs11-module"),
+			TokenLabel: c.String("pkcs11-token"),
+			Pin:        os.Getenv("BG_PKCS11_PIN"),
+			KeyLabel:   c.String("pkcs11-key"),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sk.signer = signer
+		sk.close = func() { signer.Close() }
+
+		if err := sk.checkKvv(signer, nil); err != nil {
+			sk.Close()
+			return nil, err
+		}
+
+		return sk, nil
+	}
+
+	if len(keySources(c)) == 0 {
+		store, _, err := OpenKeyStore(c)
+		if err != nil {
+			return nil, err
+		}
+
+		sk.store = store
+		sk.close = store.Close
+
+		return sk, nil
+	}
+
+	key, err := LoadSealKey(c)
+	if err != nil {
+		return nil, err
+	}
+	defer keys.Zero(key)
+
+	signer, err := sk.softwareSigner(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sk.signer = signer
+	sk.close = signer.Clear
+
+	return sk, nil
+}
+
+// Get the signer for a file with the given TK01 customer and bankgiro number, sealed on the given date
+// The numbers and date are only used to select a key from the key store
+// The returned function releases the signer and must be called once sealing is done
+func (sk *SealKey) Signer(customerNumber string, bankgiro string, sealDate time.Time) (seal.Signer, func(), error) {
+	if sk.store == nil {
+		return sk.signer, func() {}, nil
+	}
+
+	entry, err := sk.store.Select(customerNumber, bankgiro, sealDate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fmt.Printf("Using key %s (KVV %s) for customer number %s, bankgiro %s\r\n", entry.Name, entry.Kvv, customerNumber, bankgiro)
+
+	signer, err := sk.softwareSigner(entry.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return signer, signer.Clear, nil
+}
+
+// Get the audit log seals are recorded in, nil without --audit-log
+func (sk *SealKey) AuditLog() *audit.Log {
+	return sk.auditLog
+}
+
+// Get the selected normalization profile, nil for the default
+func (sk *SealKey) Profile() *seal.NormalizationProfile {
+	return sk.profile
+}
+
+// Seal the file with the key, recording the seal in the audit log if set
+func (sk *SealKey) Seal(bgFile *sign.BankgiroFile) error {
+	customerNumber, bankgiro, sealDate := "", "", time.Time{}
+	if sk.store != nil {
+		var err error
+		customerNumber, bankgiro, err = bgFile.OpeningNumbers()
+		if err != nil {
+			return fmt.Errorf("could not select key from key store: %w", err)
+		}
+
+		// During a key rotation the key valid on the seal date is used, not the one valid today
+		sealDate, err = bgFile.SealDay()
+		if err != nil {
+			return fmt.Errorf("could not select key from key store: %w", err)
+		}
+	}
+
+	signer, release, err := sk.Signer(customerNumber, bankgiro, sealDate)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := bgFile.SetSigner(signer); err != nil {
+		return err
+	}
+
+	if sk.profile != nil {
+		if err := bgFile.SetNormalizationProfile(sk.profile); err != nil {
+			return err
+		}
+	}
+
+	if sk.auditLog != nil {
+		bgFile.AuditLog = sk.auditLog
+	}
+
+	return bgFile.Sign()
+}
+
+// Release the key
+func (sk *SealKey) Close() {
+	if sk.close != nil {
+		sk.close()
+		sk.close = nil
+	}
+}
+
+// Create a software signer from a hex key, checking it against the KVV
+func (sk *SealKey) softwareSigner(key []byte) (*seal.SoftwareSigner, error) {
+	signer, err := seal.NewSoftwareSigner(key)
+	if err != nil {
+		return nil, seal.DiagnoseKeyError(err, key, sk.kvv)
+	}
+
+	if err := sk.checkKvv(signer, key); err != nil {
+		signer.Clear()
+		return nil, err
+	}
+
+	return signer, nil
+}
+
+// Check the signer against --kvv, the key is only used for diagnostics and may be nil
+func (sk *SealKey) checkKvv(signer seal.Signer, key []byte) error {
+	if sk.kvv == "" {
+		return nil
+	}
+
+	sealer := seal.HmacSealer{}
+	if err := sealer.SetSigner(signer); err != nil {
+		return err
+	}
+
+	if err := sealer.CheckKvv(sk.kvv); err != nil {
+		if key == nil {
+			return err
+		}
+
+		return seal.DiagnoseKeyError(err, key, sk.kvv)
+	}
+
+	return nil
+}
+
+// Load the seal key from the selected key source
+// The returned buffer should be zeroed with keys.Zero when no longer needed
+func LoadSealKey(c *cli.Context) ([]byte, error) {
+	if err := CheckKeySource(c); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c.IsSet("key-file"):
+		return keys.FromFile(c.String("key-file"))
+	case c.IsSet("key-stdin"):
+		return keys.FromReader(os.Stdin)
+	case c.IsSet("key-command"):
+		return keys.FromCommand(c.String("key-command"))
+	case c.IsSet("key-name"):
+		store, _, err := OpenKeyStore(c)
+		if err != nil {
+			return nil, err
+		}
+		defer store.Close()
+
+		entry, err := store.Get(c.String("key-name"))
+		if err != nil {
+			return nil, err
+		}
+
+		key := make([]byte, len(entry.Key))
+		copy(key, entry.Key)
+
+		return key, nil
+	}
+
+	return []byte(c.String("key")), nil
+}
+
+// Read the key store passphrase from the passphrase file or environment
+func keyStorePassphrase(c *cli.Context) (string, error) {
+	if path := c.String("key-store-passphrase-file"); path != "" {
+		passphrase, err := keys.FromFile(path)
+		if err != nil {
+			return "", err
+		}
+		defer keys.Zero(passphrase)
+
+		return string(passphrase), nil
+	}
+
+	if passphrase := os.Getenv("BG_KEY_STORE_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+
+	return "", cli.Exit("key store passphrase is required, set BG_KEY_STORE_PASSPHRASE or use --key-store-passphrase-file", 1)
+}
+
+// Open the key store given by --key-store
+// The store is decrypted with --key-store-identity if given, otherwise with the passphrase
+// The returned recipient is used to save the store after changes
+func OpenKeyStore(c *cli.Context) (*keys.Store, age.Recipient, error) {
+	if c.String("key-store") == "" {
+		return nil, nil, cli.Exit("--key-store is required", 1)
+	}
+
+	var identity age.Identity
+	var recipient age.Recipient
+
+	if path := c.String("key-store-identity"); path != "" {
+		var err error
+		identity, recipient, err = keys.IdentityFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		passphrase, err := keyStorePassphrase(c)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		identity, recipient, err = keys.PassphraseIdentity(passphrase)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	store, err := keys.OpenStore(c.String("key-store"), identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return store, recipient, nil
+}
+
+// Select the normalization rules by profile name or JSON file
+func NormalizationProfileFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "normalization-profile",
+		Usage:   "normalization rules to seal with, a profile name or JSON profile file, default is " + seal.DefaultProfile.Name,
+		EnvVars: []string{"BG_NORMALIZATION_PROFILE"},
+	}
+}
+
+// Get the normalization profile selected with --normalization-profile, nil for the default
+func NormalizationProfile(c *cli.Context) (*seal.NormalizationProfile, error) {
+	name := c.String("normalization-profile")
+	if name == "" {
+		return nil, nil
+	}
+
+	profile, err := seal.FindProfile(name)
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+
+	return profile, nil
+}
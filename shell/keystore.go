@@ -0,0 +1,295 @@
+package shell
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/keys"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// Flags describing a key when it is added to the key store
+func KeyEntryFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "customer-number",
+			Usage: "customer number the key is used for, matched against the TK01 opening record",
+		},
+		&cli.StringFlag{
+			Name:  "bankgiro",
+			Usage: "bankgiro number the key is used for, matched against the TK01 opening record",
+		},
+		&cli.StringFlag{
+			Name:  "valid-from",
+			Usage: "first date the key is used on (YYYY-MM-DD)",
+		},
+		&cli.StringFlag{
+			Name:  "valid-to",
+			Usage: "last date the key is used on (YYYY-MM-DD)",
+		},
+	}
+}
+
+// Add a key read from stdin to the key store
+func KeyAdd(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit("key name is required", 1)
+	}
+
+	store, recipient, err := OpenKeyStore(c)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	key, err := keys.FromReader(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	entry := keys.Entry{
+		Name:           name,
+		Key:            key,
+		ValidFrom:      c.String("valid-from"),
+		ValidTo:        c.String("valid-to"),
+		CustomerNumber: c.String("customer-number"),
+		Bankgiro:       c.String("bankgiro"),
+	}
+
+	if err := store.Add(entry); err != nil {
+		keys.Zero(key)
+		return err
+	}
+
+	if err := store.Save(recipient); err != nil {
+		return err
+	}
+
+	added, _ := store.Get(name)
+	fmt.Printf("Key %s added with KVV %s\r\n", name, added.Kvv)
+
+	return nil
+}
+
+// List the keys in the key store, the keys themselves are never printed
+func KeyList(c *cli.Context) error {
+	store, _, err := OpenKeyStore(c)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATUS\tCUSTOMER\tBANKGIRO\tVALID FROM\tVALID TO\tKVV")
+	for _, entry := range store.Keys {
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Name,
+			entry.Status(now),
+			orDash(entry.CustomerNumber),
+			orDash(entry.Bankgiro),
+			orDash(entry.ValidFrom),
+			orDash(entry.ValidTo),
+			entry.Kvv,
+		)
+	}
+
+	return w.Flush()
+}
+
+// Replace a key with a new key read from stdin
+func KeyRotate(c *cli.Context) error {
+	if c.Args().Len() != 2 {
+		return cli.Exit("old and new key name are required", 1)
+	}
+
+	validFrom := time.Now()
+	if date := c.String("valid-from"); date != "" {
+		var err error
+		validFrom, err = time.Parse(keys.DateFormat, date)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("invalid date %s, expected YYYY-MM-DD", date), 1)
+		}
+	}
+
+	store, recipient, err := OpenKeyStore(c)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	key, err := keys.FromReader(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	replacement := keys.Entry{
+		Name:           c.Args().Get(1),
+		Key:            key,
+		ValidTo:        c.String("valid-to"),
+		CustomerNumber: c.String("customer-number"),
+		Bankgiro:       c.String("bankgiro"),
+	}
+
+	if err := store.Rotate(c.Args().Get(0), replacement, validFrom); err != nil {
+		keys.Zero(key)
+		return err
+	}
+
+	if err := store.Save(recipient); err != nil {
+		return err
+	}
+
+	old, _ := store.Get(c.Args().Get(0))
+	added, _ := store.Get(replacement.Name)
+	fmt.Printf("Key %s expires after %s\r\n", old.Name, old.ValidTo)
+	fmt.Printf("Key %s valid from %s with KVV %s\r\n", added.Name, added.ValidFrom, added.Kvv)
+
+	return nil
+}
+
+// Remove a key from the key store
+func KeyRemove(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit("key name is required", 1)
+	}
+
+	store, recipient, err := OpenKeyStore(c)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.Remove(name); err != nil {
+		return err
+	}
+
+	if err := store.Save(recipient); err != nil {
+		return err
+	}
+
+	fmt.Printf("Key %s removed\r\n", name)
+
+	return nil
+}
+
+// Print the KVV of a key
+func KeyKvv(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit("key name is required", 1)
+	}
+
+	store, _, err := OpenKeyStore(c)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	entry, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(entry.Kvv)
+
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+// Combine key components into a key file
+// Components are read from --component-file or entered on the terminal without echo
+func KeyCombine(c *cli.Context) error {
+	components := []keys.Component{}
+	defer func() {
+		for _, component := range components {
+			keys.Zero(component.Value)
+		}
+	}()
+
+	if files := c.StringSlice("component-file"); len(files) > 0 {
+		for _, path := range files {
+			if err := keys.CheckPermissions(path); err != nil {
+				return err
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+
+			component, err := keys.ReadComponent(f)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			components = append(components, component)
+		}
+	} else {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return cli.Exit("components must be entered on a terminal, use --component-file otherwise", 1)
+		}
+
+		for i := 1; i <= c.Int("components"); i++ {
+			fmt.Fprintf(os.Stderr, "Key component %d: ", i)
+			value, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "Check value for component %d (empty to skip): ", i)
+			checkValue, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				keys.Zero(value)
+				return err
+			}
+
+			components = append(components, keys.Component{
+				Value:      bytes.TrimSpace(value),
+				CheckValue: string(bytes.TrimSpace(checkValue)),
+			})
+		}
+	}
+
+	for i, component := range components {
+		if component.CheckValue == "" {
+			fmt.Printf("Component %d has no check value, it is only verified through the combined KVV\r\n", i+1)
+		}
+	}
+
+	kvv := c.String("kvv")
+	key, err := keys.CombineComponents(components, kvv)
+	if err != nil {
+		return err
+	}
+	defer keys.Zero(key)
+
+	output := c.String("output")
+	if err := keys.WriteKeyFile(output, key, strings.ToUpper(kvv)); err != nil {
+		return err
+	}
+
+	fmt.Printf("%d components combined and verified against KVV %s\r\n", len(components), strings.ToUpper(kvv))
+	fmt.Println("Key saved to", output)
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package shell
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/hoglandets-it/go-bankgiro/parse"
+	"github.com/hoglandets-it/go-bankgiro/reconcile"
+	"github.com/urfave/cli/v2"
+)
+
+// Reconcile a Betalningsspecifikation against the expected charges in a CSV file
+func Reconcile(c *cli.Context) error {
+	input := c.Args().First()
+	if input == "" {
+		return cli.Exit("file is required", 1)
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	spec, err := readPaymentSpecification(content)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	charges, err := reconcile.LoadCsv(c.String("charges"))
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	report, err := reconcile.Reconcile(spec, charges)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	if c.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	return report.Write(os.Stdout)
+}
+
+// Parse the file and get its Betalningsspecifikation records, old format sections are converted to the new format
+func readPaymentSpecification(content []byte) (*parse.PaymentSpecification, error) {
+	agFile, err := readAutogiroFile(content)
+	if err != nil {
+		return nil, err
+	}
+
+	newFile, err := agFile.ToNewFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	return newFile.PaymentSpecification()
+}
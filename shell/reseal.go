@@ -0,0 +1,262 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/keys"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+	"github.com/urfave/cli/v2"
+)
+
+// Flags selecting the key a file was sealed with, reseal verifies the file with it before sealing it with the new key
+// Without them, the key is selected from --key-store by the KVV in the trailer
+func OldKeyFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "old-key",
+			Usage:   "key the file was sealed with (visible in process listings, prefer the other key sources)",
+			EnvVars: []string{"BG_OLD_SEAL_KEY"},
+		},
+		&cli.StringFlag{
+			Name:    "old-key-file",
+			Usage:   "read the key the file was sealed with from a file only readable by its owner",
+			EnvVars: []string{"BG_OLD_SEAL_KEY_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "old-key-command",
+			Usage:   "read the key the file was sealed with from the output of a command",
+			EnvVars: []string{"BG_OLD_SEAL_KEY_COMMAND"},
+		},
+		&cli.StringFlag{
+			Name:    "old-key-name",
+			Usage:   "read the key the file was sealed with from the key store",
+			EnvVars: []string{"BG_OLD_SEAL_KEY_NAME"},
+		},
+	}
+}
+
+// Verify the seal of a sealed file and write its content without the HMAC header and trailer
+func Unseal(c *cli.Context) error {
+	input, content, err := readSealed(c)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := sign.ParseSealedFile(content)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	verifier, release, err := verifyKey(c, "", sealed.Kvv)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	profile, err := NormalizationProfile(c)
+	if err != nil {
+		return err
+	}
+
+	unsealed, verification, err := sign.Unseal(content, verifier, profile)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	fmt.Printf("Seal verified, sealed %s with KVV %s\r\n", verification.SealDate, verification.Kvv)
+
+	output, err := createOutput(c, input, "unsealed")
+	if err != nil {
+		return err
+	}
+
+	if _, err := output.Write(unsealed); err != nil {
+		output.Close()
+		return err
+	}
+
+	fmt.Println("File saved to", output.Name())
+
+	return output.Close()
+}
+
+// Verify the seal of a sealed file with the old key and seal it again with the new key or seal date
+func Reseal(c *cli.Context) error {
+	date := c.String("date")
+	if date != "" {
+		if _, err := time.Parse("060102", date); err != nil {
+			return cli.Exit("--date must be formatted as YYMMDD", 1)
+		}
+	}
+
+	if err := CheckKeySource(c); err != nil {
+		return err
+	}
+
+	input, content, err := readSealed(c)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := sign.ParseSealedFile(content)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	verifier, release, err := verifyKey(c, "old-", sealed.Kvv)
+	if err != nil {
+		return err
+	}
+
+	profile, err := NormalizationProfile(c)
+	if err != nil {
+		release()
+		return err
+	}
+
+	bgFile, verification, err := sign.Reseal(content, verifier, profile)
+	release()
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	fmt.Printf("Seal verified, sealed %s with KVV %s\r\n", verification.SealDate, verification.Kvv)
+
+	bgFile.Source = input
+	if date != "" {
+		bgFile.SetSealDate(date)
+	}
+
+	sealKey, err := OpenSealKey(c)
+	if err != nil {
+		return err
+	}
+	defer sealKey.Close()
+
+	if err := sealKey.Seal(bgFile); err != nil {
+		return err
+	}
+
+	if bgFile.Seal.GetKvvBgFormat() == verification.Kvv && bgFile.Seal.SealDate == verification.SealDate {
+		fmt.Println("The file is sealed with the same key and date as before")
+	}
+	fmt.Printf("Resealed %s with KVV %s, MAC %s\r\n", bgFile.Seal.SealDate, bgFile.Seal.GetKvvBgFormat(), bgFile.Seal.GetMacBgFormat())
+
+	output, err := createOutput(c, input, "resealed")
+	if err != nil {
+		return err
+	}
+
+	if _, err := bgFile.WriteTo(output); err != nil {
+		output.Close()
+		return err
+	}
+
+	fmt.Println("File saved to", output.Name())
+
+	return output.Close()
+}
+
+// Read the sealed file given as the first argument
+func readSealed(c *cli.Context) (string, []byte, error) {
+	input := c.Args().First()
+	if input == "" {
+		return "", nil, cli.Exit("sealed file is required", 1)
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		return "", nil, cli.Exit(err.Error(), 1)
+	}
+
+	return input, content, nil
+}
+
+// Create the file given by --output, default is [input]-[suffix]
+// An existing file is only replaced with --overwrite
+func createOutput(c *cli.Context, input string, suffix string) (*os.File, error) {
+	path := c.String("output")
+	if path == "" {
+		path = fmt.Sprintf("%s-%s", input, suffix)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if c.Bool("overwrite") {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if os.IsExist(err) {
+		return nil, cli.Exit(fmt.Sprintf("%s already exists, use -f to overwrite", path), 1)
+	}
+
+	return f, err
+}
+
+// Get the signer to verify a file sealed with the given KVV
+// The key is read from the --[prefix]key sources, or selected from the key store by the KVV
+// The returned function releases the signer and must be called once verifying is done
+func verifyKey(c *cli.Context, prefix string, kvv string) (seal.Signer, func(), error) {
+	if prefix == "" && len(keySources(c)) > 0 {
+		sk, err := OpenSealKey(c)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return sk.signer, sk.Close, nil
+	}
+
+	var key []byte
+	var err error
+
+	switch {
+	case c.IsSet(prefix + "key-file"):
+		key, err = keys.FromFile(c.String(prefix + "key-file"))
+	case c.IsSet(prefix + "key-command"):
+		key, err = keys.FromCommand(c.String(prefix + "key-command"))
+	case c.IsSet(prefix + "key"):
+		key = []byte(c.String(prefix + "key"))
+	case c.String("key-store") != "":
+		key, err = storedKey(c, c.String(prefix+"key-name"), kvv)
+	default:
+		return nil, nil, cli.Exit(fmt.Sprintf("the key the file was sealed with is required, use one of --%[1]skey, --%[1]skey-file, --%[1]skey-command, --%[1]skey-name or --key-store", prefix), 1)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer keys.Zero(key)
+
+	signer, err := seal.NewSoftwareSigner(key)
+	if err != nil {
+		return nil, nil, seal.DiagnoseKeyError(err, key, kvv)
+	}
+
+	return signer, signer.Clear, nil
+}
+
+// Copy the key with the given name, or else the given KVV, from the key store
+func storedKey(c *cli.Context, name string, kvv string) ([]byte, error) {
+	store, _, err := OpenKeyStore(c)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	var entry *keys.Entry
+	if name != "" {
+		entry, err = store.Get(name)
+	} else {
+		entry, err = store.FindKvv(kvv)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Verifying with key %s (KVV %s)\r\n", entry.Name, entry.Kvv)
+
+	key := make([]byte, len(entry.Key))
+	copy(key, entry.Key)
+
+	return key, nil
+}
@@ -0,0 +1,115 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hoglandets-it/go-bankgiro/keys"
+	"github.com/hoglandets-it/go-bankgiro/rpc"
+	"github.com/hoglandets-it/go-bankgiro/server"
+	"github.com/urfave/cli/v2"
+)
+
+// Serve the HTTP sealing and verification API, and the gRPC API if --grpc-listen is set, until interrupted
+func Serve(c *cli.Context) error {
+	tokens, err := serveTokens(c)
+	if err != nil {
+		return err
+	}
+
+	if (c.String("tls-cert") == "") != (c.String("tls-key") == "") {
+		return cli.Exit("both --tls-cert and --tls-key must be set to serve HTTPS", 1)
+	}
+
+	sealKey, err := OpenSealKey(c)
+	if err != nil {
+		return err
+	}
+	defer sealKey.Close()
+
+	srv, err := server.New(server.Config{
+		Addr:            c.String("listen"),
+		Tokens:          tokens,
+		MaxBodySize:     c.Int64("max-body-size"),
+		ShutdownTimeout: c.Duration("shutdown-timeout"),
+		TLSCert:         c.String("tls-cert"),
+		TLSKey:          c.String("tls-key"),
+		AuditLog:        sealKey.AuditLog(),
+		Profile:         sealKey.Profile(),
+	}, sealKey)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if c.String("grpc-listen") == "" {
+		return srv.Run(ctx)
+	}
+
+	grpcSrv, err := rpc.New(rpc.Config{
+		Addr:            c.String("grpc-listen"),
+		Tokens:          tokens,
+		MaxFileSize:     c.Int64("max-body-size"),
+		ShutdownTimeout: c.Duration("shutdown-timeout"),
+		TLSCert:         c.String("tls-cert"),
+		TLSKey:          c.String("tls-key"),
+		AuditLog:        sealKey.AuditLog(),
+		Profile:         sealKey.Profile(),
+	}, sealKey)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	// Stop both servers when either of them fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, 2)
+	for _, run := range []func(context.Context) error{srv.Run, grpcSrv.Run} {
+		go func(run func(context.Context) error) {
+			err := run(ctx)
+			cancel()
+			errs <- err
+		}(run)
+	}
+
+	return errors.Join(<-errs, <-errs)
+}
+
+// Read the accepted bearer tokens, one per line, from --token-file or BG_SERVE_TOKENS
+func serveTokens(c *cli.Context) ([]string, error) {
+	var content string
+	if path := c.String("token-file"); path != "" {
+		if err := keys.CheckPermissions(path); err != nil {
+			return nil, cli.Exit(err.Error(), 1)
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, cli.Exit(err.Error(), 1)
+		}
+		content = string(b)
+	} else {
+		content = os.Getenv("BG_SERVE_TOKENS")
+	}
+
+	tokens := []string{}
+	for _, line := range strings.FieldsFunc(content, func(r rune) bool { return r == '\n' || r == ',' }) {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			tokens = append(tokens, line)
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, cli.Exit("no bearer tokens configured, use --token-file or BG_SERVE_TOKENS", 1)
+	}
+
+	return tokens, nil
+}
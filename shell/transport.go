@@ -0,0 +1,149 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hoglandets-it/go-bankgiro/transport"
+	"github.com/urfave/cli/v2"
+)
+
+// The flags shared by the send and fetch commands
+func TransportFlags() []cli.Flag {
+	knownHosts := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		knownHosts = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "host",
+			Required: true,
+			Usage:    "SFTP server to connect to",
+			EnvVars:  []string{"BG_SFTP_HOST"},
+		},
+		&cli.IntFlag{
+			Name:    "port",
+			Value:   22,
+			Usage:   "SFTP server port",
+			EnvVars: []string{"BG_SFTP_PORT"},
+		},
+		&cli.StringFlag{
+			Name:     "user",
+			Required: true,
+			Usage:    "SFTP user name",
+			EnvVars:  []string{"BG_SFTP_USER"},
+		},
+		&cli.StringFlag{
+			Name:    "identity",
+			Aliases: []string{"i"},
+			Usage:   "private key to authenticate with, its passphrase is read from BG_SFTP_IDENTITY_PASSPHRASE, default is the password in BG_SFTP_PASSWORD",
+			EnvVars: []string{"BG_SFTP_IDENTITY"},
+		},
+		&cli.StringFlag{
+			Name:    "known-hosts",
+			Value:   knownHosts,
+			Usage:   "known_hosts file to verify the host key with",
+			EnvVars: []string{"BG_SFTP_KNOWN_HOSTS"},
+		},
+		&cli.StringFlag{
+			Name:    "host-key-fingerprint",
+			Usage:   "SHA256 fingerprint of the host key, e.g. SHA256:..., used instead of --known-hosts",
+			EnvVars: []string{"BG_SFTP_HOST_KEY_FINGERPRINT"},
+		},
+		&cli.StringFlag{
+			Name:    "state",
+			Value:   "bankgiro-transfer.json",
+			Usage:   "file tracking what has been sent and fetched",
+			EnvVars: []string{"BG_TRANSFER_STATE"},
+		},
+	}
+}
+
+func transportConfig(c *cli.Context) transport.Config {
+	return transport.Config{
+		Host:               c.String("host"),
+		Port:               c.Int("port"),
+		User:               c.String("user"),
+		IdentityFile:       c.String("identity"),
+		Passphrase:         os.Getenv("BG_SFTP_IDENTITY_PASSPHRASE"),
+		Password:           os.Getenv("BG_SFTP_PASSWORD"),
+		KnownHostsFile:     c.String("known-hosts"),
+		HostKeyFingerprint: c.String("host-key-fingerprint"),
+	}
+}
+
+// Upload sealed files to the remote outbox
+func Send(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.Exit("at least one file to send is required", 1)
+	}
+
+	config := transportConfig(c)
+	config.RemoteOutbox = c.String("remote-dir")
+
+	return transfer(c, config, func(client *transport.Client, state *transport.State) ([]transport.Result, error) {
+		return client.Send(state, c.Args().Slice(), c.Bool("force")), nil
+	})
+}
+
+// Download new return files from the remote inbox
+func Fetch(c *cli.Context) error {
+	inbox := c.String("inbox")
+	if err := os.MkdirAll(inbox, 0755); err != nil {
+		return err
+	}
+
+	config := transportConfig(c)
+	config.RemoteInbox = c.String("remote-dir")
+
+	return transfer(c, config, func(client *transport.Client, state *transport.State) ([]transport.Result, error) {
+		return client.Fetch(state, inbox, c.StringSlice("pattern"), c.Bool("remove"))
+	})
+}
+
+// Connect, run the transfer and save the state, failed files are reported with exit code 2
+func transfer(c *cli.Context, config transport.Config, run func(*transport.Client, *transport.State) ([]transport.Result, error)) error {
+	state, err := transport.LoadState(c.String("state"))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("could not read state file: %v", err), 1)
+	}
+
+	client, err := transport.Dial(config)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	defer client.Close()
+
+	results, runErr := run(client, state)
+
+	if err := state.Save(); err != nil {
+		return cli.Exit(fmt.Sprintf("could not save state file: %v", err), 1)
+	}
+
+	failed := 0
+	for _, result := range results {
+		switch result.Status {
+		case transport.StatusSent:
+			fmt.Printf("sent     %s -> %s\r\n", result.Local, result.Remote)
+		case transport.StatusFetched:
+			fmt.Printf("fetched  %s -> %s\r\n", result.Remote, result.Local)
+		case transport.StatusSkipped:
+			fmt.Printf("skipped  %s: %s\r\n", result.Remote, result.Reason)
+		case transport.StatusFailed:
+			failed++
+			fmt.Printf("failed   %s: %s\r\n", result.Remote, result.Reason)
+		}
+	}
+
+	if runErr != nil {
+		return cli.Exit(runErr.Error(), 1)
+	}
+
+	if failed > 0 {
+		return cli.Exit(fmt.Sprintf("%d files could not be transferred", failed), 2)
+	}
+
+	return nil
+}
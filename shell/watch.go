@@ -0,0 +1,37 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hoglandets-it/go-bankgiro/watch"
+	"github.com/urfave/cli/v2"
+)
+
+// Watch an inbox directory and seal files as they arrive, until interrupted
+func WatchInbox(c *cli.Context) error {
+	sealKey, err := OpenSealKey(c)
+	if err != nil {
+		return err
+	}
+	defer sealKey.Close()
+
+	watcher, err := watch.New(watch.Options{
+		Inbox:      c.String("inbox"),
+		Outbox:     c.String("outbox"),
+		Archive:    c.String("archive"),
+		ErrorDir:   c.String("error-dir"),
+		Patterns:   c.StringSlice("pattern"),
+		SettleTime: c.Duration("settle-time"),
+	}, sealKey.Seal)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return watcher.Run(ctx)
+}
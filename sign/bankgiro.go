@@ -1,17 +1,44 @@
 package sign
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"io/fs"
+	"time"
 
+	"github.com/hoglandets-it/go-bankgiro/audit"
+	"github.com/hoglandets-it/go-bankgiro/calendar"
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/parse"
 	"github.com/hoglandets-it/go-bankgiro/seal"
 	"github.com/hoglandets-it/go-bankgiro/tools"
 )
 
 // The type representing an outgoing Bankgiro file
+// Source: the file name recorded in the audit log
+// AuditLog: when set, each seal is recorded in the audit log
+// Encoding: the encoding the content was read as
+// Substitutions: the characters replaced by transliteration
+// DateProblems: the payment dates Bankgirot would move or reject, checked against the seal date when the content is converted
+// and again when the seal date is changed
+// Charset: how content added with Write or ReadFrom is converted to ISO-8859-1
 type BankgiroFile struct {
 	Content          string
 	FormattedContent string
+	Encoding         charset.Detection
+	Substitutions    []charset.Substitution
+	DateProblems     []calendar.DateProblem
 	Seal             seal.HmacSealer
+	Source           string
+	AuditLog         *audit.Log
+	Charset          charset.Options
+	input            []byte
+	converted        []byte
+	prepared         bool
+	output           io.Reader
 }
 
 // Creates a new Bankgiro file with the given content
@@ -19,6 +46,7 @@ func CreateBankgiroFile(content string) BankgiroFile {
 	bgf := BankgiroFile{
 		Content: content,
 		Seal:    seal.HmacSealer{},
+		input:   []byte(content),
 	}
 	// Formats the content
 	// 1. Replaces line endings with CRLF
@@ -28,22 +56,126 @@ func CreateBankgiroFile(content string) BankgiroFile {
 	return bgf
 }
 
-// Creates a new Bankgiro file with the given byteslice content
+// Creates a new Bankgiro file with the given byteslice content, detecting its encoding
 func CreateBankgiroFileBytes(content []byte) (BankgiroFile, error) {
-	isoString, err := tools.BytesToIsoString(content)
+	return CreateBankgiroFileEncoding(content, "")
+}
+
+// Creates a new Bankgiro file with the given byteslice content read as the given encoding, detected when empty
+// Characters that cannot be represented in ISO-8859-1 are returned as a *charset.UnrepresentableError
+func CreateBankgiroFileEncoding(content []byte, encoding charset.Encoding) (BankgiroFile, error) {
+	return CreateBankgiroFileOptions(content, charset.Options{Encoding: encoding})
+}
+
+// Creates a new Bankgiro file with the given byteslice content, converted to ISO-8859-1 with the given options
+// Characters that can neither be represented nor transliterated are returned as a *charset.UnrepresentableError
+func CreateBankgiroFileOptions(content []byte, opts charset.Options) (BankgiroFile, error) {
+	bgf := BankgiroFile{Charset: opts, input: content}
+
+	result, err := bgf.convert()
 	if err != nil {
 		return BankgiroFile{}, err
 	}
 
-	bgf := BankgiroFile{
-		Content: isoString,
-		Seal:    seal.HmacSealer{},
+	bgf.Content = tools.EnsureCrlfString(string(result.Content))
+	bgf.FormattedContent = string(bgf.Seal.OriginalData)
+
+	return bgf, nil
+}
+
+// Creates an empty Bankgiro file, the content is added with Write or ReadFrom and converted with opts when sealed
+// The content is only held as bytes, Content and FormattedContent are not set
+func NewBankgiroFile(opts charset.Options) *BankgiroFile {
+	return &BankgiroFile{Charset: opts}
+}
+
+// Add content to the file, it is converted to ISO-8859-1 and formatted once when sealed
+func (bg *BankgiroFile) Write(p []byte) (int, error) {
+	if bg.Seal.Mac != nil {
+		return 0, fmt.Errorf("cannot add content to a sealed file")
 	}
 
-	bgf.FormattedContent = seal.FormatContentString(bgf.Content)
-	bgf.Seal.SetData(bgf.FormattedContent)
+	bg.input = append(bg.input, p...)
+	bg.prepared = false
 
-	return bgf, nil
+	return len(p), nil
+}
+
+// Read all content from r into the file, see Write
+func (bg *BankgiroFile) ReadFrom(r io.Reader) (int64, error) {
+	if bg.Seal.Mac != nil {
+		return 0, fmt.Errorf("cannot add content to a sealed file")
+	}
+
+	// Allocate once when the size is known up front
+	buf := bytes.NewBuffer(bg.input)
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		buf.Grow(r.Len() + bytes.MinRead)
+	case interface{ Stat() (fs.FileInfo, error) }:
+		if info, err := r.Stat(); err == nil && info.Mode().IsRegular() {
+			buf.Grow(int(info.Size()) + bytes.MinRead)
+		}
+	}
+
+	n, err := buf.ReadFrom(r)
+	bg.input = buf.Bytes()
+	bg.prepared = false
+
+	return n, err
+}
+
+// Get the content as added with Write or ReadFrom, before conversion
+func (bg *BankgiroFile) Input() []byte {
+	return bg.input
+}
+
+// Convert the content added with Write or ReadFrom to ISO-8859-1 and format it for sealing, unless already done
+// Sign does this when needed, call it first to get Encoding, Substitutions and DateProblems or the conversion error early
+func (bg *BankgiroFile) Prepare() error {
+	if bg.prepared {
+		return nil
+	}
+
+	_, err := bg.convert()
+	return err
+}
+
+// Convert the content to ISO-8859-1 and hand it to the sealer
+func (bg *BankgiroFile) convert() (*charset.Result, error) {
+	// The sealer keeps an existing HMAC header, sealing a sealed file would add a second trailer
+	if IsSealed(bg.input) {
+		return nil, fmt.Errorf("file is already sealed, unseal or reseal it instead")
+	}
+
+	result, err := charset.Convert(bg.input, bg.Charset)
+	if err != nil {
+		return nil, err
+	}
+
+	bg.Encoding = result.Detection
+	bg.Substitutions = result.Substitutions
+	bg.converted = result.Content
+	bg.prepared = true
+
+	// The sealer sets the seal date to today unless one is set
+	if err := bg.Seal.SetDataBytes(seal.FormatBytes(result.Content)); err != nil {
+		return result, err
+	}
+	bg.checkPaymentDates()
+
+	return result, nil
+}
+
+// Check the payment dates of the converted content against the seal date, the day the file is sent
+func (bg *BankgiroFile) checkPaymentDates() {
+	sealDate, err := time.Parse("060102", bg.Seal.SealDate)
+	if err != nil {
+		bg.DateProblems = nil
+		return
+	}
+
+	bg.DateProblems = calendar.CheckPaymentDates(bg.converted, sealDate, calendar.PaymentLeadDays)
 }
 
 // Set the key used to seal the Bankgiro file
@@ -56,22 +188,58 @@ func (bg *BankgiroFile) SetSealKeyBytes(key []byte) error {
 	return bg.Seal.SetKeyBytes(key)
 }
 
+// Set an external signer used to seal the Bankgiro file, e.g. an HSM
+func (bg *BankgiroFile) SetSigner(signer seal.Signer) error {
+	return bg.Seal.SetSigner(signer)
+}
+
+// Zero the seal key held in memory, call when the key is no longer needed
+func (bg *BankgiroFile) ClearSealKey() {
+	bg.Seal.ClearKey()
+}
+
 // Check the KVV value against a provided value
 func (bg *BankgiroFile) CheckKvv(kvv string) error {
 	return bg.Seal.CheckKvv(kvv)
 }
 
-// Set a custom Seal Date
+// Set the normalization rules used to seal the Bankgiro file
+func (bg *BankgiroFile) SetNormalizationProfile(profile *seal.NormalizationProfile) error {
+	return bg.Seal.SetProfile(profile)
+}
+
+// Set a custom Seal Date, the payment dates are checked again against it once the content is converted
 func (bg *BankgiroFile) SetSealDate(date string) {
 	bg.Seal.SetSealDate(date)
+
+	if bg.prepared {
+		bg.checkPaymentDates()
+	}
+}
+
+// Get the date the file is sealed on, today unless set with SetSealDate
+func (bg *BankgiroFile) SealDay() (time.Time, error) {
+	if err := bg.Prepare(); err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse("060102", bg.Seal.SealDate)
 }
 
 // Check if the file is ready to be signed
 func (bg *BankgiroFile) ReadyToSign() bool {
-	return bg.Seal.Key != nil && bg.Seal.KeyVer != nil && bg.FormattedContent != "" && bg.Seal.Validate() == nil
+	if err := bg.Prepare(); err != nil {
+		return false
+	}
+
+	return bg.Seal.HasKey() && bg.Seal.KeyVer != nil && len(bg.Seal.OriginalData) > 0 && bg.Seal.Validate() == nil
 }
 
 func (bg *BankgiroFile) Sign() error {
+	if err := bg.Prepare(); err != nil {
+		return err
+	}
+
 	if !bg.ReadyToSign() {
 		return fmt.Errorf("not ready to sign - error")
 	}
@@ -81,6 +249,34 @@ func (bg *BankgiroFile) Sign() error {
 		return err
 	}
 
+	if bg.AuditLog != nil {
+		return bg.audit()
+	}
+
+	return nil
+}
+
+// Record the seal in the audit log
+func (bg *BankgiroFile) audit() error {
+	inputSum := sha256.Sum256(bg.input)
+	outputHash := sha256.New()
+	if _, err := bg.WriteTo(outputHash); err != nil {
+		return err
+	}
+	outputSum := outputHash.Sum(nil)
+
+	_, err := bg.AuditLog.Append(audit.Record{
+		Source:       bg.Source,
+		InputSha256:  hex.EncodeToString(inputSum[:]),
+		OutputSha256: hex.EncodeToString(outputSum[:]),
+		Kvv:          bg.Seal.GetKvvBgFormat(),
+		Mac:          bg.Seal.GetMacBgFormat(),
+		SealDate:     bg.Seal.SealDate,
+	})
+	if err != nil {
+		return fmt.Errorf("sealed, but could not record the seal in the audit log: %w", err)
+	}
+
 	return nil
 }
 
@@ -88,6 +284,74 @@ func (bg *BankgiroFile) GetSignedData() string {
 	return bg.Seal.GetSignedContent()
 }
 
+// Get the sealed file
+func (bg *BankgiroFile) Bytes() []byte {
+	return bg.Seal.GetSignedBytes()
+}
+
+// Write the sealed file to w without copying the content
+func (bg *BankgiroFile) WriteTo(w io.Writer) (int64, error) {
+	if bg.Seal.Mac == nil {
+		return 0, fmt.Errorf("file is not sealed")
+	}
+
+	var written int64
+	for _, part := range [][]byte{bg.Seal.PrefixedData, {'\r', '\n'}, bg.Seal.Trailer()} {
+		n, err := w.Write(part)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// Read the sealed file
+func (bg *BankgiroFile) Read(p []byte) (int, error) {
+	if bg.output == nil {
+		if bg.Seal.Mac == nil {
+			return 0, fmt.Errorf("file is not sealed")
+		}
+
+		bg.output = io.MultiReader(bytes.NewReader(bg.Seal.PrefixedData), bytes.NewReader([]byte{'\r', '\n'}), bytes.NewReader(bg.Seal.Trailer()))
+	}
+
+	return bg.output.Read(p)
+}
+
+// Get the customer number and bankgiro number from the opening record of the file
+func (bg *BankgiroFile) OpeningNumbers() (customerNumber string, bankgiro string, err error) {
+	if err := bg.Prepare(); err != nil {
+		return "", "", err
+	}
+
+	// Blank rows are removed, so the opening record follows the HMAC header
+	head := bg.Seal.FormattedData
+	for i, rows := 0, 0; i < len(head); i++ {
+		if head[i] == '\n' {
+			if rows++; rows == 3 {
+				head = head[:i]
+				break
+			}
+		}
+	}
+
+	return parse.OpeningNumbers(string(head))
+}
+
+// Check if content already carries an HMAC seal, a 00 header row and a 99 trailer row
+func IsSealed(content []byte) bool {
+	trimmed := bytes.TrimSpace(content)
+	if !bytes.HasPrefix(trimmed, []byte(parse.HMAC_HEADER)) {
+		return false
+	}
+
+	lastRow := trimmed[bytes.LastIndexAny(trimmed, "\r\n")+1:]
+
+	return bytes.HasPrefix(lastRow, []byte(parse.HMAC_FILE_SEAL))
+}
+
 // TODO: Remove all blank/space-only rows
 // TODO: Add check for BG Number (ensureBgNumberCorrect)
 // TODO: Add regex \r\n[ ]*\r\n
@@ -2,11 +2,18 @@ package sign_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/hoglandets-it/go-bankgiro/audit"
+	"github.com/hoglandets-it/go-bankgiro/batch"
+	"github.com/hoglandets-it/go-bankgiro/charset"
 	"github.com/hoglandets-it/go-bankgiro/sign"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+	"golang.org/x/text/encoding/unicode"
 )
 
 const (
@@ -74,3 +81,147 @@ func TestAgainstExpected(t *testing.T) {
 
 	}
 }
+
+func TestAuditLog(t *testing.T) {
+	content, err := os.ReadFile("../tests/sealFile/basic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := t.TempDir() + "/audit.log"
+
+	bgf, err := sign.CreateBankgiroFileBytes(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bgf.Source = "basic.txt"
+	bgf.AuditLog = audit.Open(path)
+	bgf.SetSealKey(SignedBy)
+	bgf.SetSealDate(SignedOnDate)
+
+	if err := bgf.Sign(); err != nil {
+		t.Fatal(err)
+	}
+
+	verification, err := audit.Verify(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verification.Valid() || verification.Records != 1 {
+		t.Fatalf("unexpected audit log: %+v", verification)
+	}
+
+	logContent, _ := os.ReadFile(path)
+	for _, expected := range []string{SignedByKvv, bgf.Seal.GetMacBgFormat(), batch.Sha256(content), batch.Sha256([]byte(bgf.GetSignedData())), `"source":"basic.txt"`} {
+		if !bytes.Contains(logContent, []byte(expected)) {
+			t.Errorf("audit log does not contain %s", expected)
+		}
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	isoContent, err := os.ReadFile("../tests/normalization/andringslista-new.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := tools.BytesIsoDecoder(isoContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seal := func(content []byte, encoding charset.Encoding) string {
+		bgf, err := sign.CreateBankgiroFileEncoding(content, encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bgf.SetSealKey(SignedBy)
+		bgf.SetSealDate(SignedOnDate)
+		if err := bgf.Sign(); err != nil {
+			t.Fatal(err)
+		}
+		return bgf.GetSignedData()
+	}
+
+	expected := seal(content, "")
+	if !strings.Contains(expected, "MAKULERING/\xC4NDRING") {
+		t.Errorf("sealed file is not ISO-8859-1: %q", expected)
+	}
+
+	for name, got := range map[string]string{
+		"iso-8859-1":        seal(isoContent, ""),
+		"forced iso-8859-1": seal(isoContent, charset.ISO88591),
+		"lf line endings":   seal(bytes.ReplaceAll(isoContent, []byte("\r\n"), []byte("\n")), ""),
+	} {
+		if got != expected {
+			t.Errorf("%s: sealed file differs from the UTF-8 input\r\n%q\r\n%q", name, got, expected)
+		}
+	}
+
+	_, err = sign.CreateBankgiroFileBytes(append([]byte("01 \x80\r\n"), isoContent...))
+	var unrepresentable *charset.UnrepresentableError
+	if !errors.As(err, &unrepresentable) || unrepresentable.Characters[0].Line != 1 || unrepresentable.Characters[0].Column != 4 {
+		t.Errorf("expected the euro sign to be flagged, got %v", err)
+	}
+}
+
+func TestDateProblems(t *testing.T) {
+	content, err := os.ReadFile("../tests/sealFile/basic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The dates are checked after conversion, so they are found in UTF-16 content as well
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().Bytes(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, opts := range map[string]charset.Options{"iso-8859-1": {}, "utf-16le": {Encoding: charset.UTF16LE}} {
+		input := content
+		if opts.Encoding == charset.UTF16LE {
+			input = utf16
+		}
+
+		bgFile := sign.NewBankgiroFile(opts)
+		bgFile.Write(input)
+		bgFile.SetSealDate(SignedOnDate)
+		if err := bgFile.Prepare(); err != nil {
+			t.Fatal(err)
+		}
+
+		// All payments have passed on the seal date
+		if len(bgFile.DateProblems) != 3 || bgFile.DateProblems[0].Row != 2 || bgFile.DateProblems[0].Date != "20240422" {
+			t.Errorf("%s: DateProblems = %v", name, bgFile.DateProblems)
+		}
+	}
+}
+
+func TestDateProblemsSealDate(t *testing.T) {
+	content, err := os.ReadFile("../tests/sealFile/basic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The payments are dated 20240422, 20240426 and 20240422, the seal date is set after Prepare as serve does
+	tests := []struct {
+		sealDate string
+		problems int
+	}{
+		{"240418", 0},
+		{"240422", 2},
+		{"240429", 3},
+	}
+	for _, tt := range tests {
+		bgFile := sign.NewBankgiroFile(charset.Options{})
+		bgFile.Write(content)
+		if err := bgFile.Prepare(); err != nil {
+			t.Fatal(err)
+		}
+
+		bgFile.SetSealDate(tt.sealDate)
+		if len(bgFile.DateProblems) != tt.problems {
+			t.Errorf("seal date %s: DateProblems = %v, expected %d", tt.sealDate, bgFile.DateProblems, tt.problems)
+		}
+	}
+}
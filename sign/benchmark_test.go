@@ -0,0 +1,84 @@
+package sign_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+)
+
+// The size of the generated file the benchmarks seal
+const benchmarkSize = 100 << 20
+
+var (
+	benchmarkOnce    sync.Once
+	benchmarkContent []byte
+)
+
+// A file of about 100 MB, the opening record of the basic test file followed by its payment rows repeated
+func benchmarkFile(b *testing.B) []byte {
+	benchmarkOnce.Do(func() {
+		content, err := os.ReadFile("../tests/sealFile/basic.txt")
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		lines := bytes.SplitAfter(content, []byte("\n"))
+		rows := bytes.Join(lines[1:], nil)
+		if !bytes.HasSuffix(rows, []byte("\n")) {
+			rows = append(rows, '\r', '\n')
+		}
+
+		benchmarkContent = make([]byte, 0, benchmarkSize+len(content))
+		benchmarkContent = append(benchmarkContent, lines[0]...)
+		for len(benchmarkContent) < benchmarkSize {
+			benchmarkContent = append(benchmarkContent, rows...)
+		}
+	})
+
+	return benchmarkContent
+}
+
+// Sealing through the string API, converting the result to bytes as the callers did
+func BenchmarkSealStrings(b *testing.B) {
+	content := benchmarkFile(b)
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bgf, err := sign.CreateBankgiroFileBytes(content)
+		if err != nil {
+			b.Fatal(err)
+		}
+		sealTestFile(b, &bgf)
+
+		if _, err := io.Discard.Write([]byte(bgf.GetSignedData())); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Sealing through the byte API, reading the content and writing the sealed file without copies
+func BenchmarkSealBytes(b *testing.B) {
+	content := benchmarkFile(b)
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bgf := sign.NewBankgiroFile(charset.Options{})
+		if _, err := bgf.ReadFrom(bytes.NewReader(content)); err != nil {
+			b.Fatal(err)
+		}
+		sealTestFile(b, bgf)
+
+		if _, err := bgf.WriteTo(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
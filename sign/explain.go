@@ -0,0 +1,50 @@
+package sign
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+)
+
+// Explain byte for byte how a Bankgiro file is normalized into the HMAC input
+// A sealed file is explained without its trailer, as when verifying it
+// An unsealed file is explained as it would be sealed on sealDate, default today, with the HMAC header added
+// The content is converted to ISO-8859-1 with the given options, as when sealing, and explained with its line endings as received
+// The profile selects the normalization rules, nil for the default
+func ExplainFile(content []byte, opts charset.Options, sealDate string, profile *seal.NormalizationProfile) (*seal.Explanation, error) {
+	if profile == nil {
+		profile = seal.DefaultProfile
+	}
+
+	result, err := charset.Convert(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsSealed(content) {
+		if _, err := ParseSealedFile(content); err != nil {
+			return nil, err
+		}
+
+		// Everything before the trailer row is sealed, the line break before it is dropped by normalization
+		rows := strings.TrimRight(string(result.Content), "\r\n\t ")
+		end := strings.LastIndexAny(rows, "\r\n")
+		if end < 0 {
+			return nil, fmt.Errorf("no HMAC trailer row found")
+		}
+		if end > 0 && rows[end-1:end+1] == "\r\n" {
+			end--
+		}
+
+		return profile.Explain([]byte(rows[:end])), nil
+	}
+
+	if sealDate == "" {
+		sealDate = time.Now().Format("060102")
+	}
+
+	return profile.Explain(seal.PrefixContent(result.Content, sealDate)), nil
+}
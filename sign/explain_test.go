@@ -0,0 +1,84 @@
+package sign_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+)
+
+func TestExplainFile(t *testing.T) {
+	for _, file := range TEST_FILES {
+		content, err := os.ReadFile("../tests/sealFile/" + file + ".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bgf, err := sign.CreateBankgiroFileBytes(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bgf.SetSealDate(SignedOnDate)
+
+		explanation, err := sign.ExplainFile(content, charset.Options{}, SignedOnDate, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(explanation.Normalized, bgf.Seal.NormalizedData) {
+			t.Errorf("%s: explanation does not match the sealed data", file)
+		}
+
+		signed, err := os.ReadFile("../tests/sealFile/" + file + "-signed.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		explanation, err = sign.ExplainFile(signed, charset.Options{}, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signer, _ := seal.NewSoftwareSigner([]byte(SignedBy))
+		mac, _ := signer.ComputeMAC(explanation.Normalized)
+		sealed, err := sign.ParseSealedFile(signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.ToUpper(hex.EncodeToString(mac))[:32] != sealed.Mac {
+			t.Errorf("%s: explanation of the sealed file does not match the seal", file)
+		}
+	}
+}
+
+func TestExplainFileLineEndings(t *testing.T) {
+	content, err := os.ReadFile("../tests/sealFile/basic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crlf, err := sign.ExplainFile(content, charset.Options{}, SignedOnDate, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lone line feeds are shown as received and dropped like CRLF
+	lf := bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	explanation, err := sign.ExplainFile(lf, charset.Options{}, SignedOnDate, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(explanation.Normalized, crlf.Normalized) {
+		t.Error("explanation of LF content does not match the CRLF content")
+	}
+	if input := explanation.Lines[1].Input; !bytes.HasSuffix(input, []byte("\n")) || bytes.HasSuffix(input, []byte("\r\n")) {
+		t.Errorf("line 2 input = %q, expected the LF as received", input)
+	}
+	if !explanation.Lines[1].Changed() || crlf.Lines[1].Changed() {
+		t.Error("expected only the line ending with a lone LF to be changed")
+	}
+}
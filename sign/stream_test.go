@@ -0,0 +1,114 @@
+package sign_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+)
+
+// The byte API gives the same sealed file as the string API
+func TestStreamMatchesLegacy(t *testing.T) {
+	for _, file := range TEST_FILES {
+		t.Run(file, func(t *testing.T) {
+			content, err := os.ReadFile("../tests/sealFile/" + file + ".txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			legacy, err := sign.CreateBankgiroFileBytes(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sealTestFile(t, &legacy)
+			expected := legacy.GetSignedData()
+
+			f, err := os.Open("../tests/sealFile/" + file + ".txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			bgf := sign.NewBankgiroFile(charset.Options{})
+			if _, err := bgf.ReadFrom(f); err != nil {
+				t.Fatal(err)
+			}
+			sealTestFile(t, bgf)
+
+			var written bytes.Buffer
+			n, err := bgf.WriteTo(&written)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != int64(written.Len()) || written.String() != expected {
+				t.Errorf("WriteTo() = %q, expected %q", written.String(), expected)
+			}
+
+			read, err := io.ReadAll(bgf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(read) != expected {
+				t.Errorf("Read() = %q, expected %q", read, expected)
+			}
+
+			if string(bgf.Bytes()) != expected {
+				t.Errorf("Bytes() = %q, expected %q", bgf.Bytes(), expected)
+			}
+
+			if _, err := bgf.Write([]byte("more")); err == nil {
+				t.Error("expected an error writing to a sealed file")
+			}
+		})
+	}
+}
+
+// Content written in pieces is sealed as a whole
+func TestStreamWrite(t *testing.T) {
+	content, err := os.ReadFile("../tests/sealFile/basic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	whole := sign.NewBankgiroFile(charset.Options{})
+	whole.Write(content)
+	sealTestFile(t, whole)
+
+	pieces := sign.NewBankgiroFile(charset.Options{})
+	for i := 0; i < len(content); i += 7 {
+		pieces.Write(content[i:min(i+7, len(content))])
+	}
+	sealTestFile(t, pieces)
+
+	if !bytes.Equal(whole.Bytes(), pieces.Bytes()) {
+		t.Errorf("Bytes() = %q, expected %q", pieces.Bytes(), whole.Bytes())
+	}
+
+	customerNumber, bankgiro, err := pieces.OpeningNumbers()
+	if err != nil || customerNumber != "006924" || bankgiro != "0009925256" {
+		t.Errorf("OpeningNumbers() = %s, %s, %v", customerNumber, bankgiro, err)
+	}
+
+	if _, err := sign.NewBankgiroFile(charset.Options{}).WriteTo(io.Discard); err == nil {
+		t.Error("expected an error writing an unsealed file")
+	}
+}
+
+func sealTestFile(t testing.TB, bgf *sign.BankgiroFile) {
+	t.Helper()
+
+	if err := bgf.SetSealKey(SignedBy); err != nil {
+		t.Fatal(err)
+	}
+	if err := bgf.CheckKvv(SignedByKvv); err != nil {
+		t.Fatal(err)
+	}
+	bgf.SetSealDate(SignedOnDate)
+
+	if err := bgf.Sign(); err != nil {
+		t.Fatal(err)
+	}
+}
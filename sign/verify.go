@@ -0,0 +1,199 @@
+package sign
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/parse"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"github.com/hoglandets-it/go-bankgiro/tools"
+)
+
+// A sealed Bankgiro file split into its HMAC header, content and trailer
+// Header: the 00 row, carrying the seal date
+// Content: the rows between the header and trailer
+// Trailer: the 99 row, carrying the trailer date, KVV and MAC
+type SealedFile struct {
+	Header      string
+	Content     string
+	Trailer     string
+	SealDate    string
+	TrailerDate string
+	Kvv         string
+	Mac         string
+}
+
+// The result of verifying a sealed file against a key
+type Verification struct {
+	Valid       bool   `json:"valid"`
+	KvvMatch    bool   `json:"kvvMatch"`
+	MacMatch    bool   `json:"macMatch"`
+	SealDate    string `json:"sealDate"`
+	Kvv         string `json:"kvv"`
+	ExpectedKvv string `json:"expectedKvv"`
+	Mac         string `json:"mac"`
+	ExpectedMac string `json:"expectedMac"`
+}
+
+// Split a sealed file into its header, content and trailer
+func ParseSealedFile(content []byte) (*SealedFile, error) {
+	isoString, err := tools.BytesToIsoString(content)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := strings.Split(seal.FormatContentString(isoString), "\r\n")
+	if len(rows) < 3 {
+		return nil, fmt.Errorf("sealed file must have a header, content and trailer, got %d rows", len(rows))
+	}
+
+	header := rows[0]
+	trailer := strings.TrimRight(rows[len(rows)-1], " \t")
+
+	if !strings.HasPrefix(header, parse.HMAC_HEADER) || len(header) < 12 || header[8:12] != "HMAC" {
+		return nil, fmt.Errorf("no HMAC header row found: %s", header)
+	}
+
+	if !strings.HasPrefix(trailer, parse.HMAC_FILE_SEAL) || len(trailer) < 72 {
+		return nil, fmt.Errorf("no HMAC trailer row found: %s", trailer)
+	}
+
+	return &SealedFile{
+		Header:      header,
+		Content:     strings.Join(rows[1:len(rows)-1], "\r\n"),
+		Trailer:     trailer,
+		SealDate:    header[2:8],
+		TrailerDate: trailer[2:8],
+		Kvv:         trailer[8:40],
+		Mac:         trailer[40:72],
+	}, nil
+}
+
+// Recalculate the seal of a sealed file with the signer and compare it to the trailer
+func VerifySealedFile(content []byte, signer seal.Signer) (*Verification, error) {
+	return VerifySealedFileWithProfile(content, signer, nil)
+}
+
+// Recalculate the seal of a sealed file with the signer and normalization profile, nil for the default
+func VerifySealedFileWithProfile(content []byte, signer seal.Signer, profile *seal.NormalizationProfile) (*Verification, error) {
+	sealed, err := ParseSealedFile(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifySealed(sealed, signer, profile)
+}
+
+// Recalculate the seal of a parsed sealed file and compare it to the trailer
+func verifySealed(sealed *SealedFile, signer seal.Signer, profile *seal.NormalizationProfile) (*Verification, error) {
+	sealer := seal.HmacSealer{Profile: profile}
+	if err := sealer.SetSigner(signer); err != nil {
+		return nil, err
+	}
+
+	// The header is part of the sealed data, PrefixContent keeps it as is
+	if err := sealer.SetData(sealed.Header + "\r\n" + sealed.Content); err != nil {
+		return nil, err
+	}
+
+	if err := sealer.Calculate(); err != nil {
+		return nil, err
+	}
+
+	verification := &Verification{
+		SealDate:    sealed.SealDate,
+		Kvv:         sealed.Kvv,
+		ExpectedKvv: sealer.GetKvvBgFormat(),
+		Mac:         sealed.Mac,
+		ExpectedMac: sealer.GetMacBgFormat(),
+	}
+	verification.KvvMatch = strings.EqualFold(verification.Kvv, verification.ExpectedKvv)
+	verification.MacMatch = strings.EqualFold(verification.Mac, verification.ExpectedMac)
+	verification.Valid = verification.KvvMatch && verification.MacMatch
+
+	return verification, nil
+}
+
+// The error returned when a sealed file does not verify against the key
+type SealMismatchError struct {
+	Verification *Verification
+}
+
+func (e *SealMismatchError) Error() string {
+	if !e.Verification.KvvMatch {
+		return fmt.Sprintf("file was sealed with another key: the trailer has KVV %s, the key has KVV %s", e.Verification.Kvv, e.Verification.ExpectedKvv)
+	}
+
+	return fmt.Sprintf("seal does not match the content: the trailer has MAC %s, expected %s", e.Verification.Mac, e.Verification.ExpectedMac)
+}
+
+// Verify the seal of a sealed file with the signer and normalization profile, nil for the default
+// Returns the content without the HMAC header and trailer rows, as ISO-8859-1 with CRLF line endings
+// A seal that does not verify is returned as a *SealMismatchError
+func Unseal(content []byte, verifier seal.Signer, profile *seal.NormalizationProfile) ([]byte, *Verification, error) {
+	sealed, err := ParseSealedFile(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verification, err := verifySealed(sealed, verifier, profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !verification.Valid {
+		return nil, verification, &SealMismatchError{Verification: verification}
+	}
+
+	return []byte(sealed.Content + "\r\n"), verification, nil
+}
+
+// Verify the seal of a sealed file and get its content as a new file to be sealed again, e.g. with a new key when rotating keys
+// Set the new key, and the seal date unless sealing today, then call Sign
+// The normalization profile is used to verify the old seal and to calculate the new one
+func Reseal(content []byte, verifier seal.Signer, profile *seal.NormalizationProfile) (*BankgiroFile, *Verification, error) {
+	unsealed, verification, err := Unseal(content, verifier, profile)
+	if err != nil {
+		return nil, verification, err
+	}
+
+	bgFile := NewBankgiroFile(charset.Options{Encoding: charset.ISO88591})
+	bgFile.Write(unsealed)
+
+	if err := bgFile.SetNormalizationProfile(profile); err != nil {
+		return nil, verification, err
+	}
+
+	return bgFile, verification, nil
+}
+
+// Check that the content is an unsealed Bankgiro file that can be sealed
+// Payment dates are warnings rather than errors, Prepare checks them into BankgiroFile.DateProblems
+func Validate(content []byte) error {
+	if len(strings.TrimSpace(string(content))) == 0 {
+		return fmt.Errorf("file is empty")
+	}
+
+	if IsSealed(content) {
+		return fmt.Errorf("file is already sealed")
+	}
+
+	isoContent, err := tools.BytesToIsoString(content)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := parse.OpeningNumbers(isoContent); err != nil {
+		return fmt.Errorf("invalid opening record: %w", err)
+	}
+
+	// Trailing spaces and tabs are not counted
+	for i, row := range strings.Split(tools.EnsureCrlfString(isoContent), "\r\n") {
+		if length := len(strings.TrimRight(row, " \t")); length > 80 {
+			return fmt.Errorf("row %d is %d characters long, the maximum is 80", i+1, length)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package sign_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/seal"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+)
+
+func TestVerifySealedFile(t *testing.T) {
+	signer, err := seal.NewSoftwareSigner([]byte(SignedBy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range TEST_FILES {
+		signed, err := os.ReadFile("../tests/sealFile/" + file + "-signed.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		verification, err := sign.VerifySealedFile(signed, signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !verification.Valid || verification.SealDate != SignedOnDate {
+			t.Errorf("%s: verification failed: %+v", file, verification)
+		}
+
+		tampered := bytes.Replace(signed, []byte("0000000"), []byte("0000001"), 1)
+		verification, err = sign.VerifySealedFile(tampered, signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verification.Valid || verification.MacMatch || !verification.KvvMatch {
+			t.Errorf("%s: tampered file verified: %+v", file, verification)
+		}
+	}
+
+	otherSigner, _ := seal.NewSoftwareSigner([]byte("00112233445566778899AABBCCDDEEFF"))
+	signed, _ := os.ReadFile("../tests/sealFile/basic-signed.txt")
+	verification, err := sign.VerifySealedFile(signed, otherSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verification.Valid || verification.KvvMatch {
+		t.Errorf("file verified with the wrong key: %+v", verification)
+	}
+
+	unsigned, _ := os.ReadFile("../tests/sealFile/basic.txt")
+	if _, err := sign.VerifySealedFile(unsigned, signer); err == nil {
+		t.Error("expected error when verifying an unsealed file")
+	}
+}
+
+func TestUnseal(t *testing.T) {
+	signer, err := seal.NewSoftwareSigner([]byte(SignedBy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range TEST_FILES {
+		signed, err := os.ReadFile("../tests/sealFile/" + file + "-signed.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		unsealed, verification, err := sign.Unseal(signed, signer, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !verification.Valid || bytes.HasPrefix(unsealed, []byte("00")) || bytes.Contains(unsealed, []byte("\r\n99")) {
+			t.Errorf("%s: Unseal() = %q, %+v", file, unsealed, verification)
+		}
+
+		// Sealing the unsealed content with the same key and date gives the sealed file back
+		bgf := sign.NewBankgiroFile(charset.Options{})
+		bgf.Write(unsealed)
+		sealTestFile(t, bgf)
+		if string(bgf.Bytes()) != string(signed) {
+			t.Errorf("%s: sealed again = %q, expected %q", file, bgf.Bytes(), signed)
+		}
+	}
+
+	signed, _ := os.ReadFile("../tests/sealFile/basic-signed.txt")
+	otherSigner, _ := seal.NewSoftwareSigner([]byte("00112233445566778899AABBCCDDEEFF"))
+	tests := []struct {
+		name     string
+		content  []byte
+		signer   seal.Signer
+		kvvMatch bool
+	}{
+		{"Tampered", bytes.Replace(signed, []byte("0000000"), []byte("0000001"), 1), signer, true},
+		{"Other Key", signed, otherSigner, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := sign.Unseal(tt.content, tt.signer, nil)
+			var mismatch *sign.SealMismatchError
+			if !errors.As(err, &mismatch) || mismatch.Verification.KvvMatch != tt.kvvMatch {
+				t.Errorf("Unseal() error = %v, expected a seal mismatch", err)
+			}
+		})
+	}
+}
+
+func TestReseal(t *testing.T) {
+	oldSigner, _ := seal.NewSoftwareSigner([]byte(SignedBy))
+	newSigner, _ := seal.NewSoftwareSigner([]byte("00112233445566778899AABBCCDDEEFF"))
+
+	signed, err := os.ReadFile("../tests/sealFile/blank-rows-signed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sign.CreateBankgiroFileBytes(signed); err == nil {
+		t.Error("expected an error sealing a sealed file")
+	}
+
+	bgf, verification, err := sign.Reseal(signed, oldSigner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verification.Kvv != SignedByKvv {
+		t.Errorf("Reseal() verified with KVV %s", verification.Kvv)
+	}
+
+	bgf.SetSealDate("250101")
+	if err := bgf.SetSigner(newSigner); err != nil {
+		t.Fatal(err)
+	}
+	if err := bgf.Sign(); err != nil {
+		t.Fatal(err)
+	}
+
+	resealed := bgf.Bytes()
+	if bytes.Count(resealed, []byte("\r\n99")) != 1 || !bytes.HasPrefix(resealed, []byte("00250101HMAC")) {
+		t.Errorf("Reseal() = %q", resealed)
+	}
+
+	verification, err = sign.VerifySealedFile(resealed, newSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verification.Valid || verification.SealDate != "250101" || !bytes.Contains(resealed, []byte("\r\n99250101")) {
+		t.Errorf("resealed file did not verify with the new key: %+v", verification)
+	}
+
+	if _, _, err := sign.Reseal(resealed, oldSigner, nil); err == nil {
+		t.Error("expected an error resealing with the wrong old key")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{"Unsealed", "basic.txt", false},
+		{"Blank Rows", "blank-rows.txt", false},
+		{"Already Sealed", "basic-signed.txt", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := os.ReadFile("../tests/sealFile/" + tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sign.Validate(content); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := sign.Validate([]byte("not a bankgiro file\r\n")); err == nil {
+		t.Error("expected error for a file without opening record")
+	}
+
+	// The reported length does not include the trailing spaces
+	long := "0120240416AUTOGIRO" + strings.Repeat(" ", 44) + "0069240009912346    \r\n82" + strings.Repeat("0", 79) + strings.Repeat(" ", 5) + "\r\n"
+	if err := sign.Validate([]byte(long)); err == nil || !strings.Contains(err.Error(), "row 2 is 81 characters long") {
+		t.Errorf("Validate() error = %v, expected row 2 to be reported as 81 characters", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"github.com/hoglandets-it/go-bankgiro/charset"
 	"golang.org/x/text/encoding/charmap"
 )
 
@@ -140,20 +141,18 @@ func EnsureCrlfString(s string) string {
 	return strings.ReplaceAll(onlyN, "\n", "\r\n")
 }
 
+// Convert the content to an ISO-8859-1 string with CRLF line endings, detecting its encoding
 func BytesToIsoString(input []byte) (string, error) {
-	if err := IdentifyInvalidUtf8(string(input)); err != nil {
-		decoded, err := AutomaticDecoder(input, charmap.ISO8859_1, charmap.Windows1252)
-		if err != nil {
-			return string(input), nil
-		}
-
-		return decoded, nil
-	}
+	return BytesToIsoStringEncoding(input, "")
+}
 
-	bodyString, err := charmap.ISO8859_1.NewEncoder().String(string(input))
+// Convert the content to an ISO-8859-1 string with CRLF line endings, reading it as the given encoding
+// The encoding is detected when empty, see charset.Detect
+func BytesToIsoStringEncoding(input []byte, encoding charset.Encoding) (string, error) {
+	result, err := charset.ToIso(input, encoding)
 	if err != nil {
-		return "", fmt.Errorf("error transforming bytes: %w", err)
+		return "", err
 	}
 
-	return EnsureCrlfString(bodyString), nil
+	return EnsureCrlfString(string(result.Content)), nil
 }
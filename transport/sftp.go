@@ -0,0 +1,357 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/batch"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+type Status string
+
+const (
+	StatusSent    Status = "sent"
+	StatusFetched Status = "fetched"
+	StatusSkipped Status = "skipped"
+	StatusFailed  Status = "failed"
+)
+
+// Host/Port/User: the SFTP server and account
+// IdentityFile: private key to authenticate with, Password is used when not set
+// KnownHostsFile: known_hosts file to verify the host key with
+// HostKeyFingerprint: SHA256 fingerprint of the host key, e.g. SHA256:..., used instead of KnownHostsFile
+// RemoteOutbox: remote directory sealed files are uploaded to
+// RemoteInbox: remote directory return files are downloaded from
+type Config struct {
+	Host               string
+	Port               int
+	User               string
+	IdentityFile       string
+	Passphrase         string
+	Password           string
+	KnownHostsFile     string
+	HostKeyFingerprint string
+	RemoteOutbox       string
+	RemoteInbox        string
+	Timeout            time.Duration
+}
+
+// The result of transferring a single file
+type Result struct {
+	Local  string `json:"local"`
+	Remote string `json:"remote"`
+	Status Status `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// An SFTP connection to the Bankgiro file exchange
+type Client struct {
+	Config Config
+	ssh    *ssh.Client
+	sftp   *sftp.Client
+}
+
+// Connect and authenticate to the SFTP server, the host key is always verified
+func Dial(config Config) (*Client, error) {
+	hostKeyCallback, err := config.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := config.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Port == 0 {
+		config.Port = 22
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	sshClient, err := ssh.Dial("tcp", net.JoinHostPort(config.Host, strconv.Itoa(config.Port)), &ssh.ClientConfig{
+		User:            config.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         config.Timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s: %w", config.Host, err)
+	}
+
+	sftpClient, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, fmt.Errorf("could not start sftp session: %w", err)
+	}
+
+	return &Client{Config: config, ssh: sshClient, sftp: sftpClient}, nil
+}
+
+func (config Config) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if config.HostKeyFingerprint != "" {
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if fingerprint := ssh.FingerprintSHA256(key); fingerprint != config.HostKeyFingerprint {
+				return fmt.Errorf("host key fingerprint %s does not match %s", fingerprint, config.HostKeyFingerprint)
+			}
+			return nil
+		}, nil
+	}
+
+	if config.KnownHostsFile == "" {
+		return nil, fmt.Errorf("a known hosts file or host key fingerprint is required")
+	}
+
+	return knownhosts.New(config.KnownHostsFile)
+}
+
+func (config Config) auth() ([]ssh.AuthMethod, error) {
+	if config.IdentityFile == "" {
+		if config.Password == "" {
+			return nil, fmt.Errorf("an identity file or password is required")
+		}
+		return []ssh.AuthMethod{ssh.Password(config.Password)}, nil
+	}
+
+	pemBytes, err := os.ReadFile(config.IdentityFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var signer ssh.Signer
+	if config.Passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(pemBytes, []byte(config.Passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(pemBytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read identity file: %w", err)
+	}
+
+	return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil
+}
+
+// Upload sealed files to the remote outbox
+// Files already sent with the same content are skipped unless force is set, unsealed files are refused
+// Files are uploaded under a temporary name and renamed once complete
+func (c *Client) Send(state *State, files []string, force bool) []Result {
+	results := []Result{}
+
+	for _, local := range files {
+		remote := path.Join(c.Config.RemoteOutbox, filepath.Base(local))
+		result := Result{Local: local, Remote: remote, Status: StatusSent}
+
+		sent, err := c.send(state, local, remote, force)
+		if err != nil {
+			result.Status = StatusFailed
+			result.Reason = err.Error()
+		} else if !sent {
+			result.Status = StatusSkipped
+			result.Reason = "already sent"
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func (c *Client) send(state *State, local string, remote string, force bool) (bool, error) {
+	content, err := os.ReadFile(local)
+	if err != nil {
+		return false, err
+	}
+
+	if !sign.IsSealed(content) {
+		return false, fmt.Errorf("file is not sealed")
+	}
+
+	sha := batch.Sha256(content)
+	if _, found := state.IsSent(sha); found && !force {
+		return false, nil
+	}
+
+	if _, err := c.sftp.Stat(remote); err == nil && !force {
+		return false, fmt.Errorf("%s already exists on the server", remote)
+	}
+
+	tmpRemote := path.Join(path.Dir(remote), "."+path.Base(remote)+"."+randomSuffix()+".part")
+	if err := c.upload(tmpRemote, content); err != nil {
+		c.sftp.Remove(tmpRemote)
+		return false, err
+	}
+
+	if force {
+		c.sftp.Remove(remote)
+	}
+
+	if err := c.sftp.Rename(tmpRemote, remote); err != nil {
+		c.sftp.Remove(tmpRemote)
+		return false, err
+	}
+
+	state.markSent(SentFile{Local: local, Remote: remote, Sha256: sha, Size: int64(len(content)), Sent: time.Now()})
+
+	return true, nil
+}
+
+func (c *Client) upload(remote string, content []byte) error {
+	file, err := c.sftp.OpenFile(remote, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, bytes.NewReader(content)); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Download new files matching the patterns from the remote inbox to a local directory
+// Files already fetched are skipped, the remote files are removed after download when remove is set
+func (c *Client) Fetch(state *State, localDir string, patterns []string, remove bool) ([]Result, error) {
+	entries, err := c.sftp.ReadDir(c.Config.RemoteInbox)
+	if err != nil {
+		return nil, fmt.Errorf("could not list %s: %w", c.Config.RemoteInbox, err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	results := []Result{}
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() || entry.Name()[0] == '.' {
+			continue
+		}
+
+		if matches, err := batch.MatchesPatterns(entry.Name(), patterns); err != nil || !matches {
+			if err != nil {
+				return results, err
+			}
+			continue
+		}
+
+		remote := path.Join(c.Config.RemoteInbox, entry.Name())
+		result := Result{Remote: remote, Status: StatusFetched}
+
+		if state.IsFetched(remote, entry.Size(), entry.ModTime()) {
+			result.Status = StatusSkipped
+			result.Reason = "already fetched"
+			results = append(results, result)
+			continue
+		}
+
+		local, err := c.fetch(state, remote, entry, localDir)
+		result.Local = local
+		if err != nil {
+			result.Status = StatusFailed
+			result.Reason = err.Error()
+		} else if remove {
+			if err := c.sftp.Remove(remote); err != nil {
+				result.Reason = fmt.Sprintf("could not remove remote file: %v", err)
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (c *Client) fetch(state *State, remote string, entry fs.FileInfo, localDir string) (string, error) {
+	file, err := c.sftp.Open(remote)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	local, err := writeUnique(localDir, entry.Name(), content)
+	if err != nil {
+		return "", err
+	}
+
+	state.markFetched(FetchedFile{
+		Remote:  remote,
+		Local:   local,
+		Sha256:  batch.Sha256(content),
+		Size:    entry.Size(),
+		ModTime: entry.ModTime(),
+		Fetched: time.Now(),
+	})
+
+	return local, nil
+}
+
+// Write the file atomically to the directory, adding a timestamp if the name is taken
+func writeUnique(dir string, name string, content []byte) (string, error) {
+	tmp, err := os.CreateTemp(dir, "."+name+".*.part")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	local := uniquePath(filepath.Join(dir, name))
+
+	return local, os.Rename(tmp.Name(), local)
+}
+
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	stamp := time.Now().Format("20060102T150405")
+
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s.%s-%d%s", base, stamp, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (c *Client) Close() error {
+	c.sftp.Close()
+	return c.ssh.Close()
+}
@@ -0,0 +1,187 @@
+package transport_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/hoglandets-it/go-bankgiro/batch"
+	"github.com/hoglandets-it/go-bankgiro/transport"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// Start an in-process SFTP server accepting the returned client key, serving the local file system
+func startServer(t *testing.T) (config transport.Config) {
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientPub, clientPriv, _ := ed25519.GenerateKey(rand.Reader)
+	authorized, _ := ssh.NewPublicKey(clientPub)
+
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), authorized.Marshal()) {
+				return nil, os.ErrPermission
+			}
+			return nil, nil
+		},
+	}
+	serverConfig.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, serverConfig)
+		}
+	}()
+
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(identity, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	return transport.Config{
+		Host:               host,
+		Port:               portNumber,
+		User:               "bankgiro",
+		IdentityFile:       identity,
+		HostKeyFingerprint: ssh.FingerprintSHA256(hostSigner.PublicKey()),
+		RemoteOutbox:       t.TempDir(),
+		RemoteInbox:        t.TempDir(),
+	}
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			for req := range requests {
+				req.Reply(req.Type == "subsystem" && string(req.Payload[4:]) == "sftp", nil)
+				if req.Type == "subsystem" {
+					server, err := sftp.NewServer(channel)
+					if err == nil {
+						server.Serve()
+					}
+					channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+func TestSendAndFetch(t *testing.T) {
+	config := startServer(t)
+
+	client, err := transport.Dial(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	state, err := transport.LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := []string{"../tests/sealFile/basic-signed.txt", "../tests/sealFile/basic.txt"}
+	results := client.Send(state, files, false)
+	if results[0].Status != transport.StatusSent || results[1].Status != transport.StatusFailed {
+		t.Fatalf("unexpected send results: %+v", results)
+	}
+
+	sent, err := os.ReadFile(filepath.Join(config.RemoteOutbox, "basic-signed.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := os.ReadFile(files[0])
+	if !bytes.Equal(sent, expected) {
+		t.Error("uploaded file does not match")
+	}
+
+	results = client.Send(state, files[:1], false)
+	if results[0].Status != transport.StatusSkipped {
+		t.Errorf("expected file to be skipped when sent again: %+v", results)
+	}
+
+	os.WriteFile(filepath.Join(config.RemoteInbox, "return.txt"), []byte("return file"), 0644)
+	os.WriteFile(filepath.Join(config.RemoteInbox, "other.dat"), []byte("other"), 0644)
+
+	inbox := t.TempDir()
+	results, err = client.Fetch(state, inbox, []string{"*.txt"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != transport.StatusFetched {
+		t.Fatalf("unexpected fetch results: %+v", results)
+	}
+	if content, _ := os.ReadFile(filepath.Join(inbox, "return.txt")); string(content) != "return file" {
+		t.Errorf("fetched file does not match: %q", content)
+	}
+
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+	state, err = transport.LoadState(state.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, _ = client.Fetch(state, inbox, []string{"*.txt"}, true)
+	if len(results) != 1 || results[0].Status != transport.StatusSkipped {
+		t.Errorf("expected fetched file to be skipped: %+v", results)
+	}
+
+	if _, found := state.IsSent(batch.Sha256(expected)); !found || len(state.Sent) != 1 {
+		t.Errorf("sent file not kept in state: %+v", state.Sent)
+	}
+}
+
+func TestHostKeyMismatch(t *testing.T) {
+	config := startServer(t)
+	config.HostKeyFingerprint = "SHA256:invalid"
+
+	if _, err := transport.Dial(config); err == nil {
+		t.Error("expected error when the host key does not match")
+	}
+
+	config.HostKeyFingerprint = ""
+	if _, err := transport.Dial(config); err == nil {
+		t.Error("expected error without host key verification")
+	}
+}
@@ -0,0 +1,125 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// A file uploaded to the remote outbox
+type SentFile struct {
+	Local  string    `json:"local"`
+	Remote string    `json:"remote"`
+	Sha256 string    `json:"sha256"`
+	Size   int64     `json:"size"`
+	Sent   time.Time `json:"sent"`
+}
+
+// A file downloaded from the remote inbox
+// Size and ModTime identify the remote file, a file replaced on the server is fetched again
+type FetchedFile struct {
+	Remote  string    `json:"remote"`
+	Local   string    `json:"local"`
+	Sha256  string    `json:"sha256"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// The files sent and fetched, keyed by the SHA-256 of sent files and the remote path of fetched files
+type State struct {
+	Path    string                 `json:"-"`
+	Sent    map[string]SentFile    `json:"sent"`
+	Fetched map[string]FetchedFile `json:"fetched"`
+
+	mu sync.Mutex
+}
+
+// Load the state file, a missing file is an empty state
+func LoadState(path string) (*State, error) {
+	state := &State{Path: path}
+
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(content, state); err != nil {
+			return nil, err
+		}
+	}
+
+	if state.Sent == nil {
+		state.Sent = map[string]SentFile{}
+	}
+	if state.Fetched == nil {
+		state.Fetched = map[string]FetchedFile{}
+	}
+
+	return state, nil
+}
+
+// Save the state file atomically
+func (s *State) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// Check if a file with the given content has been sent
+func (s *State) IsSent(sha256 string) (SentFile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent, found := s.Sent[sha256]
+	return sent, found
+}
+
+// Check if the remote file has been fetched, a changed size or modification time is a new file
+func (s *State) IsFetched(remote string, size int64, modTime time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fetched, found := s.Fetched[remote]
+	return found && fetched.Size == size && fetched.ModTime.Equal(modTime)
+}
+
+func (s *State) markSent(sent SentFile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Sent[sent.Sha256] = sent
+}
+
+func (s *State) markFetched(fetched FetchedFile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Fetched[fetched.Remote] = fetched
+}
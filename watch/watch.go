@@ -0,0 +1,283 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hoglandets-it/go-bankgiro/batch"
+	"github.com/hoglandets-it/go-bankgiro/charset"
+	"github.com/hoglandets-it/go-bankgiro/sign"
+)
+
+// The suffix of the explanation file written next to a failed file
+const ErrorSuffix = ".err"
+
+// Inbox: directory the ERP drops files into
+// Outbox: directory sealed files are written to
+// Archive: directory original files are moved to once sealed
+// ErrorDir: directory failed files are moved to, with a .err file explaining the failure
+// Patterns: glob patterns matched against the file names, all files are processed when empty
+// SettleTime: how long a file must remain unchanged before it is considered fully written
+type Options struct {
+	Inbox      string
+	Outbox     string
+	Archive    string
+	ErrorDir   string
+	Patterns   []string
+	SettleTime time.Duration
+}
+
+// Watches the inbox and seals files as they arrive
+type Watcher struct {
+	Options  Options
+	SealFunc batch.SealFunc
+	Logger   *log.Logger
+	pending  map[string]*pendingFile
+}
+
+// A file in the inbox waiting to be fully written
+type pendingFile struct {
+	size        int64
+	modTime     time.Time
+	stableSince time.Time
+}
+
+// Create a watcher, the directories are created if they do not exist
+func New(opts Options, sealFunc batch.SealFunc) (*Watcher, error) {
+	for _, dir := range []string{opts.Inbox, opts.Outbox, opts.Archive, opts.ErrorDir} {
+		if dir == "" {
+			return nil, fmt.Errorf("inbox, outbox, archive and error directories are required")
+		}
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.SettleTime <= 0 {
+		opts.SettleTime = 2 * time.Second
+	}
+
+	return &Watcher{
+		Options:  opts,
+		SealFunc: sealFunc,
+		Logger:   log.New(os.Stdout, "", log.LstdFlags),
+		pending:  map[string]*pendingFile{},
+	}, nil
+}
+
+// Watch the inbox until the context is cancelled
+// Files already present in the inbox are processed on start
+func (w *Watcher) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(w.Options.Inbox); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(w.Options.Inbox)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		w.track(filepath.Join(w.Options.Inbox, entry.Name()))
+	}
+
+	w.Logger.Printf("watching %s", w.Options.Inbox)
+
+	ticker := time.NewTicker(w.Options.SettleTime / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.Logger.Printf("stopped watching %s", w.Options.Inbox)
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) || event.Has(fsnotify.Write) {
+				w.track(event.Name)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.Logger.Printf("watch error: %v", err)
+		case now := <-ticker.C:
+			w.processSettled(now)
+		}
+	}
+}
+
+// Start tracking a file in the inbox, or restart its settle time if it changed
+func (w *Watcher) track(path string) {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") {
+		return
+	}
+
+	matched, err := batch.MatchesPatterns(name, w.Options.Patterns)
+	if err != nil || !matched {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return
+	}
+
+	w.pending[path] = &pendingFile{size: info.Size(), modTime: info.ModTime(), stableSince: time.Now()}
+}
+
+// Process the files that have not changed for the settle time
+func (w *Watcher) processSettled(now time.Time) {
+	for path, pending := range w.pending {
+		info, err := os.Stat(path)
+		if err != nil {
+			delete(w.pending, path)
+			continue
+		}
+
+		if info.Size() != pending.size || !info.ModTime().Equal(pending.modTime) {
+			pending.size = info.Size()
+			pending.modTime = info.ModTime()
+			pending.stableSince = now
+			continue
+		}
+
+		if now.Sub(pending.stableSince) < w.Options.SettleTime {
+			continue
+		}
+
+		delete(w.pending, path)
+		w.ProcessFile(path)
+	}
+}
+
+// Validate, seal and move a single file from the inbox
+// Failed files are moved to the error directory with a .err file explaining the failure
+func (w *Watcher) ProcessFile(path string) error {
+	name := filepath.Base(path)
+
+	output, err := w.sealFile(path)
+	if err != nil {
+		w.Logger.Printf("failed %s: %v", name, err)
+		if moveErr := w.moveToErrors(path, err); moveErr != nil {
+			w.Logger.Printf("could not move %s to %s: %v", name, w.Options.ErrorDir, moveErr)
+		}
+		return err
+	}
+
+	archived, err := moveUnique(path, w.Options.Archive)
+	if err != nil {
+		w.Logger.Printf("sealed %s but could not archive the original: %v", name, err)
+		return err
+	}
+
+	w.Logger.Printf("sealed %s -> %s, original archived to %s", name, output, archived)
+
+	return nil
+}
+
+// Seal a file into the outbox, the sealed file is renamed into place once fully written
+func (w *Watcher) sealFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	if err := sign.Validate(content); err != nil {
+		return "", err
+	}
+
+	bgFile := sign.NewBankgiroFile(charset.Options{})
+	bgFile.Write(content)
+	if err := bgFile.Prepare(); err != nil {
+		return "", err
+	}
+	bgFile.Source = path
+
+	if err := w.SealFunc(bgFile); err != nil {
+		return "", err
+	}
+
+	// Checked against the seal date, which the seal function may set
+	for _, problem := range bgFile.DateProblems {
+		w.Logger.Printf("warning: %s: %s", filepath.Base(path), problem)
+	}
+
+	output := uniquePath(filepath.Join(w.Options.Outbox, filepath.Base(path)))
+
+	tmp, err := os.CreateTemp(w.Options.Outbox, ".sealing-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := bgFile.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	return output, os.Rename(tmp.Name(), output)
+}
+
+// Move a failed file to the error directory and explain the failure in a .err file
+func (w *Watcher) moveToErrors(path string, cause error) error {
+	moved, err := moveUnique(path, w.Options.ErrorDir)
+	if err != nil {
+		return err
+	}
+
+	explanation := fmt.Sprintf(
+		"file:   %s\r\ntime:   %s\r\nerror:  %s\r\n",
+		filepath.Base(path),
+		time.Now().Format(time.RFC3339),
+		strings.ReplaceAll(cause.Error(), "\r\n", "\r\n        "),
+	)
+
+	return os.WriteFile(moved+ErrorSuffix, []byte(explanation), 0644)
+}
+
+// Move a file into a directory, adding a timestamp to the name if it already exists there
+func moveUnique(path string, dir string) (string, error) {
+	target := uniquePath(filepath.Join(dir, filepath.Base(path)))
+
+	return target, os.Rename(path, target)
+}
+
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	stamp := time.Now().Format("20060102T150405")
+
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s.%s-%d%s", base, stamp, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
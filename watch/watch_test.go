@@ -0,0 +1,115 @@
+package watch_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoglandets-it/go-bankgiro/sign"
+	"github.com/hoglandets-it/go-bankgiro/watch"
+)
+
+const SignedBy = "1234567890ABCDEF1234567890ABCDEF"
+
+func testSealFunc(bgFile *sign.BankgiroFile) error {
+	if err := bgFile.SetSealKey(SignedBy); err != nil {
+		return err
+	}
+
+	return bgFile.Sign()
+}
+
+// Wait for a file to appear, failing the test after a timeout
+func waitForFile(t *testing.T, path string) []byte {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if content, err := os.ReadFile(path); err == nil {
+			return content
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatalf("%s did not appear", path)
+	return nil
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	opts := watch.Options{
+		Inbox:      filepath.Join(dir, "inbox"),
+		Outbox:     filepath.Join(dir, "outbox"),
+		Archive:    filepath.Join(dir, "archive"),
+		ErrorDir:   filepath.Join(dir, "error"),
+		SettleTime: 100 * time.Millisecond,
+	}
+
+	watcher, err := watch.New(opts, testSealFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher.Logger = log.New(io.Discard, "", 0)
+
+	content, err := os.ReadFile("../tests/sealFile/basic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Present before the watcher starts
+	if err := os.WriteFile(filepath.Join(opts.Inbox, "existing.txt"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Run(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}()
+
+	// Written in two parts, the file must not be sealed half written
+	f, err := os.Create(filepath.Join(opts.Inbox, "payments.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(content[:40])
+	time.Sleep(50 * time.Millisecond)
+	f.Write(content[40:])
+	f.Close()
+
+	if err := os.WriteFile(filepath.Join(opts.Inbox, "invalid.txt"), []byte("not a bankgiro file\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"existing.txt", "payments.txt"} {
+		sealed := waitForFile(t, filepath.Join(opts.Outbox, name))
+		if !sign.IsSealed(sealed) {
+			t.Errorf("%s in outbox is not sealed", name)
+		}
+		if !strings.Contains(string(sealed), strings.TrimSpace(string(content))) {
+			t.Errorf("%s in outbox does not contain the full original content", name)
+		}
+
+		archived := waitForFile(t, filepath.Join(opts.Archive, name))
+		if string(archived) != string(content) {
+			t.Errorf("%s in archive differs from the original", name)
+		}
+	}
+
+	explanation := waitForFile(t, filepath.Join(opts.ErrorDir, "invalid.txt"+watch.ErrorSuffix))
+	if !strings.Contains(string(explanation), "invalid opening record") {
+		t.Errorf("unexpected error explanation: %s", explanation)
+	}
+	waitForFile(t, filepath.Join(opts.ErrorDir, "invalid.txt"))
+
+	if entries, _ := os.ReadDir(opts.Inbox); len(entries) != 0 {
+		t.Errorf("inbox not empty: %d files left", len(entries))
+	}
+}